@@ -0,0 +1,265 @@
+package mandrill
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// AddAttachmentReader attaches the content read from r, encoding it as it's read.
+// The type is taken from name's extension, or sniffed from the content.
+func (m *Message) AddAttachmentReader(name string, r io.Reader) error {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	head = head[:n]
+
+	var encoded strings.Builder
+	enc := base64.NewEncoder(base64.StdEncoding, &encoded)
+	enc.Write(head)
+	if _, err = io.Copy(enc, r); err != nil {
+		return err
+	}
+	enc.Close()
+
+	m.Attachments = append(m.Attachments, &Attachment{
+		Type:    attachmentType(name, head),
+		Name:    name,
+		Content: encoded.String(),
+	})
+	return nil
+}
+
+// attachmentType detects a MIME type from filename or the start of the content
+func attachmentType(filename string, head []byte) string {
+	mimeType := mime.TypeByExtension(path.Ext(filename))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(head)
+	}
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	return mimeType
+}
+
+// AttachmentCache reuses the base64 encoding of identical attachment content
+// across messages, keyed by the content's SHA-256. The zero value is ready to
+// use and it is safe for concurrent use.
+type AttachmentCache struct {
+	mu      sync.Mutex
+	encoded map[[sha256.Size]byte]string
+}
+
+// Attachment returns an attachment for content, encoding it only the first time
+// the cache sees that content
+func (c *AttachmentCache) Attachment(mimeType string, name string, content []byte) *Attachment {
+	sum := sha256.Sum256(content)
+
+	c.mu.Lock()
+	encoded, ok := c.encoded[sum]
+	c.mu.Unlock()
+
+	if !ok {
+		encoded = base64.StdEncoding.EncodeToString(content)
+		c.mu.Lock()
+		if c.encoded == nil {
+			c.encoded = map[[sha256.Size]byte]string{}
+		}
+		c.encoded[sum] = encoded
+		c.mu.Unlock()
+	}
+
+	return &Attachment{Type: mimeType, Name: name, Content: encoded}
+}
+
+// Len returns the number of distinct contents in the cache
+func (c *AttachmentCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.encoded)
+}
+
+// CompressedTypes are MIME types whose content is already compressed. CompressAttachments
+// leaves them alone unless they're listed in AttachmentCompression.Types.
+var CompressedTypes = []string{
+	"application/zip", "application/gzip", "application/x-gzip", "application/x-bzip2", "application/x-xz",
+	"application/x-7z-compressed", "application/x-rar-compressed", "application/vnd.rar", "application/zstd",
+	"application/pdf", "application/epub+zip",
+	"application/vnd.openxmlformats-officedocument.*", "application/vnd.oasis.opendocument.*",
+	"image/*", "audio/*", "video/*",
+}
+
+// AttachmentCompression describes which attachments CompressAttachments zips
+type AttachmentCompression struct {
+	// attachments whose decoded content is smaller than this many bytes are left alone
+	Threshold int
+	// MIME types to compress, e.g. "text/csv" or "text/*". Empty compresses every
+	// type except CompressedTypes.
+	Types []string
+}
+
+// CompressAttachments replaces each qualifying attachment with a zip archive
+// containing it, appending ".zip" to its name. Images are never compressed.
+func (m *Message) CompressAttachments(opts AttachmentCompression) error {
+	for i, a := range m.Attachments {
+		if !opts.matches(a.Type) {
+			continue
+		}
+
+		content, err := base64.StdEncoding.DecodeString(a.Content)
+		if err != nil {
+			return err
+		}
+		if len(content) < opts.Threshold {
+			continue
+		}
+
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, err := zw.Create(a.Name)
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(content); err != nil {
+			return err
+		}
+		if err = zw.Close(); err != nil {
+			return err
+		}
+
+		m.Attachments[i] = &Attachment{
+			Type:    "application/zip",
+			Name:    a.Name + ".zip",
+			Content: base64.StdEncoding.EncodeToString(buf.Bytes()),
+		}
+	}
+	return nil
+}
+
+func (opts AttachmentCompression) matches(mimeType string) bool {
+	if len(opts.Types) == 0 {
+		return !matchesType(CompressedTypes, mimeType)
+	}
+	return matchesType(opts.Types, mimeType)
+}
+
+// matchesType reports whether mimeType, ignoring case and parameters such as
+// "; charset=utf-8", is one of patterns. A pattern ending in "*" matches any
+// type starting with the rest of it, e.g. "text/*".
+func matchesType(patterns []string, mimeType string) bool {
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	for _, t := range patterns {
+		t = strings.ToLower(t)
+		if t == mimeType || (strings.HasSuffix(t, "*") && strings.HasPrefix(mimeType, t[:len(t)-1])) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxMessageSize is Mandrill's limit on the size of a message, attachments included
+const MaxMessageSize = 25 << 20
+
+// DefaultRiskyExtensions are the file extensions AttachmentLimits treats as risky when RiskyExtensions is nil
+var DefaultRiskyExtensions = []string{".bat", ".cmd", ".com", ".cpl", ".exe", ".js", ".jar", ".msi", ".scr", ".vbs"}
+
+// AttachmentLimits is a ContentChecker that refuses messages whose attachments
+// Mandrill would reject before they're uploaded, and warns about risky file types.
+// Risky attachments are allowed unless RefuseRisky is set.
+type AttachmentLimits struct {
+	// the maximum total encoded size of attachments and images in bytes. Defaults to MaxMessageSize.
+	MaxSize int
+	// the maximum number of attachments and images. Zero allows any number.
+	MaxCount int
+	// file extensions treated as risky, e.g. ".exe". Defaults to DefaultRiskyExtensions.
+	RiskyExtensions []string
+	// optional callback warned about each risky attachment
+	OnRisky func(message *Message, attachment *Attachment)
+	// refuse messages with risky attachments instead of only warning about them
+	RefuseRisky bool
+}
+
+// AttachmentError describes why AttachmentLimits refused a message
+type AttachmentError struct {
+	// the name of the offending attachment, empty when the limit applies to all attachments
+	Name string
+	// what's wrong
+	Reason string
+}
+
+// Error describes the problem
+func (err *AttachmentError) Error() string {
+	if err.Name == "" {
+		return "mandrill: attachments " + err.Reason
+	}
+	return fmt.Sprintf("mandrill: attachment %q %s", err.Name, err.Reason)
+}
+
+// CheckContent validates the message's attachments and images
+func (l AttachmentLimits) CheckContent(message *Message) error {
+	maxSize := l.MaxSize
+	if maxSize <= 0 {
+		maxSize = MaxMessageSize
+	}
+	risky := l.RiskyExtensions
+	if risky == nil {
+		risky = DefaultRiskyExtensions
+	}
+
+	all := append(append([]*Attachment(nil), message.Attachments...), message.Images...)
+	if l.MaxCount > 0 && len(all) > l.MaxCount {
+		return &AttachmentError{Reason: fmt.Sprintf("count %d is over the limit of %d", len(all), l.MaxCount)}
+	}
+
+	total := 0
+	for _, a := range all {
+		total += len(a.Content)
+		if !isRisky(a.Name, risky) {
+			continue
+		}
+		if l.OnRisky != nil {
+			l.OnRisky(message, a)
+		}
+		if l.RefuseRisky {
+			return &AttachmentError{Name: a.Name, Reason: "has a risky file type"}
+		}
+	}
+
+	if total > maxSize {
+		return &AttachmentError{Reason: fmt.Sprintf("total %s encoded, over the limit of %s", formatSize(total), formatSize(maxSize))}
+	}
+	return nil
+}
+
+func isRisky(name string, extensions []string) bool {
+	ext := path.Ext(name)
+	for _, risky := range extensions {
+		if strings.EqualFold(ext, risky) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatSize(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", n)
+}
@@ -0,0 +1,181 @@
+package mandrill
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// AddAttachmentReader //////////
+
+func Test_AddAttachmentReader(t *testing.T) {
+	content := "[" + strings.Repeat(`{"a":1},`, 200) + "{}]"
+	m := &Message{}
+
+	err := m.AddAttachmentReader("report.json", strings.NewReader(content))
+	expect(t, err, nil)
+	expect(t, m.Attachments[0].Name, "report.json")
+	expect(t, m.Attachments[0].Type, "application/json")
+	expect(t, m.Attachments[0].Content, base64.StdEncoding.EncodeToString([]byte(content)))
+}
+
+func Test_AddAttachmentReader_Sniffed(t *testing.T) {
+	pdf := []byte("%PDF-1.4 small")
+	m := &Message{}
+
+	expect(t, m.AddAttachmentReader("statement", bytes.NewReader(pdf)), nil)
+	expect(t, m.Attachments[0].Type, "application/pdf")
+	expect(t, m.Attachments[0].Content, base64.StdEncoding.EncodeToString(pdf))
+
+	expect(t, m.AddAttachmentReader("notes", strings.NewReader("hello")), nil)
+	expect(t, m.Attachments[1].Type, "text/plain")
+
+	expect(t, m.AddAttachmentReader("empty", strings.NewReader("")), nil)
+	expect(t, m.Attachments[2].Content, "")
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) { return 0, errors.New("connection reset") }
+
+func Test_AddAttachmentReader_Error(t *testing.T) {
+	m := &Message{}
+	err := m.AddAttachmentReader("report.csv", io.MultiReader(strings.NewReader(strings.Repeat("x", 600)), failingReader{}))
+	expect(t, err.Error(), "connection reset")
+	expect(t, len(m.Attachments), 0)
+}
+
+// CompressAttachments //////////
+
+func Test_CompressAttachments(t *testing.T) {
+	csv := strings.Repeat("a,b,c\n", 100)
+	m := &Message{Attachments: []*Attachment{
+		{Type: "text/csv", Name: "report.csv", Content: base64.StdEncoding.EncodeToString([]byte(csv))},
+		{Type: "text/plain", Name: "small.txt", Content: base64.StdEncoding.EncodeToString([]byte("hi"))},
+		{Type: "application/pdf", Name: "doc.pdf", Content: base64.StdEncoding.EncodeToString([]byte(csv))},
+	}}
+
+	err := m.CompressAttachments(AttachmentCompression{Threshold: 100, Types: []string{"text/*"}})
+	expect(t, err, nil)
+
+	expect(t, m.Attachments[0].Name, "report.csv.zip")
+	expect(t, m.Attachments[0].Type, "application/zip")
+	expect(t, m.Attachments[1].Name, "small.txt")
+	expect(t, m.Attachments[2].Name, "doc.pdf")
+
+	raw, _ := base64.StdEncoding.DecodeString(m.Attachments[0].Content)
+	zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	expect(t, err, nil)
+	expect(t, zr.File[0].Name, "report.csv")
+	f, _ := zr.File[0].Open()
+	content, _ := ioutil.ReadAll(f)
+	expect(t, string(content), csv)
+}
+
+func Test_CompressAttachments_DefaultTypes(t *testing.T) {
+	content := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a,b,c\n", 100)))
+	m := &Message{Attachments: []*Attachment{
+		{Type: "text/csv; charset=utf-8", Name: "report.csv", Content: content},
+		{Type: "application/zip", Name: "archive.zip", Content: content},
+		{Type: "image/jpeg", Name: "photo.jpg", Content: content},
+		{Type: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", Name: "doc.docx", Content: content},
+	}}
+
+	expect(t, m.CompressAttachments(AttachmentCompression{}), nil)
+	expect(t, m.Attachments[0].Name, "report.csv.zip")
+	expect(t, m.Attachments[1].Name, "archive.zip")
+	expect(t, m.Attachments[2].Name, "photo.jpg")
+	expect(t, m.Attachments[3].Name, "doc.docx")
+}
+
+func Test_CompressAttachments_TypeParameters(t *testing.T) {
+	content := base64.StdEncoding.EncodeToString([]byte("a,b,c\n"))
+	m := &Message{Attachments: []*Attachment{{Type: "Text/CSV; charset=utf-8", Name: "report.csv", Content: content}}}
+
+	expect(t, m.CompressAttachments(AttachmentCompression{Types: []string{"text/csv"}}), nil)
+	expect(t, m.Attachments[0].Name, "report.csv.zip")
+}
+
+func Test_CompressAttachments_BadContent(t *testing.T) {
+	m := &Message{Attachments: []*Attachment{{Type: "text/csv", Name: "report.csv", Content: "!!!"}}}
+	refute(t, m.CompressAttachments(AttachmentCompression{}), nil)
+}
+
+// AttachmentCache //////////
+
+func Test_AttachmentCache(t *testing.T) {
+	cache := &AttachmentCache{}
+	pdf := []byte("%PDF-1.4 terms and conditions")
+
+	a := cache.Attachment("application/pdf", "terms.pdf", pdf)
+	b := cache.Attachment("application/pdf", "terms-copy.pdf", pdf)
+	c := cache.Attachment("text/plain", "notes.txt", []byte("notes"))
+
+	expect(t, a.Content, base64.StdEncoding.EncodeToString(pdf))
+	expect(t, b.Content, a.Content)
+	expect(t, b.Name, "terms-copy.pdf")
+	expect(t, c.Content, base64.StdEncoding.EncodeToString([]byte("notes")))
+	expect(t, cache.Len(), 2)
+}
+
+// AttachmentLimits //////////
+
+func Test_AttachmentLimits(t *testing.T) {
+	m := &Message{
+		Attachments: []*Attachment{{Name: "report.pdf", Content: strings.Repeat("a", 600)}},
+		Images:      []*Attachment{{Name: "logo", Content: strings.Repeat("a", 600)}},
+	}
+
+	expect(t, AttachmentLimits{}.CheckContent(m), nil)
+
+	err := AttachmentLimits{MaxSize: 1024}.CheckContent(m)
+	expect(t, err.Error(), "mandrill: attachments total 1.2KB encoded, over the limit of 1.0KB")
+
+	err = AttachmentLimits{MaxCount: 1}.CheckContent(m)
+	expect(t, err.Error(), "mandrill: attachments count 2 is over the limit of 1")
+}
+
+func Test_AttachmentLimits_Risky(t *testing.T) {
+	m := &Message{Attachments: []*Attachment{{Name: "setup.EXE"}, {Name: "notes.txt"}}}
+
+	expect(t, AttachmentLimits{MaxSize: 1024}.CheckContent(m), nil)
+
+	err := AttachmentLimits{RefuseRisky: true}.CheckContent(m)
+	expect(t, err.(*AttachmentError).Name, "setup.EXE")
+	expect(t, err.Error(), `mandrill: attachment "setup.EXE" has a risky file type`)
+
+	expect(t, AttachmentLimits{RiskyExtensions: []string{}, RefuseRisky: true}.CheckContent(m), nil)
+
+	warned := []string{}
+	limits := AttachmentLimits{
+		RiskyExtensions: []string{".txt"},
+		OnRisky: func(message *Message, a *Attachment) {
+			warned = append(warned, a.Name)
+		},
+	}
+	expect(t, limits.CheckContent(m), nil)
+	expect(t, strings.Join(warned, ","), "notes.txt")
+
+	limits.RefuseRisky = true
+	refute(t, limits.CheckContent(m), nil)
+	expect(t, strings.Join(warned, ","), "notes.txt,notes.txt")
+}
+
+func Test_AttachmentLimits_ContentChecker(t *testing.T) {
+	client := ClientWithKey("SANDBOX_SUCCESS")
+	client.ContentCheckers = []ContentChecker{AttachmentLimits{MaxSize: 10}}
+
+	_, err := client.MessagesSend(&Message{Attachments: []*Attachment{{Name: "big.pdf", Content: strings.Repeat("a", 11)}}})
+	_, ok := err.(*AttachmentError)
+	expect(t, ok, true)
+}
+
+func Test_formatSize(t *testing.T) {
+	expect(t, formatSize(512), "512B")
+	expect(t, formatSize(MaxMessageSize), "25.0MB")
+}
@@ -0,0 +1,109 @@
+package mandrill
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without making a request while the client's CircuitBreaker is open
+var ErrCircuitOpen = errors.New("mandrill: circuit open, Mandrill appears to be unavailable")
+
+// DefaultCircuitThreshold is the number of consecutive failures that opens a CircuitBreaker when Threshold is zero
+const DefaultCircuitThreshold = 5
+
+// DefaultCircuitCoolDown is how long a CircuitBreaker stays open when CoolDown is zero
+const DefaultCircuitCoolDown = 30 * time.Second
+
+// CircuitBreaker makes requests fail fast with ErrCircuitOpen after repeated outage
+// errors: transport failures, timeouts and responses that aren't Mandrill errors,
+// such as a proxy's 502 page. API errors like Invalid_Key don't count, and neither
+// do errors raised by the client itself, like ErrInFlightTimeout. Once CoolDown
+// has passed a single request is let through, closing the circuit if it succeeds.
+type CircuitBreaker struct {
+	// consecutive failures that open the circuit. Defaults to DefaultCircuitThreshold.
+	Threshold int
+	// how long the circuit stays open before a request is tried again. Defaults to DefaultCircuitCoolDown.
+	CoolDown time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// Open reports whether requests are currently being refused
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.failures >= b.threshold() && (b.probing || now().Sub(b.openedAt) < b.coolDown())
+}
+
+// allow reports whether a request may be made
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold() {
+		return true
+	}
+	if b.probing || now().Sub(b.openedAt) < b.coolDown() {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record counts the outcome of an allowed request
+func (b *CircuitBreaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	switch {
+	case err == nil:
+		b.failures = 0
+	case ctx.Err() != nil, isLocal(err):
+		// canceled by the caller or refused locally, says nothing about Mandrill
+	case isOutage(err):
+		b.failures++
+		if b.failures >= b.threshold() {
+			b.openedAt = now()
+		}
+	default:
+		b.failures = 0
+	}
+}
+
+func (b *CircuitBreaker) threshold() int {
+	if b.Threshold > 0 {
+		return b.Threshold
+	}
+	return DefaultCircuitThreshold
+}
+
+func (b *CircuitBreaker) coolDown() time.Duration {
+	if b.CoolDown > 0 {
+		return b.CoolDown
+	}
+	return DefaultCircuitCoolDown
+}
+
+// isOutage reports whether err suggests Mandrill is unreachable rather than rejecting the request
+func isOutage(err error) bool {
+	switch e := err.(type) {
+	case *ThrottledError:
+		return false
+	case *Error:
+		return e.Body != nil && e.StatusCode >= 500
+	}
+	return true
+}
+
+// isLocal reports whether err was raised by the client itself, without an answer from Mandrill
+func isLocal(err error) bool {
+	return errors.Is(err, ErrInFlightTimeout) || errors.Is(err, ErrURLNotAllowed)
+}
@@ -0,0 +1,144 @@
+package mandrill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func flakyServer(code *int32, body string) (*httptest.Server, *Client, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		status := int(atomic.LoadInt32(code))
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			fmt.Fprint(w, `"PONG!"`)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	client := &Client{Key: "APIKEY", BaseURL: server.URL + "/", HTTPClient: &http.Client{}}
+	return server, client, &calls
+}
+
+// CircuitBreaker //////////
+
+func Test_CircuitBreaker_Opens(t *testing.T) {
+	start := time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)
+	freezeNow(t, start)
+
+	code := int32(502)
+	server, c, calls := flakyServer(&code, "<html>Bad Gateway</html>")
+	defer server.Close()
+	c.CircuitBreaker = &CircuitBreaker{Threshold: 2, CoolDown: time.Minute}
+
+	_, err := c.Ping()
+	expect(t, err.(*Error).StatusCode, 502)
+	expect(t, c.CircuitBreaker.Open(), false)
+	_, err = c.Ping()
+	expect(t, err.(*Error).StatusCode, 502)
+	expect(t, c.CircuitBreaker.Open(), true)
+
+	_, err = c.Ping()
+	expect(t, err, ErrCircuitOpen)
+	expect(t, *calls, int32(2))
+
+	// a failed probe after the cool-down re-opens the circuit
+	freezeNow(t, start.Add(time.Minute))
+	expect(t, c.CircuitBreaker.Open(), false)
+	_, err = c.Ping()
+	expect(t, err.(*Error).StatusCode, 502)
+	_, err = c.Ping()
+	expect(t, err, ErrCircuitOpen)
+	expect(t, *calls, int32(3))
+
+	// a successful probe closes it
+	freezeNow(t, start.Add(2*time.Minute))
+	atomic.StoreInt32(&code, 200)
+	_, err = c.Ping()
+	expect(t, err, nil)
+	expect(t, c.CircuitBreaker.Open(), false)
+	_, err = c.Ping()
+	expect(t, err, nil)
+}
+
+func Test_CircuitBreaker_IgnoresAPIErrors(t *testing.T) {
+	code := int32(500)
+	server, c, _ := flakyServer(&code, `{"status":"error","code":-1,"name":"Invalid_Key","message":"Invalid API key"}`)
+	defer server.Close()
+	c.CircuitBreaker = &CircuitBreaker{Threshold: 1}
+
+	for i := 0; i < 3; i++ {
+		_, err := c.Ping()
+		expect(t, err.(*Error).Name, "Invalid_Key")
+	}
+	expect(t, c.CircuitBreaker.Open(), false)
+}
+
+func Test_CircuitBreaker_IgnoresCanceled(t *testing.T) {
+	code := int32(200)
+	server, c, _ := flakyServer(&code, "")
+	defer server.Close()
+	c.CircuitBreaker = &CircuitBreaker{Threshold: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.PingContext(ctx)
+	expect(t, errors.Is(err, context.Canceled), true)
+	expect(t, c.CircuitBreaker.Open(), false)
+}
+
+func Test_CircuitBreaker_TransportErrors(t *testing.T) {
+	c := &Client{Key: "APIKEY", BaseURL: "http://127.0.0.1:1/", HTTPClient: &http.Client{}}
+	c.CircuitBreaker = &CircuitBreaker{Threshold: 1}
+
+	_, err := c.Ping()
+	refute(t, err, nil)
+	_, err = c.Ping()
+	expect(t, err, ErrCircuitOpen)
+}
+
+func Test_isOutage(t *testing.T) {
+	expect(t, isOutage(errors.New("connection refused")), true)
+	expect(t, isOutage(&Error{StatusCode: 503, Body: []byte("unavailable")}), true)
+	expect(t, isOutage(&Error{StatusCode: 500, Name: "GeneralError"}), false)
+	expect(t, isOutage(&ThrottledError{}), false)
+}
+
+func Test_isLocal(t *testing.T) {
+	expect(t, isLocal(ErrInFlightTimeout), true)
+	expect(t, isLocal(fmt.Errorf("%w: host %q", ErrURLNotAllowed, "example.com")), true)
+	expect(t, isLocal(errors.New("connection refused")), false)
+}
+
+func Test_CircuitBreaker_LocalErrorsDontCloseProbe(t *testing.T) {
+	current := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	b := &CircuitBreaker{Threshold: 2, CoolDown: time.Minute}
+	ctx := context.Background()
+	b.record(ctx, errors.New("connection refused"))
+	b.record(ctx, errors.New("connection refused"))
+	expect(t, b.Open(), true)
+
+	current = current.Add(time.Minute)
+	expect(t, b.allow(), true)
+	b.record(ctx, ErrInFlightTimeout)
+	expect(t, b.failures, 2)
+
+	expect(t, b.allow(), true)
+	b.record(ctx, fmt.Errorf("redirect: %w", ErrURLNotAllowed))
+	expect(t, b.failures, 2)
+
+	expect(t, b.allow(), true)
+	b.record(ctx, nil)
+	expect(t, b.failures, 0)
+	expect(t, b.Open(), false)
+}
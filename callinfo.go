@@ -0,0 +1,41 @@
+package mandrill
+
+import (
+	"context"
+	"time"
+)
+
+// CallInfo describes the HTTP requests behind an API call. See WithCallInfo.
+type CallInfo struct {
+	// the API path, e.g. "messages/send.json"
+	Path string
+	// how long the call took, including waits between throttled retries
+	Duration time.Duration
+	// the number of HTTP requests made: the first plus any throttled retries
+	Attempts int
+	// the HTTP status code of the last response, or 0 if none was received
+	StatusCode int
+	// the size in bytes of the last response body
+	ResponseSize int
+}
+
+type callInfoKey struct{}
+
+// WithCallInfo returns a context that fills info in when an API call is made with
+// it, for emitting per-call telemetry. If several calls share the context, info
+// describes the last one to finish, so don't share it between goroutines. Sends
+// made with the sandbox keys make no request and leave info untouched.
+func WithCallInfo(ctx context.Context, info *CallInfo) context.Context {
+	return context.WithValue(ctx, callInfoKey{}, info)
+}
+
+// record fills the info in once a call has finished
+func (info *CallInfo) record(path string, start time.Time, attempts *int, status *int, body *[]byte) {
+	*info = CallInfo{
+		Path:         path,
+		Duration:     time.Since(start),
+		Attempts:     *attempts,
+		StatusCode:   *status,
+		ResponseSize: len(*body),
+	}
+}
@@ -0,0 +1,56 @@
+package mandrill
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// CallInfo //////////
+
+func Test_WithCallInfo(t *testing.T) {
+	server, c := testTools(200, `"PONG!"`)
+	defer server.Close()
+
+	var info CallInfo
+	_, err := c.PingContext(WithCallInfo(context.Background(), &info))
+	expect(t, err, nil)
+	expect(t, info.Path, "users/ping.json")
+	expect(t, info.Attempts, 1)
+	expect(t, info.StatusCode, 200)
+	expect(t, info.ResponseSize, len(`"PONG!"`+"\n"))
+	expect(t, info.Duration > 0, true)
+}
+
+func Test_WithCallInfo_Retries(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"status":"error","code":-1,"name":"GeneralError","message":"Oops"}`)
+	}))
+	defer server.Close()
+	c := &Client{Key: "APIKEY", BaseURL: server.URL + "/", HTTPClient: &http.Client{}, ThrottleRetries: 5}
+
+	var info CallInfo
+	_, err := c.PingContext(WithCallInfo(context.Background(), &info))
+	expect(t, err.Error(), "Oops")
+	expect(t, info.Attempts, 3)
+	expect(t, info.StatusCode, 500)
+}
+
+func Test_WithCallInfo_Sandbox(t *testing.T) {
+	c := ClientWithKey("SANDBOX_SUCCESS")
+
+	info := CallInfo{Path: "untouched"}
+	_, err := c.MessagesSendContext(WithCallInfo(context.Background(), &info), &Message{To: []*To{{Email: "bob@example.com"}}})
+	expect(t, err, nil)
+	expect(t, info.Path, "untouched")
+}
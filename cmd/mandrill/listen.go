@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/keighl/mandrill/webhooks"
+)
+
+// keyList collects a repeatable -key flag
+type keyList []string
+
+func (k *keyList) String() string { return strings.Join(*k, ",") }
+
+func (k *keyList) Set(key string) error {
+	*k = append(*k, key)
+	return nil
+}
+
+func listen(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("mandrill webhooks listen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	addr := flags.String("addr", "localhost:8090", "the address to listen on")
+	var keys keyList
+	flags.Var(&keys, "key", "a webhook key to verify signatures with; repeat while rotating. Defaults to $MANDRILL_WEBHOOK_KEY.")
+	webhookURL := flags.String("url", "", "the webhook URL as registered with Mandrill, e.g. a tunnel's URL. Defaults to the URL requests are made to.")
+	forward := flags.String("forward", "", "an application URL to forward verified requests to, unchanged")
+	quiet := flags.Bool("quiet", false, "print only a summary line for each event")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if len(keys) == 0 && os.Getenv("MANDRILL_WEBHOOK_KEY") != "" {
+		keys = append(keys, os.Getenv("MANDRILL_WEBHOOK_KEY"))
+	}
+	if len(keys) == 0 {
+		fmt.Fprintln(stderr, "warning: no -key given, accepting requests without verifying their signature")
+	}
+
+	l := &listener{
+		handler: &webhooks.Handler{
+			Keys:               keys,
+			InsecureSkipVerify: len(keys) == 0,
+			URL:                *webhookURL,
+		},
+		forward: *forward,
+		quiet:   *quiet,
+		out:     stdout,
+	}
+	l.handler.OnEvent = l.print
+
+	fmt.Fprintf(stderr, "listening on http://%s/\n", *addr)
+	if err := http.ListenAndServe(*addr, l); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// listener verifies and prints webhook requests, then forwards them
+type listener struct {
+	handler *webhooks.Handler
+	forward string
+	quiet   bool
+	out     io.Writer
+	client  *http.Client
+
+	// serializes output, so a batch's events print together
+	mu sync.Mutex
+}
+
+func (l *listener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fmt.Fprintf(l.out, "--> %s %s\n", r.Method, r.URL.RequestURI())
+	rec := httptest.NewRecorder()
+	l.handler.ServeHTTP(rec, r)
+	if rec.Code != http.StatusOK {
+		fmt.Fprintf(l.out, "<-- %d %s", rec.Code, rec.Body.String())
+		copyResponse(w, rec.Result())
+		return
+	}
+	if l.forward == "" || r.Method != "POST" {
+		fmt.Fprintf(l.out, "<-- %d\n", rec.Code)
+		w.WriteHeader(rec.Code)
+		return
+	}
+
+	res, err := l.forwardRequest(r, body)
+	if err != nil {
+		fmt.Fprintf(l.out, "<-- forwarding to %s failed: %s\n", l.forward, err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer res.Body.Close()
+	fmt.Fprintf(l.out, "<-- %s answered %s\n", l.forward, res.Status)
+	copyResponse(w, res)
+}
+
+// forwardRequest posts the request's body and headers to the forward URL, so the
+// application can verify the signature itself
+func (l *listener) forwardRequest(r *http.Request, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(r.Method, l.forward, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range r.Header {
+		req.Header[name] = values
+	}
+	client := l.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// print writes an event's summary line and its JSON
+func (l *listener) print(event *webhooks.Event) error {
+	email := ""
+	if event.Msg != nil {
+		email = event.Msg.Email
+	}
+	fmt.Fprintf(l.out, "%s  %-11s %s  %s\n", event.Ts.UTC().Format("2006-01-02 15:04:05"), event.Type, email, event.ID)
+	switch event.Type {
+	case webhooks.EventHardBounce, webhooks.EventSoftBounce:
+		if bounce, _ := event.Bounce(); bounce != nil && bounce.Diag != "" {
+			d := bounce.Classify()
+			fmt.Fprintf(l.out, "    %s (%s, %s)\n", bounce.Diag, d.Category, d.Action)
+		}
+	case webhooks.EventClick:
+		fmt.Fprintf(l.out, "    %s\n", event.URL)
+	}
+
+	if l.quiet {
+		return nil
+	}
+	data, err := json.MarshalIndent(event, "    ", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(l.out, "    %s\n", data)
+	return nil
+}
+
+func copyResponse(w http.ResponseWriter, res *http.Response) {
+	for name, values := range res.Header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(res.StatusCode)
+	io.Copy(w, res.Body)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/keighl/mandrill/webhooks"
+)
+
+func expect(t *testing.T, a interface{}, b interface{}) {
+	if a != b {
+		t.Errorf("Expected %v (type %[1]T) - Got %v (type %[2]T)", b, a)
+	}
+}
+
+const bounceEventJSON = `[{
+	"event": "hard_bounce",
+	"ts": 1365109999,
+	"_id": "exampleaaaaaaaaaaaaaaaaaaaaaaaaa",
+	"msg": {
+		"email": "example.webhook@mandrillapp.com",
+		"bounce_description": "bad_mailbox",
+		"diag": "smtp;550 5.1.1 The email account that you tried to reach does not exist."
+	}
+}]`
+
+func signedRequest(key string) *http.Request {
+	form := url.Values{"mandrill_events": {bounceEventJSON}}
+	r := httptest.NewRequest("POST", "http://example.com/webhook", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("X-Mandrill-Signature", webhooks.Signature(key, "http://example.com/webhook", form))
+	return r
+}
+
+func newListener(keys ...string) (*listener, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	l := &listener{handler: &webhooks.Handler{Keys: keys}, out: out, quiet: true}
+	l.handler.OnEvent = l.print
+	return l, out
+}
+
+func Test_Run_Usage(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	expect(t, run([]string{"webhooks"}, ioutil.Discard, stderr), 2)
+	expect(t, strings.Contains(stderr.String(), "webhooks listen"), true)
+}
+
+func Test_Listener(t *testing.T) {
+	l, out := newListener("key")
+
+	w := httptest.NewRecorder()
+	l.ServeHTTP(w, signedRequest("key"))
+	expect(t, w.Code, http.StatusOK)
+	expect(t, out.String(), `--> POST /webhook
+2013-04-04 21:13:19  hard_bounce example.webhook@mandrillapp.com  exampleaaaaaaaaaaaaaaaaaaaaaaaaa
+    smtp;550 5.1.1 The email account that you tried to reach does not exist. (no_such_user, suppress)
+<-- 200
+`)
+}
+
+func Test_Listener_InvalidSignature(t *testing.T) {
+	l, out := newListener("key")
+
+	w := httptest.NewRecorder()
+	l.ServeHTTP(w, signedRequest("other"))
+	expect(t, w.Code, http.StatusForbidden)
+	expect(t, out.String(), "--> POST /webhook\n<-- 403 "+webhooks.ErrInvalidSignature.Error()+"\n")
+}
+
+func Test_Listener_Forward(t *testing.T) {
+	var forwarded *http.Request
+	var body string
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		forwarded, body = r, string(data)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer app.Close()
+
+	l, out := newListener("key")
+	l.forward = app.URL + "/mandrill"
+
+	req := signedRequest("key")
+	w := httptest.NewRecorder()
+	l.ServeHTTP(w, req)
+	expect(t, w.Code, http.StatusAccepted)
+	expect(t, forwarded.URL.Path, "/mandrill")
+	expect(t, forwarded.Header.Get("X-Mandrill-Signature"), req.Header.Get("X-Mandrill-Signature"))
+	expect(t, body, url.Values{"mandrill_events": {bounceEventJSON}}.Encode())
+	expect(t, strings.HasSuffix(out.String(), "<-- "+l.forward+" answered 202 Accepted\n"), true)
+}
+
+func Test_Listener_ForwardFailed(t *testing.T) {
+	l, _ := newListener("key")
+	l.forward = "http://127.0.0.1:1/mandrill"
+
+	w := httptest.NewRecorder()
+	l.ServeHTTP(w, signedRequest("key"))
+	expect(t, w.Code, http.StatusBadGateway)
+}
@@ -0,0 +1,36 @@
+// Command mandrill is a development tool for Mandrill integrations.
+//
+//	mandrill webhooks listen [flags]
+//
+// runs a local webhook endpoint that verifies, decodes and prints the events
+// Mandrill posts to it, and can forward them to your application. Expose it with
+// a tunnel and register the tunnel's URL as a webhook to see real events.
+//
+//	go get github.com/keighl/mandrill/cmd/mandrill
+//	mandrill webhooks listen -url https://abc123.tunnel.example/ -forward http://localhost:3000/mandrill
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `usage: mandrill <command> [flags]
+
+commands:
+  webhooks listen    run a local webhook endpoint that prints received events
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command in args, returning the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) >= 2 && args[0] == "webhooks" && args[1] == "listen" {
+		return listen(args[2:], stdout, stderr)
+	}
+	fmt.Fprint(stderr, usage)
+	return 2
+}
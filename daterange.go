@@ -0,0 +1,61 @@
+package mandrill
+
+import (
+	"time"
+)
+
+const (
+	// DateFormat is the layout Mandrill uses for date-only parameters such as messages/search date_from
+	DateFormat = "2006-01-02"
+	// TimestampFormat is the layout Mandrill uses for UTC timestamps such as send_at
+	TimestampFormat = "2006-01-02 15:04:05"
+)
+
+// overridden in tests
+var now = time.Now
+
+// DateRange is a time window for search, stats and export calls. Both ends are
+// inclusive and always formatted in UTC.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// LastHours returns the range covering the past n hours up to now
+func LastHours(n int) DateRange {
+	to := now().UTC()
+	return DateRange{From: to.Add(-time.Duration(n) * time.Hour), To: to}
+}
+
+// LastDays returns the range covering n whole UTC days up to now, today included,
+// so LastDays(1) is today. n less than 1 is treated as 1.
+func LastDays(n int) DateRange {
+	if n < 1 {
+		n = 1
+	}
+	to := now().UTC()
+	today := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return DateRange{From: today.AddDate(0, 0, -(n - 1)), To: to}
+}
+
+// DateFrom formats the start of the range for date-only parameters. Because
+// Mandrill compares whole days, the range is widened to the start of From's UTC day.
+func (r DateRange) DateFrom() string {
+	return r.From.UTC().Format(DateFormat)
+}
+
+// DateTo formats the end of the range for date-only parameters. Mandrill treats
+// date_to as inclusive, so results from the whole of To's UTC day are included.
+func (r DateRange) DateTo() string {
+	return r.To.UTC().Format(DateFormat)
+}
+
+// TimestampFrom formats the start of the range for timestamp parameters
+func (r DateRange) TimestampFrom() string {
+	return r.From.UTC().Format(TimestampFormat)
+}
+
+// TimestampTo formats the end of the range for timestamp parameters
+func (r DateRange) TimestampTo() string {
+	return r.To.UTC().Format(TimestampFormat)
+}
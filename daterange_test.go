@@ -0,0 +1,38 @@
+package mandrill
+
+import (
+	"testing"
+	"time"
+)
+
+func freezeNow(t *testing.T, at time.Time) {
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = time.Now })
+}
+
+// DateRange //////////
+
+func Test_LastHours(t *testing.T) {
+	freezeNow(t, time.Date(2024, 3, 2, 1, 30, 0, 0, time.FixedZone("EST", -5*3600)))
+	r := LastHours(24)
+
+	expect(t, r.DateFrom(), "2024-03-01")
+	expect(t, r.DateTo(), "2024-03-02")
+	expect(t, r.TimestampFrom(), "2024-03-01 06:30:00")
+	expect(t, r.TimestampTo(), "2024-03-02 06:30:00")
+}
+
+func Test_LastDays(t *testing.T) {
+	freezeNow(t, time.Date(2024, 3, 2, 23, 0, 0, 0, time.UTC))
+	r := LastDays(7)
+
+	expect(t, r.DateFrom(), "2024-02-25")
+	expect(t, r.DateTo(), "2024-03-02")
+	from, _ := time.Parse(DateFormat, r.DateFrom())
+	to, _ := time.Parse(DateFormat, r.DateTo())
+	expect(t, int(to.Sub(from).Hours()/24)+1, 7)
+	expect(t, r.TimestampFrom(), "2024-02-25 00:00:00")
+
+	expect(t, LastDays(1).DateFrom(), "2024-03-02")
+	expect(t, LastDays(0).DateFrom(), "2024-03-02")
+}
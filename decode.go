@@ -0,0 +1,94 @@
+package mandrill
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Mandrill is inconsistent about timestamp formats across endpoints
+var timeFormats = []string{
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	DateFormat,
+}
+
+// Time is a UTC timestamp that decodes from any of the formats Mandrill returns:
+// "YYYY-MM-DD HH:MM:SS" (with optional fraction), RFC 3339, a date, a unix
+// timestamp number, or null/"" for the zero time
+type Time struct {
+	time.Time
+}
+
+var timeType = reflect.TypeOf(Time{})
+
+// invalidTime reports a timestamp that can't be decoded as a type error, so tolerant
+// decoding leaves the zero time while StrictDecoding still fails
+func invalidTime(value string) error {
+	return &json.UnmarshalTypeError{Value: value, Type: timeType}
+}
+
+// UnmarshalJSON decodes a Mandrill timestamp
+func (t *Time) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		t.Time = time.Time{}
+		return nil
+	}
+
+	if len(data) > 0 && data[0] != '"' {
+		secs, err := strconv.ParseFloat(string(data), 64)
+		if err != nil {
+			return invalidTime(string(data))
+		}
+		t.Time = time.Unix(0, int64(secs*float64(time.Second))).UTC()
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+
+	for _, format := range timeFormats {
+		if parsed, err := time.Parse(format, s); err == nil {
+			t.Time = parsed.UTC()
+			return nil
+		}
+	}
+	return invalidTime("string")
+}
+
+// MarshalJSON encodes the time in Mandrill's "YYYY-MM-DD HH:MM:SS" UTC format, or null for the zero time
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.UTC().Format(TimestampFormat))
+}
+
+// decode unmarshals an API response body. Unless StrictDecoding is set, fields
+// whose JSON type doesn't match the Go type are left at their zero value
+// instead of failing the whole response. A response with the wrong overall
+// shape, e.g. an object where a list is expected, is always an error.
+func (c *Client) decode(body []byte, v interface{}) error {
+	err := json.Unmarshal(body, v)
+	if c.StrictDecoding {
+		return err
+	}
+
+	// errors from Time's UnmarshalJSON carry no Field but are always about a single field
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && (typeErr.Field != "" || typeErr.Type == timeType) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package mandrill
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// Time //////////
+
+func Test_Time_UnmarshalJSON(t *testing.T) {
+	want := time.Date(2013, 1, 1, 15, 30, 27, 0, time.UTC)
+	for _, in := range []string{`"2013-01-01 15:30:27"`, `"2013-01-01T15:30:27Z"`, `"2013-01-01T10:30:27-05:00"`, `1357054227`} {
+		var ts Time
+		expect(t, json.Unmarshal([]byte(in), &ts), nil)
+		expect(t, ts.Equal(want), true)
+	}
+
+	var ts Time
+	expect(t, json.Unmarshal([]byte(`"2013-01-01 15:30:27.123"`), &ts), nil)
+	expect(t, ts.Nanosecond(), 123000000)
+
+	for _, in := range []string{`null`, `""`} {
+		ts := Time{want}
+		expect(t, json.Unmarshal([]byte(in), &ts), nil)
+		expect(t, ts.IsZero(), true)
+	}
+
+	refute(t, json.Unmarshal([]byte(`"yesterday"`), &ts), nil)
+	_, ok := json.Unmarshal([]byte(`true`), &ts).(*json.UnmarshalTypeError)
+	expect(t, ok, true)
+}
+
+func Test_decode_MalformedTimestamp(t *testing.T) {
+	server, c, _ := testRecorder(200, `[
+		{"email":"a@example.com","detail":"first","created_at":"2013-01-01 15:30"},
+		{"email":"b@example.com","detail":"second","created_at":true},
+		{"email":"c@example.com","detail":"third","created_at":"2013-01-01 15:30:27"}
+	]`)
+	defer server.Close()
+
+	entries, err := c.WhitelistsList("")
+	expect(t, err, nil)
+	expect(t, len(entries), 3)
+	expect(t, entries[0].CreatedAt.IsZero(), true)
+	expect(t, entries[1].CreatedAt.IsZero(), true)
+	expect(t, entries[1].Detail, "second")
+	expect(t, entries[2].CreatedAt.IsZero(), false)
+
+	c.StrictDecoding = true
+	_, err = c.WhitelistsList("")
+	_, ok := err.(*json.UnmarshalTypeError)
+	expect(t, ok, true)
+}
+
+func Test_Time_MarshalJSON(t *testing.T) {
+	b, _ := json.Marshal(Time{time.Date(2013, 1, 1, 10, 30, 27, 0, time.FixedZone("EST", -5*3600))})
+	expect(t, string(b), `"2013-01-01 15:30:27"`)
+
+	b, _ = json.Marshal(Time{})
+	expect(t, string(b), `null`)
+}
+
+// decode //////////
+
+func Test_decode_Tolerant(t *testing.T) {
+	var v struct {
+		Email string `json:"email"`
+		Count int    `json:"count"`
+		Tags  []string
+	}
+	c := ClientWithKey("APIKEY")
+	err := c.decode([]byte(`{"email":"bob@example.com","count":"12","tags":null,"extra":true}`), &v)
+
+	expect(t, err, nil)
+	expect(t, v.Email, "bob@example.com")
+	expect(t, v.Count, 0)
+
+	c.StrictDecoding = true
+	refute(t, c.decode([]byte(`{"email":"bob@example.com","count":"12"}`), &v), nil)
+	expect(t, c.decode([]byte(`{"email":"bob@example.com","extra":true}`), &v), nil)
+}
+
+func Test_decode_WrongShape(t *testing.T) {
+	c := ClientWithKey("APIKEY")
+	var responses []*Response
+	refute(t, c.decode([]byte(`{"status":"error","message":"nope"}`), &responses), nil)
+	var pong string
+	refute(t, c.decode([]byte(`{"PING":"PONG!"}`), &pong), nil)
+}
+
+func Test_MessagesSend_WrongShapeResponse(t *testing.T) {
+	server, m := testTools(200, `{"status":"error","code":-1,"name":"Unknown","message":"nope"}`)
+	defer server.Close()
+	responses, err := m.MessagesSend(&Message{})
+
+	refute(t, err, nil)
+	expect(t, len(responses), 0)
+}
+
+func Test_Ping_WrongShapeResponse(t *testing.T) {
+	server, m := testTools(200, `{"PING":"PONG!"}`)
+	defer server.Close()
+	pong, err := m.Ping()
+
+	refute(t, err, nil)
+	expect(t, pong, "")
+}
+
+func Test_MessagesSend_NullResponse(t *testing.T) {
+	server, m := testTools(200, `null`)
+	defer server.Close()
+	responses, err := m.MessagesSend(&Message{})
+
+	expect(t, err, nil)
+	expect(t, responses == nil, false)
+	expect(t, len(responses), 0)
+}
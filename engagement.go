@@ -0,0 +1,254 @@
+package mandrill
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultTopLinks is the number of links an EngagementReport ranks when EngagementParams.TopLinks is zero
+const DefaultTopLinks = 10
+
+// EngagementParams selects the sending an Engagement report covers
+type EngagementParams struct {
+	// the tag the report is about, e.g. a campaign's tag
+	Tag string
+	// the days the report covers, as whole UTC days. A zero From covers the 7 days
+	// before To; a zero To covers up to now. Mandrill keeps hourly stats for 30 days.
+	Range DateRange
+	// the number of links to rank. Defaults to DefaultTopLinks.
+	TopLinks int
+}
+
+// EngagementReport summarizes how a tag's messages were delivered and engaged with
+type EngagementReport struct {
+	// the tag the report is about
+	Tag string
+	// the days the report covers, from the start of the first to the end of the last
+	Range DateRange
+	// when the report was built
+	GeneratedAt time.Time
+
+	// the tag's hourly stats summed over the range: the delivery funnel
+	Stats Stats
+	// the messages sent that didn't bounce
+	Delivered int
+	// UniqueOpens over Delivered
+	OpenRate float64
+	// UniqueClicks over Delivered
+	ClickRate float64
+	// UniqueClicks over UniqueOpens
+	ClickToOpenRate float64
+
+	// the number of the tag's messages found by search, which the rest of the report is built from
+	Messages int
+	// whether a day had more messages than search returns, so the counts below are from a sample
+	Truncated bool
+	// the recipients who opened at least once
+	UniqueOpeners int
+	// the recipients who clicked at least once
+	UniqueClickers int
+	// the most clicked links, most clicks first
+	TopLinks []*LinkEngagement
+	// the bounced messages grouped by state and SMTP code, most common first
+	Bounces []*BounceBreakdown
+}
+
+// LinkEngagement is the clicks on one link in an EngagementReport
+type LinkEngagement struct {
+	// the link
+	URL string
+	// the clicks on the link in the tag's messages
+	Clicks int
+	// the recipients who clicked the link
+	Clickers int
+	// the link's hourly stats summed over the range, across every message containing it
+	URLStats Stats
+}
+
+// BounceBreakdown is a group of bounced messages in an EngagementReport
+type BounceBreakdown struct {
+	// the message state, "bounced" or "soft-bounced"
+	State string
+	// the SMTP reply and enhanced status code of the last SMTP event, e.g. "550 5.1.1", or "" if there was none
+	Code string
+	// the number of messages
+	Count int
+	// the diagnostic of one of the messages
+	Example string
+}
+
+// engagementSearchLimit is the most results Mandrill returns from one search
+const engagementSearchLimit = 1000
+
+var smtpCodePattern = regexp.MustCompile(`^\d{3}(?:[ -]\d\.\d{1,3}\.\d{1,3})?`)
+
+// Engagement builds an EngagementReport for a tag, e.g. after a campaign's send.
+// It combines the tag's time series for the funnel, a search of its messages,
+// one UTC day at a time, for unique engagement and bounces, and the time series of
+// the top links. Client.PageInterval spaces the searches.
+func (c *Client) Engagement(ctx context.Context, params EngagementParams) (report *EngagementReport, err error) {
+	to := params.Range.To
+	if to.IsZero() {
+		to = now()
+	}
+	from := params.Range.From
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -searchDefaultDays)
+	}
+	first, last := dayStart(from), dayStart(to)
+	end := last.AddDate(0, 0, 1)
+	topLinks := params.TopLinks
+	if topLinks <= 0 {
+		topLinks = DefaultTopLinks
+	}
+
+	report = &EngagementReport{
+		Tag:         params.Tag,
+		Range:       DateRange{From: first, To: end.Add(-time.Second)},
+		GeneratedAt: now().UTC(),
+	}
+
+	series, err := c.TagsTimeSeriesContext(ctx, params.Tag)
+	if err != nil {
+		return nil, err
+	}
+	report.Stats = sumSeries(series, first, end)
+
+	openers := map[string]bool{}
+	clickers := map[string]bool{}
+	links := map[string]*LinkEngagement{}
+	linkClickers := map[string]map[string]bool{}
+	bounces := map[[2]string]*BounceBreakdown{}
+
+	for day := last; !day.Before(first); day = day.AddDate(0, 0, -1) {
+		if !day.Equal(last) {
+			if err := c.pageWait(ctx); err != nil {
+				return nil, err
+			}
+		}
+		results, err := c.MessagesSearchContext(ctx, SearchParams{
+			Range: DateRange{From: day, To: day},
+			Tags:  []string{params.Tag},
+			Limit: engagementSearchLimit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(results) >= engagementSearchLimit {
+			report.Truncated = true
+		}
+
+		for _, result := range results {
+			if result == nil {
+				continue
+			}
+			report.Messages++
+			email := strings.ToLower(result.Email)
+			if result.Opens > 0 {
+				openers[email] = true
+			}
+			if result.Clicks > 0 {
+				clickers[email] = true
+			}
+			for _, click := range result.ClicksDetail {
+				if click == nil || click.URL == "" {
+					continue
+				}
+				link := links[click.URL]
+				if link == nil {
+					link = &LinkEngagement{URL: click.URL}
+					links[click.URL] = link
+					linkClickers[click.URL] = map[string]bool{}
+				}
+				link.Clicks++
+				linkClickers[click.URL][email] = true
+			}
+			if result.State == "bounced" || result.State == "soft-bounced" {
+				diag := lastDiag(result)
+				key := [2]string{result.State, smtpCodePattern.FindString(diag)}
+				bounce := bounces[key]
+				if bounce == nil {
+					bounce = &BounceBreakdown{State: key[0], Code: key[1], Example: diag}
+					bounces[key] = bounce
+				}
+				bounce.Count++
+			}
+		}
+	}
+
+	report.UniqueOpeners = len(openers)
+	report.UniqueClickers = len(clickers)
+	report.rates()
+
+	for url, link := range links {
+		link.Clickers = len(linkClickers[url])
+		report.TopLinks = append(report.TopLinks, link)
+	}
+	sort.Slice(report.TopLinks, func(i, j int) bool {
+		a, b := report.TopLinks[i], report.TopLinks[j]
+		if a.Clicks != b.Clicks {
+			return a.Clicks > b.Clicks
+		}
+		return a.URL < b.URL
+	})
+	if len(report.TopLinks) > topLinks {
+		report.TopLinks = report.TopLinks[:topLinks]
+	}
+	for _, link := range report.TopLinks {
+		series, err := c.URLsTimeSeriesContext(ctx, link.URL)
+		if err != nil {
+			return nil, err
+		}
+		link.URLStats = sumSeries(series, first, end)
+	}
+
+	for _, bounce := range bounces {
+		report.Bounces = append(report.Bounces, bounce)
+	}
+	sort.Slice(report.Bounces, func(i, j int) bool {
+		a, b := report.Bounces[i], report.Bounces[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.State != b.State {
+			return a.State < b.State
+		}
+		return a.Code < b.Code
+	})
+
+	return report, nil
+}
+
+func (r *EngagementReport) rates() {
+	r.Delivered = r.Stats.Sent - r.Stats.HardBounces - r.Stats.SoftBounces
+	if r.Delivered > 0 {
+		r.OpenRate = float64(r.Stats.UniqueOpens) / float64(r.Delivered)
+		r.ClickRate = float64(r.Stats.UniqueClicks) / float64(r.Delivered)
+	}
+	if r.Stats.UniqueOpens > 0 {
+		r.ClickToOpenRate = float64(r.Stats.UniqueClicks) / float64(r.Stats.UniqueOpens)
+	}
+}
+
+// sumSeries sums the hours of series from from up to, but not including, end
+func sumSeries(series []*TimeSeries, from, end time.Time) (stats Stats) {
+	for _, entry := range series {
+		if entry != nil && !entry.Time.Before(from) && entry.Time.Before(end) {
+			stats.add(&entry.Stats)
+		}
+	}
+	return stats
+}
+
+// lastDiag returns the diagnostic of the message's last SMTP event that has one
+func lastDiag(result *SearchResult) string {
+	for i := len(result.SMTPEvents) - 1; i >= 0; i-- {
+		if event := result.SMTPEvents[i]; event != nil && event.Diag != "" {
+			return strings.TrimPrefix(event.Diag, "smtp;")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package mandrill
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Engagement //////////
+
+func Test_Engagement(t *testing.T) {
+	freezeNow(t, time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC))
+
+	var searches []string
+	var urls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		switch r.URL.Path {
+		case "/tags/time-series.json":
+			fmt.Fprint(w, `[
+				{"time":"2024-02-29 23:00:00","sent":100,"opens":100},
+				{"time":"2024-03-01 10:00:00","sent":60,"hard_bounces":2,"soft_bounces":1,"opens":30,"unique_opens":20,"clicks":12,"unique_clicks":8},
+				{"time":"2024-03-02 09:00:00","sent":40,"hard_bounces":1,"opens":20,"unique_opens":12,"clicks":5,"unique_clicks":4}
+			]`)
+		case "/messages/search.json":
+			searches = append(searches, fmt.Sprintf("%v %v", payload["date_from"], payload["tags"]))
+			if payload["date_from"] == "2024-03-02" {
+				fmt.Fprint(w, `[
+					{"_id":"1","email":"bob@example.com","state":"sent","opens":2,"clicks":2,"clicks_detail":[{"url":"https://example.com/a"},{"url":"https://example.com/b"}]},
+					{"_id":"2","email":"jill@example.com","state":"bounced","smtp_events":[{"type":"sent","diag":"250 OK"},{"type":"bounced","diag":"smtp;550 5.1.1 User unknown"}]}
+				]`)
+				return
+			}
+			fmt.Fprint(w, `[
+				{"_id":"3","email":"BOB@example.com","state":"sent","opens":1,"clicks":1,"clicks_detail":[{"url":"https://example.com/a"}]},
+				{"_id":"4","email":"sam@example.com","state":"sent","opens":1},
+				{"_id":"5","email":"amy@example.com","state":"bounced","smtp_events":[{"type":"bounced","diag":"550 5.1.1 No such user"}]},
+				{"_id":"6","email":"al@example.com","state":"soft-bounced","smtp_events":[{"type":"soft-bounced","diag":"452 4.2.2 Mailbox full"}]}
+			]`)
+		case "/urls/time-series.json":
+			urls = append(urls, payload["url"].(string))
+			fmt.Fprint(w, `[{"time":"2024-02-29 23:00:00","clicks":50},{"time":"2024-03-01 11:00:00","clicks":7,"unique_clicks":5}]`)
+		}
+	}))
+	defer server.Close()
+	c := &Client{Key: "APIKEY", BaseURL: server.URL + "/", HTTPClient: &http.Client{}}
+
+	report, err := c.Engagement(context.Background(), EngagementParams{
+		Tag:      "spring-sale",
+		Range:    DateRange{From: time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)},
+		TopLinks: 1,
+	})
+	expect(t, err, nil)
+	expect(t, fmt.Sprint(searches), "[2024-03-02 [spring-sale] 2024-03-01 [spring-sale]]")
+
+	expect(t, report.Tag, "spring-sale")
+	expect(t, report.Range.From, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	expect(t, report.Range.To, time.Date(2024, 3, 2, 23, 59, 59, 0, time.UTC))
+	expect(t, report.Stats.Sent, 100)
+	expect(t, report.Stats.UniqueOpens, 32)
+	expect(t, report.Delivered, 96)
+	expect(t, report.OpenRate, 32.0/96)
+	expect(t, report.ClickRate, 12.0/96)
+	expect(t, report.ClickToOpenRate, 12.0/32)
+
+	expect(t, report.Messages, 6)
+	expect(t, report.Truncated, false)
+	expect(t, report.UniqueOpeners, 2)
+	expect(t, report.UniqueClickers, 1)
+
+	expect(t, len(report.TopLinks), 1)
+	expect(t, report.TopLinks[0].URL, "https://example.com/a")
+	expect(t, report.TopLinks[0].Clicks, 2)
+	expect(t, report.TopLinks[0].Clickers, 1)
+	expect(t, report.TopLinks[0].URLStats.Clicks, 7)
+	expect(t, fmt.Sprint(urls), "[https://example.com/a]")
+
+	expect(t, len(report.Bounces), 2)
+	expect(t, *report.Bounces[0], BounceBreakdown{State: "bounced", Code: "550 5.1.1", Count: 2, Example: "550 5.1.1 User unknown"})
+	expect(t, *report.Bounces[1], BounceBreakdown{State: "soft-bounced", Code: "452 4.2.2", Count: 1, Example: "452 4.2.2 Mailbox full"})
+}
+
+func Test_Engagement_Error(t *testing.T) {
+	server, c := testTools(500, `{"status":"error","code":-1,"name":"Invalid_Key","message":"Invalid API key"}`)
+	defer server.Close()
+
+	report, err := c.Engagement(context.Background(), EngagementParams{Tag: "spring-sale"})
+	expect(t, report, (*EngagementReport)(nil))
+	expect(t, err.Error(), "Invalid API key")
+}
@@ -0,0 +1,299 @@
+package mandrill
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// DefaultPollInterval is the interval WaitForExport and WaitForDelivery poll at when Client.PollInterval is zero
+const DefaultPollInterval = 10 * time.Second
+
+// Export is an export job
+type Export struct {
+	// the unique identifier for this export job
+	ID string `json:"id"`
+	// the date and time that the export job was created
+	CreatedAt Time `json:"created_at"`
+	// the type of the export job - activity, reject, or whitelist
+	Type string `json:"type"`
+	// the date and time that the export job was finished
+	FinishedAt Time `json:"finished_at"`
+	// the export job's state - one of "waiting", "working", "complete", "error", or "expired"
+	State string `json:"state"`
+	// the url for the export job's results, if the job is completed
+	ResultURL string `json:"result_url"`
+}
+
+// ActivityExportParams selects the messages included in an activity export
+type ActivityExportParams struct {
+	// an optional email address to notify when the export job has finished
+	NotifyEmail string
+	// an optional range of send times to include. Zero ends are unbounded.
+	Range DateRange
+	// an optional list of tags to filter by
+	Tags []string
+	// an optional list of senders to filter by
+	Senders []string
+	// an optional list of states to filter by, e.g. "sent", "bounced", "rejected"
+	States []string
+	// an optional list of API keys to filter by
+	APIKeys []string
+}
+
+// ExportsList returns the account's export jobs
+func (c *Client) ExportsList() ([]*Export, error) {
+	return c.ExportsListContext(context.Background())
+}
+
+// ExportsListContext is like ExportsList but with a context
+func (c *Client) ExportsListContext(ctx context.Context) (exports []*Export, err error) {
+	var data struct {
+		Key string `json:"key"`
+	}
+
+	data.Key = c.Key
+
+	err = c.call(ctx, "exports/list.json", data, &exports)
+	return exports, err
+}
+
+// ExportsInfo returns the state of an export job
+func (c *Client) ExportsInfo(id string) (*Export, error) {
+	return c.ExportsInfoContext(context.Background(), id)
+}
+
+// ExportsInfoContext is like ExportsInfo but with a context
+func (c *Client) ExportsInfoContext(ctx context.Context, id string) (export *Export, err error) {
+	var data struct {
+		Key string `json:"key"`
+		ID  string `json:"id"`
+	}
+
+	data.Key = c.Key
+	data.ID = id
+
+	err = c.call(ctx, "exports/info.json", data, &export)
+	return export, err
+}
+
+// ExportsRejects begins an export of the rejection denylist
+func (c *Client) ExportsRejects(notifyEmail string) (*Export, error) {
+	return c.ExportsRejectsContext(context.Background(), notifyEmail)
+}
+
+// ExportsRejectsContext is like ExportsRejects but with a context
+func (c *Client) ExportsRejectsContext(ctx context.Context, notifyEmail string) (*Export, error) {
+	return c.exportCall(ctx, "exports/rejects.json", notifyEmail)
+}
+
+// ExportsWhitelist begins an export of the allowlist
+func (c *Client) ExportsWhitelist(notifyEmail string) (*Export, error) {
+	return c.ExportsWhitelistContext(context.Background(), notifyEmail)
+}
+
+// ExportsWhitelistContext is like ExportsWhitelist but with a context
+func (c *Client) ExportsWhitelistContext(ctx context.Context, notifyEmail string) (*Export, error) {
+	return c.exportCall(ctx, "exports/whitelist.json", notifyEmail)
+}
+
+func (c *Client) exportCall(ctx context.Context, path string, notifyEmail string) (export *Export, err error) {
+	var data struct {
+		Key         string `json:"key"`
+		NotifyEmail string `json:"notify_email,omitempty"`
+	}
+
+	data.Key = c.Key
+	data.NotifyEmail = notifyEmail
+
+	err = c.call(ctx, path, data, &export)
+	return export, err
+}
+
+// ExportsActivity begins an export of the message activity history matching params
+func (c *Client) ExportsActivity(params ActivityExportParams) (*Export, error) {
+	return c.ExportsActivityContext(context.Background(), params)
+}
+
+// ExportsActivityContext is like ExportsActivity but with a context
+func (c *Client) ExportsActivityContext(ctx context.Context, params ActivityExportParams) (export *Export, err error) {
+	var data struct {
+		Key         string   `json:"key"`
+		NotifyEmail string   `json:"notify_email,omitempty"`
+		DateFrom    string   `json:"date_from,omitempty"`
+		DateTo      string   `json:"date_to,omitempty"`
+		Tags        []string `json:"tags,omitempty"`
+		Senders     []string `json:"senders,omitempty"`
+		States      []string `json:"states,omitempty"`
+		APIKeys     []string `json:"api_keys,omitempty"`
+	}
+
+	data.Key = c.Key
+	data.NotifyEmail = params.NotifyEmail
+	if !params.Range.From.IsZero() {
+		data.DateFrom = params.Range.TimestampFrom()
+	}
+	if !params.Range.To.IsZero() {
+		data.DateTo = params.Range.TimestampTo()
+	}
+	data.Tags = params.Tags
+	data.Senders = params.Senders
+	data.States = params.States
+	data.APIKeys = params.APIKeys
+
+	err = c.call(ctx, "exports/activity.json", data, &export)
+	return export, err
+}
+
+// WaitForExport polls an export job every Client.PollInterval until it completes,
+// returning its result URL. It returns an error if the job ends in the "error"
+// or "expired" state, or when ctx is done.
+func (c *Client) WaitForExport(ctx context.Context, id string) (resultURL string, err error) {
+	interval := c.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		export, err := c.ExportsInfoContext(ctx, id)
+		if err != nil {
+			return resultURL, err
+		}
+		if export == nil {
+			return resultURL, fmt.Errorf("mandrill: export %s not found in response", id)
+		}
+
+		switch export.State {
+		case "complete":
+			return export.ResultURL, nil
+		case "error", "expired":
+			return resultURL, fmt.Errorf("mandrill: export %s ended in state %q", id, export.State)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return resultURL, ctx.Err()
+		}
+	}
+}
+
+// ExportRows reads the rows of a completed export one at a time. See OpenExport.
+type ExportRows struct {
+	file    *os.File
+	csv     io.ReadCloser
+	reader  *csv.Reader
+	columns []string
+}
+
+// OpenExport downloads the results of a completed export from its result URL,
+// e.g. as returned by WaitForExport, and opens them for reading row by row.
+// The zip archive is spooled to a temporary file rather than held in memory,
+// and rows are only parsed as Next asks for them, so exports of any size can be
+// piped elsewhere at the reader's pace. Client.Timeout doesn't apply to the
+// download; use ctx to bound it. Close the rows to remove the temporary file.
+func (c *Client) OpenExport(ctx context.Context, resultURL string) (rows *ExportRows, err error) {
+	if err = c.CheckURL(resultURL); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", resultURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.userAgent())
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, &Error{StatusCode: resp.StatusCode, Body: body}
+	}
+
+	file, err := ioutil.TempFile("", "mandrill-export-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	rows = &ExportRows{file: file}
+	defer func() {
+		if err != nil {
+			rows.Close()
+			rows = nil
+		}
+	}()
+
+	size, err := io.Copy(file, resp.Body)
+	if err != nil {
+		return rows, err
+	}
+
+	archive, err := zip.NewReader(file, size)
+	if err != nil {
+		return rows, err
+	}
+	entry := exportEntry(archive.File)
+	if entry == nil {
+		return rows, fmt.Errorf("mandrill: export archive is empty")
+	}
+	if rows.csv, err = entry.Open(); err != nil {
+		return rows, err
+	}
+
+	rows.reader = csv.NewReader(rows.csv)
+	rows.reader.FieldsPerRecord = -1
+	rows.columns, err = rows.reader.Read()
+	if err == io.EOF {
+		err = nil
+	}
+	return rows, err
+}
+
+// exportEntry returns the archive's CSV file, or its first file
+func exportEntry(files []*zip.File) *zip.File {
+	for _, f := range files {
+		if strings.EqualFold(path.Ext(f.Name), ".csv") {
+			return f
+		}
+	}
+	if len(files) > 0 {
+		return files[0]
+	}
+	return nil
+}
+
+// Columns returns the export's header row, e.g. "Date", "Email Address", "Sender"
+func (r *ExportRows) Columns() []string {
+	return r.columns
+}
+
+// Next returns the next row, or io.EOF after the last one
+func (r *ExportRows) Next() ([]string, error) {
+	if r.reader == nil {
+		return nil, io.EOF
+	}
+	return r.reader.Read()
+}
+
+// Close releases the rows and removes the downloaded archive
+func (r *ExportRows) Close() error {
+	if r.csv != nil {
+		r.csv.Close()
+	}
+	r.file.Close()
+	return os.Remove(r.file.Name())
+}
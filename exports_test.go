@@ -0,0 +1,189 @@
+package mandrill
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const exportJSON = `{"id":"2013-01-01T15:30:27.000Z-1","created_at":"2013-01-01 15:30:27","type":"activity","finished_at":"2013-01-01 15:31:27","state":"complete","result_url":"https://mandrillapp.com/exports/1.zip"}`
+
+// Exports //////////
+
+func Test_ExportsList(t *testing.T) {
+	server, c, req := testRecorder(200, "["+exportJSON+"]")
+	defer server.Close()
+
+	exports, err := c.ExportsList()
+	expect(t, err, nil)
+	expect(t, req.Path, "/exports/list.json")
+	expect(t, exports[0].Type, "activity")
+}
+
+func Test_ExportsInfo(t *testing.T) {
+	server, c, req := testRecorder(200, exportJSON)
+	defer server.Close()
+
+	export, err := c.ExportsInfo("2013-01-01T15:30:27.000Z-1")
+	expect(t, err, nil)
+	expect(t, req.Path, "/exports/info.json")
+	expect(t, req.Payload["id"], "2013-01-01T15:30:27.000Z-1")
+	expect(t, export.State, "complete")
+	expect(t, export.FinishedAt.Minute(), 31)
+}
+
+func Test_ExportsRejectsWhitelist(t *testing.T) {
+	server, c, req := testRecorder(200, exportJSON)
+	defer server.Close()
+
+	_, err := c.ExportsRejects("ops@example.com")
+	expect(t, err, nil)
+	expect(t, req.Path, "/exports/rejects.json")
+	expect(t, req.Payload["notify_email"], "ops@example.com")
+
+	_, err = c.ExportsWhitelist("")
+	expect(t, err, nil)
+	expect(t, req.Path, "/exports/whitelist.json")
+	_, hasNotify := req.Payload["notify_email"]
+	expect(t, hasNotify, false)
+}
+
+func Test_ExportsActivity(t *testing.T) {
+	server, c, req := testRecorder(200, exportJSON)
+	defer server.Close()
+
+	_, err := c.ExportsActivity(ActivityExportParams{
+		NotifyEmail: "ops@example.com",
+		Range:       DateRange{From: time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC)},
+		States:      []string{"bounced"},
+	})
+	expect(t, err, nil)
+	expect(t, req.Path, "/exports/activity.json")
+	expect(t, req.Payload["date_from"], "2013-01-01 00:00:00")
+	_, hasDateTo := req.Payload["date_to"]
+	expect(t, hasDateTo, false)
+	expect(t, reflect.DeepEqual(req.Payload["states"], []interface{}{"bounced"}), true)
+}
+
+// WaitForExport //////////
+
+func Test_WaitForExport(t *testing.T) {
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&polls, 1) < 3 {
+			fmt.Fprintln(w, `{"id":"1","state":"working"}`)
+			return
+		}
+		fmt.Fprintln(w, exportJSON)
+	}))
+	defer server.Close()
+
+	c := ClientWithKey("APIKEY")
+	c.BaseURL = server.URL + "/"
+	c.PollInterval = time.Millisecond
+
+	url, err := c.WaitForExport(context.Background(), "1")
+	expect(t, err, nil)
+	expect(t, url, "https://mandrillapp.com/exports/1.zip")
+	expect(t, atomic.LoadInt32(&polls), int32(3))
+}
+
+func Test_WaitForExport_Failed(t *testing.T) {
+	server, c, _ := testRecorder(200, `{"id":"1","state":"expired"}`)
+	defer server.Close()
+
+	_, err := c.WaitForExport(context.Background(), "1")
+	expect(t, err.Error(), `mandrill: export 1 ended in state "expired"`)
+}
+
+func Test_WaitForExport_BadResponse(t *testing.T) {
+	for _, body := range []string{`null`, `["1"]`} {
+		server, c, _ := testRecorder(200, body)
+		_, err := c.WaitForExport(context.Background(), "1")
+		refute(t, err, nil)
+		server.Close()
+	}
+}
+
+func Test_WaitForExport_Deadline(t *testing.T) {
+	server, c, _ := testRecorder(200, `{"id":"1","state":"waiting"}`)
+	defer server.Close()
+	c.PollInterval = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := c.WaitForExport(ctx, "1")
+	expect(t, err, context.DeadlineExceeded)
+}
+
+// OpenExport //////////
+
+func exportServer(t *testing.T, csv string) *httptest.Server {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	f, err := zw.Create("activity.csv")
+	expect(t, err, nil)
+	fmt.Fprint(f, csv)
+	expect(t, zw.Close(), nil)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/exports/1.zip" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(archive.Bytes())
+	}))
+}
+
+func Test_OpenExport(t *testing.T) {
+	server := exportServer(t, "Date,Email Address,Status\n2013-01-01 15:30:27,bob@example.com,sent\n2013-01-01 15:31:27,\"kim, jr@example.com\",bounced\n")
+	defer server.Close()
+	c := ClientWithKey("APIKEY")
+
+	rows, err := c.OpenExport(context.Background(), server.URL+"/exports/1.zip")
+	expect(t, err, nil)
+	expect(t, reflect.DeepEqual(rows.Columns(), []string{"Date", "Email Address", "Status"}), true)
+
+	row, err := rows.Next()
+	expect(t, err, nil)
+	expect(t, row[1], "bob@example.com")
+	row, err = rows.Next()
+	expect(t, err, nil)
+	expect(t, row[1], "kim, jr@example.com")
+	expect(t, row[2], "bounced")
+	_, err = rows.Next()
+	expect(t, err, io.EOF)
+
+	name := rows.file.Name()
+	expect(t, rows.Close(), nil)
+	_, err = os.Stat(name)
+	expect(t, os.IsNotExist(err), true)
+}
+
+func Test_OpenExport_NotFound(t *testing.T) {
+	server := exportServer(t, "")
+	defer server.Close()
+	c := ClientWithKey("APIKEY")
+
+	rows, err := c.OpenExport(context.Background(), server.URL+"/exports/2.zip")
+	expect(t, rows, (*ExportRows)(nil))
+	expect(t, err.(*Error).StatusCode, http.StatusNotFound)
+}
+
+func Test_OpenExport_NotAllowed(t *testing.T) {
+	c := ClientWithKey("APIKEY")
+	c.AllowedHosts = []string{"mandrillapp.com"}
+
+	_, err := c.OpenExport(context.Background(), "https://example.com/exports/1.zip")
+	expect(t, errors.Is(err, ErrURLNotAllowed), true)
+}
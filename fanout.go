@@ -0,0 +1,189 @@
+package mandrill
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultFanOutConcurrency is the number of concurrent sends SendIndividually uses when Client.FanOutConcurrency is zero
+const DefaultFanOutConcurrency = 10
+
+// IndividualResult is the outcome of sending to one recipient with SendIndividually
+type IndividualResult struct {
+	// the recipient the message was sent to
+	To *To
+	// the API responses for the send
+	Responses []*Response
+	// the error returned by the send, or the context's error if it was never attempted
+	Err error
+}
+
+// SendIndividually sends a copy of base to each recipient in its own API call,
+// carrying only that recipient's merge vars and metadata. Sends run concurrently,
+// up to Client.FanOutConcurrency at a time. Results are returned in recipient
+// order; the error is non-nil only if ctx ended before every send was attempted.
+//
+// With a Client.DomainThrottle, sends to a throttled domain wait for their turn
+// while holding their concurrency slot, so interleave recipients of different
+// domains to keep the others moving.
+func (c *Client) SendIndividually(ctx context.Context, base *Message, recipients []*To) ([]*IndividualResult, error) {
+	limit := c.FanOutConcurrency
+	if limit <= 0 {
+		limit = DefaultFanOutConcurrency
+	}
+
+	results := make([]*IndividualResult, len(recipients))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i, to := range recipients {
+		if ctx.Err() == nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			for j := i; j < len(recipients); j++ {
+				results[j] = &IndividualResult{To: recipients[j], Err: err}
+			}
+			wg.Wait()
+			return results, err
+		}
+
+		results[i] = &IndividualResult{To: to}
+		wg.Add(1)
+		go func(res *IndividualResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := c.DomainThrottle.wait(ctx, res.To.Email); err != nil {
+				res.Err = err
+				return
+			}
+			res.Responses, res.Err = c.MessagesSendContext(ctx, base.forRecipient(res.To))
+		}(results[i])
+	}
+
+	wg.Wait()
+	return results, nil
+}
+
+// DomainThrottle limits the rate of SendIndividually's sends to each recipient
+// domain, e.g. to stay under an ISP's deferral threshold. Sends to a domain are
+// spaced evenly, so 600 per minute sends one every 100ms rather than bursts of 600.
+// Share one between clients to limit their combined rate.
+type DomainThrottle struct {
+	// messages per minute allowed to each recipient domain, keyed by lower case
+	// domain, e.g. {"yahoo.com": 600}. Subdomains need their own entries.
+	Limits map[string]int
+	// optional messages per minute allowed to each domain not in Limits. Zero leaves them unlimited.
+	Default int
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// reserve claims the next send slot for the recipient's domain, returning how long to wait for it
+func (d *DomainThrottle) reserve(email string) time.Duration {
+	domain := strings.ToLower(email[strings.LastIndex(email, "@")+1:])
+	limit, ok := d.Limits[domain]
+	if !ok {
+		limit = d.Default
+	}
+	if limit <= 0 {
+		return 0
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.next == nil {
+		d.next = map[string]time.Time{}
+	}
+	t := now()
+	slot := d.next[domain]
+	if slot.Before(t) {
+		slot = t
+	}
+	d.next[domain] = slot.Add(time.Minute / time.Duration(limit))
+	return slot.Sub(t)
+}
+
+// wait blocks until the recipient's domain may be sent to, or ctx ends
+func (d *DomainThrottle) wait(ctx context.Context, email string) error {
+	if d == nil {
+		return nil
+	}
+	delay := d.reserve(email)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// forRecipient returns a copy of the message addressed only to the recipient
+func (m *Message) forRecipient(to *To) *Message {
+	msg := m.clone()
+	msg.To = []*To{{Email: to.Email, Name: to.Name, Type: to.Type}}
+	if m.IdempotencyKey != "" {
+		msg.IdempotencyKey = m.IdempotencyKey + ":" + strings.ToLower(to.Email)
+	}
+
+	msg.MergeVars = nil
+	for _, vars := range m.MergeVars {
+		if strings.EqualFold(vars.Rcpt, to.Email) {
+			msg.MergeVars = append(msg.MergeVars, vars)
+		}
+	}
+
+	msg.RecipientMetadata = nil
+	for _, metadata := range m.RecipientMetadata {
+		if strings.EqualFold(metadata.Rcpt, to.Email) {
+			msg.RecipientMetadata = append(msg.RecipientMetadata, metadata)
+		}
+	}
+
+	return msg
+}
+
+// clone copies the message along with the slices and maps sending may modify
+func (m *Message) clone() *Message {
+	msg := *m
+	msg.To = nil
+	for _, to := range m.To {
+		if to != nil {
+			copied := *to
+			to = &copied
+		}
+		msg.To = append(msg.To, to)
+	}
+	msg.Tags = append([]string(nil), m.Tags...)
+	msg.GoogleAnalyticsDomains = append([]string(nil), m.GoogleAnalyticsDomains...)
+	msg.Attachments = append([]*Attachment(nil), m.Attachments...)
+	msg.Images = append([]*Attachment(nil), m.Images...)
+	msg.Headers = copyStringMap(m.Headers)
+	msg.Metadata = copyStringMap(m.Metadata)
+	return &msg
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
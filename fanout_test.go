@@ -0,0 +1,144 @@
+package mandrill
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// SendIndividually //////////
+
+func Test_SendIndividually(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string]*Message{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data struct {
+			Message *Message `json:"message"`
+		}
+		json.NewDecoder(r.Body).Decode(&data)
+		email := data.Message.To[0].Email
+		mu.Lock()
+		received[email] = data.Message
+		mu.Unlock()
+		fmt.Fprintf(w, `[{"email":%q,"status":"sent","_id":"id-%s"}]`, email, email)
+	}))
+	defer server.Close()
+
+	c := ClientWithKey("APIKEY")
+	c.BaseURL = server.URL + "/"
+	c.FanOutConcurrency = 2
+	c.Defaults.Tags = []string{"fanout"}
+
+	base := &Message{Subject: "Hi", Tags: []string{"base"}}
+	base.MergeVars = []*RcptMergeVars{
+		MapToRecipientVars("bob@example.com", map[string]string{"name": "Bob"}),
+		MapToRecipientVars("jill@example.com", map[string]string{"name": "Jill"}),
+	}
+	base.RecipientMetadata = []*RcptMetadata{{Rcpt: "jill@example.com", Values: map[string]interface{}{"id": "2"}}}
+
+	recipients := []*To{{Email: "bob@example.com"}, {Email: "jill@example.com"}, {Email: "sam@example.com"}}
+	results, err := c.SendIndividually(context.Background(), base, recipients)
+
+	expect(t, err, nil)
+	expect(t, len(results), 3)
+	for i, res := range results {
+		expect(t, res.Err, nil)
+		expect(t, res.To, recipients[i])
+		expect(t, res.Responses[0].Id, "id-"+recipients[i].Email)
+	}
+
+	expect(t, len(received), 3)
+	expect(t, len(received["bob@example.com"].To), 1)
+	expect(t, received["bob@example.com"].MergeVars[0].Vars[0].Content, "Bob")
+	expect(t, len(received["bob@example.com"].RecipientMetadata), 0)
+	expect(t, received["jill@example.com"].RecipientMetadata[0].Rcpt, "jill@example.com")
+	expect(t, len(received["sam@example.com"].MergeVars), 0)
+	expect(t, reflect.DeepEqual(received["sam@example.com"].Tags, []string{"base", "fanout"}), true)
+	expect(t, reflect.DeepEqual(base.Tags, []string{"base"}), true)
+}
+
+func Test_SendIndividually_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := ClientWithKey("SANDBOX_SUCCESS")
+	results, err := c.SendIndividually(ctx, &Message{}, []*To{{Email: "bob@example.com"}, {Email: "jill@example.com"}})
+
+	expect(t, err, context.Canceled)
+	expect(t, len(results), 2)
+	expect(t, results[1].Err, context.Canceled)
+}
+
+func Test_DomainThrottle_Reserve(t *testing.T) {
+	freezeNow(t, time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC))
+	d := &DomainThrottle{Limits: map[string]int{"yahoo.com": 60}, Default: 120}
+
+	expect(t, d.reserve("bob@yahoo.com"), time.Duration(0))
+	expect(t, d.reserve("jill@YAHOO.com"), time.Second)
+	expect(t, d.reserve("sam@yahoo.com"), 2*time.Second)
+	expect(t, d.reserve("bob@example.com"), time.Duration(0))
+	expect(t, d.reserve("jill@example.com"), 500*time.Millisecond)
+
+	unlimited := &DomainThrottle{Limits: map[string]int{"yahoo.com": 60}}
+	expect(t, unlimited.reserve("bob@example.com"), time.Duration(0))
+	expect(t, unlimited.reserve("jill@example.com"), time.Duration(0))
+}
+
+func Test_SendIndividually_DomainThrottle(t *testing.T) {
+	var mu sync.Mutex
+	sent := map[string]time.Time{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data struct {
+			Message *Message `json:"message"`
+		}
+		json.NewDecoder(r.Body).Decode(&data)
+		email := data.Message.To[0].Email
+		mu.Lock()
+		sent[email] = time.Now()
+		mu.Unlock()
+		fmt.Fprintf(w, `[{"email":%q,"status":"sent"}]`, email)
+	}))
+	defer server.Close()
+
+	c := ClientWithKey("APIKEY")
+	c.BaseURL = server.URL + "/"
+	c.DomainThrottle = &DomainThrottle{Limits: map[string]int{"yahoo.com": 1200}}
+
+	start := time.Now()
+	recipients := []*To{{Email: "a@yahoo.com"}, {Email: "b@yahoo.com"}, {Email: "c@yahoo.com"}, {Email: "d@example.com"}}
+	results, err := c.SendIndividually(context.Background(), &Message{}, recipients)
+	expect(t, err, nil)
+	for _, res := range results {
+		expect(t, res.Err, nil)
+	}
+
+	expect(t, sent["c@yahoo.com"].Sub(start) >= 100*time.Millisecond, true)
+	expect(t, sent["d@example.com"].Sub(start) < 100*time.Millisecond, true)
+}
+
+func Test_SendIndividually_DomainThrottleCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	c := ClientWithKey("SANDBOX_SUCCESS")
+	c.DomainThrottle = &DomainThrottle{Default: 1}
+	results, err := c.SendIndividually(ctx, &Message{}, []*To{{Email: "bob@example.com"}, {Email: "jill@example.com"}})
+
+	// the first send to reserve a slot goes out, the other's wait is cut short
+	expect(t, err, nil)
+	waited := 0
+	for _, res := range results {
+		if res.Err == context.DeadlineExceeded {
+			waited++
+		} else {
+			expect(t, res.Err, nil)
+		}
+	}
+	expect(t, waited, 1)
+}
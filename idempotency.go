@@ -0,0 +1,138 @@
+package mandrill
+
+import (
+	"context"
+	"errors"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrDuplicateSend is returned when a message's IdempotencyKey has already been sent
+var ErrDuplicateSend = errors.New("mandrill: duplicate send refused")
+
+// IdempotencyStore remembers the idempotency keys of recently sent messages.
+// Implementations shared between processes can be built on e.g. Redis' SET NX.
+type IdempotencyStore interface {
+	// Claim records key and reports whether it was new. Only one of several
+	// concurrent claims of the same key may succeed.
+	Claim(key string) (bool, error)
+	// Release forgets a claimed key so a failed send can be retried
+	Release(key string) error
+}
+
+// sendIdempotent claims the message's IdempotencyKey before sending, and releases it only if the
+// send certainly wasn't accepted. Ambiguous failures, like a timeout after the request was written,
+// keep the key claimed: the message may have been sent, and a retry could send it twice.
+func (c *Client) sendIdempotent(ctx context.Context, message *Message, data interface{}, path string) (responses []*Response, err error) {
+	key := message.IdempotencyKey
+	claimed, err := c.IdempotencyStore.Claim(key)
+	if err != nil {
+		return responses, err
+	}
+	if !claimed {
+		return responses, ErrDuplicateSend
+	}
+
+	responses, refused, err := c.deliverMessagePayload(ctx, message, data, path)
+	if err != nil && refused {
+		if releaseErr := c.IdempotencyStore.Release(key); releaseErr != nil {
+			return responses, releaseErr
+		}
+	}
+	return responses, err
+}
+
+// sendRefused reports whether a failed request certainly wasn't acted on: Mandrill answered with an
+// API error, or the request failed before it was completely written, e.g. the connection was refused
+// or the client refused it locally. Other failures, including timeouts and connections reset while
+// waiting for the response, are ambiguous. So are errors without a Mandrill error name, such as a
+// proxy's 504, since the proxy may have forwarded the request.
+func sendRefused(err error, written bool) bool {
+	switch e := err.(type) {
+	case *ThrottledError, *RetryBudgetError:
+		return true
+	case *Error:
+		return e.Body == nil || e.StatusCode < 500
+	}
+	return !written
+}
+
+// requestWritten records whether the last attempt at a request was completely written
+type requestWritten struct {
+	atomic.Bool
+}
+
+// trace returns ctx with an httptrace.ClientTrace recording each attempt
+func (w *requestWritten) trace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: func(string) { w.Store(false) },
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			if info.Err == nil {
+				w.Store(true)
+			}
+		},
+	})
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore. Keys are forgotten
+// after TTL, or kept for the life of the process when TTL is zero. The zero value is usable.
+type MemoryIdempotencyStore struct {
+	TTL time.Duration
+
+	mu   sync.Mutex
+	keys map[string]time.Time
+}
+
+// NewMemoryIdempotencyStore returns a MemoryIdempotencyStore that forgets keys after ttl
+func NewMemoryIdempotencyStore(ttl time.Duration) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{TTL: ttl}
+}
+
+// Claim records key and reports whether it was new or had expired
+func (s *MemoryIdempotencyStore) Claim(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := now()
+	if s.keys == nil {
+		s.keys = map[string]time.Time{}
+	}
+	s.expire(current)
+
+	if _, ok := s.keys[key]; ok {
+		return false, nil
+	}
+	s.keys[key] = current
+	return true, nil
+}
+
+// Release forgets key
+func (s *MemoryIdempotencyStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, key)
+	return nil
+}
+
+// Len returns the number of keys held
+func (s *MemoryIdempotencyStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(now())
+	return len(s.keys)
+}
+
+func (s *MemoryIdempotencyStore) expire(current time.Time) {
+	if s.TTL <= 0 {
+		return
+	}
+	for key, claimedAt := range s.keys {
+		if current.Sub(claimedAt) >= s.TTL {
+			delete(s.keys, key)
+		}
+	}
+}
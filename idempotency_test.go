@@ -0,0 +1,182 @@
+package mandrill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Idempotency //////////
+
+func Test_Idempotency_RefusesDuplicates(t *testing.T) {
+	server, c, _ := testRecorder(200, `[{"email":"bob@example.com","status":"sent","_id":"1"}]`)
+	defer server.Close()
+	c.IdempotencyStore = &MemoryIdempotencyStore{}
+
+	message := &Message{IdempotencyKey: "receipt-42"}
+	message.AddRecipient("bob@example.com", "Bob", "to")
+
+	responses, err := c.MessagesSend(message)
+	expect(t, err, nil)
+	expect(t, len(responses), 1)
+
+	responses, err = c.MessagesSend(message)
+	expect(t, err, ErrDuplicateSend)
+	expect(t, len(responses), 0)
+
+	message.IdempotencyKey = ""
+	_, err = c.MessagesSend(message)
+	expect(t, err, nil)
+}
+
+func Test_Idempotency_ReleasesFailedSends(t *testing.T) {
+	server, c, _ := testRecorder(500, `{"status":"error","code":-1,"name":"GeneralError","message":"Oops"}`)
+	defer server.Close()
+	store := &MemoryIdempotencyStore{}
+	c.IdempotencyStore = store
+
+	message := &Message{IdempotencyKey: "receipt-42"}
+	_, err := c.MessagesSend(message)
+	expect(t, err.(*Error).Name, "GeneralError")
+	expect(t, store.Len(), 0)
+}
+
+func Test_Idempotency_KeepsAcceptedSends(t *testing.T) {
+	server, c, _ := testRecorder(200, `[{"email":"bob@example.com","status":"teleported","_id":"1"}]`)
+	defer server.Close()
+	store := &MemoryIdempotencyStore{}
+	c.IdempotencyStore = store
+	c.StrictStatuses = true
+
+	message := &Message{IdempotencyKey: "receipt-42"}
+	_, err := c.MessagesSend(message)
+	_, ok := err.(*UnknownStatusError)
+	expect(t, ok, true)
+	expect(t, store.Len(), 1)
+
+	_, err = c.MessagesSend(message)
+	expect(t, err, ErrDuplicateSend)
+}
+
+func Test_Idempotency_KeepsUndecodableSends(t *testing.T) {
+	server, c, _ := testRecorder(200, `{"unexpected":true}`)
+	defer server.Close()
+	store := &MemoryIdempotencyStore{}
+	c.IdempotencyStore = store
+
+	_, err := c.MessagesSend(&Message{IdempotencyKey: "receipt-42"})
+	refute(t, err, nil)
+	expect(t, store.Len(), 1)
+}
+
+func Test_Idempotency_KeepsTimedOutSends(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprintln(w, `[{"email":"bob@example.com","status":"sent","_id":"1"}]`)
+	}))
+	defer server.Close()
+
+	c := ClientWithKey("APIKEY")
+	c.BaseURL = server.URL + "/"
+	c.Timeout = 10 * time.Millisecond
+	store := &MemoryIdempotencyStore{}
+	c.IdempotencyStore = store
+
+	message := &Message{IdempotencyKey: "receipt-42"}
+	_, err := c.MessagesSend(message)
+	expect(t, errors.Is(err, context.DeadlineExceeded), true)
+	expect(t, store.Len(), 1)
+
+	c.Timeout = 0
+	_, err = c.MessagesSend(message)
+	expect(t, err, ErrDuplicateSend)
+}
+
+func Test_Idempotency_ReleasesUnsentRequests(t *testing.T) {
+	c := ClientWithKey("APIKEY")
+	c.BaseURL = "http://127.0.0.1:1/"
+	store := &MemoryIdempotencyStore{}
+	c.IdempotencyStore = store
+
+	_, err := c.MessagesSend(&Message{IdempotencyKey: "receipt-42"})
+	refute(t, err, nil)
+	expect(t, store.Len(), 0)
+}
+
+func Test_sendRefused(t *testing.T) {
+	expect(t, sendRefused(&Error{StatusCode: 500, Name: "GeneralError"}, true), true)
+	expect(t, sendRefused(&ThrottledError{}, true), true)
+	expect(t, sendRefused(&RetryBudgetError{Throttled: &ThrottledError{}}, true), true)
+	expect(t, sendRefused(&Error{StatusCode: 504, Body: []byte("Gateway Timeout")}, true), false)
+	expect(t, sendRefused(&Error{StatusCode: 404, Body: []byte("Not Found")}, true), true)
+	expect(t, sendRefused(errors.New("connection reset by peer"), true), false)
+	expect(t, sendRefused(errors.New("connection refused"), false), true)
+	expect(t, sendRefused(ErrCircuitOpen, false), true)
+}
+
+type failingStore struct{}
+
+func (failingStore) Claim(key string) (bool, error) { return false, errors.New("store down") }
+func (failingStore) Release(key string) error       { return nil }
+
+func Test_Idempotency_StoreError(t *testing.T) {
+	c := ClientWithKey("SANDBOX_SUCCESS")
+	c.IdempotencyStore = failingStore{}
+
+	_, err := c.MessagesSend(&Message{IdempotencyKey: "receipt-42"})
+	expect(t, err.Error(), "store down")
+}
+
+func Test_Idempotency_Concurrent(t *testing.T) {
+	c := ClientWithKey("SANDBOX_SUCCESS")
+	c.IdempotencyStore = &MemoryIdempotencyStore{}
+
+	var sent int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.MessagesSendContext(context.Background(), &Message{IdempotencyKey: "receipt-42"}); err == nil {
+				atomic.AddInt32(&sent, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	expect(t, sent, int32(1))
+}
+
+func Test_Idempotency_FanOutKeys(t *testing.T) {
+	message := &Message{IdempotencyKey: "digest-7"}
+	expect(t, message.forRecipient(&To{Email: "Bob@Example.com"}).IdempotencyKey, "digest-7:bob@example.com")
+
+	message.IdempotencyKey = ""
+	expect(t, message.forRecipient(&To{Email: "bob@example.com"}).IdempotencyKey, "")
+}
+
+// MemoryIdempotencyStore //////////
+
+func Test_MemoryIdempotencyStore_TTL(t *testing.T) {
+	start := time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)
+	freezeNow(t, start)
+	store := NewMemoryIdempotencyStore(time.Hour)
+
+	claimed, _ := store.Claim("a")
+	expect(t, claimed, true)
+	claimed, _ = store.Claim("a")
+	expect(t, claimed, false)
+
+	freezeNow(t, start.Add(time.Hour))
+	expect(t, store.Len(), 0)
+	claimed, _ = store.Claim("a")
+	expect(t, claimed, true)
+
+	expect(t, store.Release("a"), nil)
+	expect(t, store.Len(), 0)
+}
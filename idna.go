@@ -0,0 +1,208 @@
+package mandrill
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Punycode parameters from RFC 3492
+const (
+	punyBase        = 36
+	punyTMin        = 1
+	punyTMax        = 26
+	punySkew        = 38
+	punyDamp        = 700
+	punyInitialBias = 72
+	punyInitialN    = 128
+)
+
+// NormalizeAddress converts an internationalized email address into the form
+// Mandrill accepts: the domain is converted to punycode (e.g. bob@münchen.de
+// becomes bob@xn--mnchen-3ya.de) and the unicode local part is validated.
+// ASCII addresses are returned unchanged.
+func NormalizeAddress(email string) (string, error) {
+	if isASCII(email) {
+		return email, nil
+	}
+
+	if !utf8.ValidString(email) {
+		return email, fmt.Errorf("mandrill: address %q is not valid UTF-8", email)
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email, fmt.Errorf("mandrill: address %q has no @", email)
+	}
+	local, domain := email[:at], email[at+1:]
+
+	if local == "" {
+		return email, fmt.Errorf("mandrill: address %q has an empty local part", email)
+	}
+	if len(local) > 64 {
+		return email, fmt.Errorf("mandrill: local part of %q is longer than 64 bytes", email)
+	}
+	for _, r := range local {
+		if unicode.IsSpace(r) || unicode.IsControl(r) || strings.ContainsRune(`"(),:;<>@[\]`, r) {
+			return email, fmt.Errorf("mandrill: local part of %q contains invalid character %q", email, r)
+		}
+	}
+
+	asciiDomain, err := domainToASCII(domain)
+	if err != nil {
+		return email, fmt.Errorf("mandrill: domain of %q: %v", email, err)
+	}
+
+	return local + "@" + asciiDomain, nil
+}
+
+// normalizeAddresses applies NormalizeAddress to the message's sender, recipients and BCCAddress.
+// Nil recipients are skipped.
+func (m *Message) normalizeAddresses() (err error) {
+	if m.FromEmail, err = NormalizeAddress(m.FromEmail); err != nil {
+		return err
+	}
+	if m.BCCAddress, err = NormalizeAddress(m.BCCAddress); err != nil {
+		return err
+	}
+	for _, to := range m.To {
+		if to == nil {
+			continue
+		}
+		if to.Email, err = NormalizeAddress(to.Email); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizedCopy returns a copy of the message with its addresses normalized
+func (m *Message) normalizedCopy() (*Message, error) {
+	msg := m.clone()
+	return msg, msg.normalizeAddresses()
+}
+
+func domainToASCII(domain string) (string, error) {
+	domain = strings.NewReplacer("。", ".", "．", ".", "｡", ".").Replace(domain)
+	if domain == "" {
+		return "", fmt.Errorf("empty domain")
+	}
+
+	labels := strings.Split(strings.ToLower(domain), ".")
+	for i, label := range labels {
+		if label == "" {
+			return "", fmt.Errorf("empty label in %q", domain)
+		}
+		if !isASCII(label) {
+			encoded, err := punycodeEncode(label)
+			if err != nil {
+				return "", err
+			}
+			label = "xn--" + encoded
+		}
+		if len(label) > 63 {
+			return "", fmt.Errorf("label %q is longer than 63 bytes", label)
+		}
+		labels[i] = label
+	}
+	return strings.Join(labels, "."), nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// punycodeEncode implements the encoding procedure of RFC 3492 section 6.3
+func punycodeEncode(s string) (string, error) {
+	runes := []rune(s)
+	out := make([]byte, 0, len(s)+8)
+
+	for _, r := range runes {
+		if r < punyInitialN {
+			out = append(out, byte(r))
+		}
+	}
+	b := len(out)
+	h := b
+	if b > 0 {
+		out = append(out, '-')
+	}
+
+	n, delta, bias := rune(punyInitialN), 0, punyInitialBias
+	for h < len(runes) {
+		m := rune(unicode.MaxRune)
+		for _, r := range runes {
+			if r >= n && r < m {
+				m = r
+			}
+		}
+
+		if int(m-n) > (1<<31-1-delta)/(h+1) {
+			return "", fmt.Errorf("punycode overflow encoding %q", s)
+		}
+		delta += int(m-n) * (h + 1)
+		n = m
+
+		for _, r := range runes {
+			if r < n {
+				delta++
+			}
+			if r != n {
+				continue
+			}
+
+			q := delta
+			for k := punyBase; ; k += punyBase {
+				t := k - bias
+				if t < punyTMin {
+					t = punyTMin
+				} else if t > punyTMax {
+					t = punyTMax
+				}
+				if q < t {
+					break
+				}
+				out = append(out, punycodeDigit(t+(q-t)%(punyBase-t)))
+				q = (q - t) / (punyBase - t)
+			}
+			out = append(out, punycodeDigit(q))
+
+			bias = punycodeAdapt(delta, h+1, h == b)
+			delta = 0
+			h++
+		}
+		delta++
+		n++
+	}
+
+	return string(out), nil
+}
+
+func punycodeAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punyDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+
+	k := 0
+	for delta > ((punyBase-punyTMin)*punyTMax)/2 {
+		delta /= punyBase - punyTMin
+		k += punyBase
+	}
+	return k + (punyBase-punyTMin+1)*delta/(delta+punySkew)
+}
+
+func punycodeDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
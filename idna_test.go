@@ -0,0 +1,80 @@
+package mandrill
+
+import (
+	"testing"
+)
+
+// NormalizeAddress //////////
+
+func Test_NormalizeAddress(t *testing.T) {
+	cases := map[string]string{
+		"bob@example.com":      "bob@example.com",
+		"bob@münchen.de":       "bob@xn--mnchen-3ya.de",
+		"bob@Bücher.example":   "bob@xn--bcher-kva.example",
+		"josé@例え.jp":           "josé@xn--r8jz45g.jp",
+		"user@mail.españa。com": "user@mail.xn--espaa-rta.com",
+	}
+	for in, out := range cases {
+		got, err := NormalizeAddress(in)
+		expect(t, err, nil)
+		expect(t, got, out)
+	}
+}
+
+func Test_NormalizeAddress_Invalid(t *testing.T) {
+	for _, in := range []string{"münchen.de", "@münchen.de", "bö b@example.com", "bob@münchen..de"} {
+		_, err := NormalizeAddress(in)
+		refute(t, err, nil)
+	}
+}
+
+func Test_MessagesSend_NormalizesAddresses(t *testing.T) {
+	server, client, req := testRecorder(200, `[]`)
+	defer server.Close()
+	message := &Message{FromEmail: "kyle@bücher.example"}
+	message.AddRecipient("bob@münchen.de", "Bob", "to")
+
+	_, err := client.MessagesSend(message)
+	expect(t, err, nil)
+	sent := req.Payload["message"].(map[string]interface{})
+	expect(t, sent["from_email"], "kyle@xn--bcher-kva.example")
+	expect(t, sent["to"].([]interface{})[0].(map[string]interface{})["email"], "bob@xn--mnchen-3ya.de")
+	expect(t, message.To[0].Email, "bob@münchen.de")
+
+	message.AddRecipient("b ob@münchen.de", "Bob", "to")
+	_, err = client.MessagesSend(message)
+	refute(t, err, nil)
+}
+
+func Test_MessagesSend_NilRecipient(t *testing.T) {
+	server, client, _ := testRecorder(200, `[]`)
+	defer server.Close()
+	message := &Message{To: []*To{nil, {Email: "bob@münchen.de"}}}
+
+	_, err := client.MessagesSend(message)
+	expect(t, err, nil)
+}
+
+func Test_BuildSendPayload_NormalizesAddresses(t *testing.T) {
+	c := ClientWithKey("APIKEY")
+	message := &Message{FromEmail: "kyle@bücher.example", BCCAddress: "audit@bücher.example"}
+	message.AddRecipient("bob@münchen.de", "", "to")
+
+	payload, err := c.BuildSendPayload(message)
+	expect(t, err, nil)
+	expect(t, string(payload), `{"message":{"from_email":"kyle@xn--bcher-kva.example","to":[{"email":"bob@xn--mnchen-3ya.de","type":"to"}],"bcc_address":"audit@xn--bcher-kva.example"}}`)
+	expect(t, message.To[0].Email, "bob@münchen.de")
+
+	_, err = c.BuildSendTemplatePayload(&Message{FromEmail: "b ob@münchen.de"}, "welcome", nil)
+	refute(t, err, nil)
+}
+
+func Test_MessagesSendRaw_NormalizesAddresses(t *testing.T) {
+	server, c, req := testRecorder(200, `[]`)
+	defer server.Close()
+
+	_, err := c.MessagesSendRaw(rawMIME, "kyle@bücher.example", "", []string{"bob@münchen.de"}, nil)
+	expect(t, err, nil)
+	expect(t, req.Payload["from_email"], "kyle@xn--bcher-kva.example")
+	expect(t, req.Payload["to"].([]interface{})[0], "bob@xn--mnchen-3ya.de")
+}
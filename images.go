@@ -0,0 +1,167 @@
+package mandrill
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var imgSrcPattern = regexp.MustCompile(`(?i)(<img\b[^>]*?\bsrc\s*=\s*)("([^"]*)"|'([^']*)')`)
+
+// InlineLocalImages embeds every image whose <img src> is a local or relative
+// path, reading it from fsys (e.g. os.DirFS("templates")), and rewrites the src
+// to the image's cid: reference. Remote, data: and cid: sources are left alone.
+func (m *Message) InlineLocalImages(fsys fs.FS) error {
+	return m.inlineImages(func(name string) ([]byte, error) {
+		return fs.ReadFile(fsys, name)
+	})
+}
+
+// InlineImageAssets is like InlineLocalImages but reads images from a map of
+// path to content. Sources missing from assets are an error.
+func (m *Message) InlineImageAssets(assets map[string][]byte) error {
+	return m.inlineImages(func(name string) ([]byte, error) {
+		content, ok := assets[name]
+		if !ok {
+			return nil, fmt.Errorf("mandrill: no asset for image %q", name)
+		}
+		return content, nil
+	})
+}
+
+// AddInlineImage embeds an image and returns the cid: URL to reference it by,
+// e.g. <img src="cid:logo-1a2b3c4d">. Adding the same name and content again
+// returns the existing reference; adding the name with different content is an
+// error. To rewrite existing <img src> references to embedded images instead,
+// use InlineImageAssets.
+func (m *Message) AddInlineImage(name string, content []byte) (string, error) {
+	cid := imageContentID(name)
+	for _, image := range m.Images {
+		if image.Name != cid {
+			continue
+		}
+		if image.Content != base64.StdEncoding.EncodeToString(content) {
+			return "", fmt.Errorf("mandrill: inline image %q already added with different content", name)
+		}
+		return "cid:" + cid, nil
+	}
+
+	image, err := newImage(cid, name, content)
+	if err != nil {
+		return "", err
+	}
+	m.Images = append(m.Images, image)
+	return "cid:" + cid, nil
+}
+
+func (m *Message) inlineImages(load func(name string) ([]byte, error)) error {
+	matches := imgSrcPattern.FindAllStringSubmatchIndex(m.HTML, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	cids := map[string]string{}
+	images := []*Attachment{}
+	var out strings.Builder
+	last := 0
+	for _, match := range matches {
+		// the src value is in group 3 (double quoted) or group 4 (single quoted)
+		start, end := match[6], match[7]
+		if start < 0 {
+			start, end = match[8], match[9]
+		}
+		src := m.HTML[start:end]
+
+		name, ok := localImagePath(src)
+		if !ok {
+			continue
+		}
+
+		cid, ok := cids[name]
+		if !ok {
+			content, err := load(name)
+			if err != nil {
+				return err
+			}
+			cid = imageContentID(name)
+			image, err := newImage(cid, name, content)
+			if err != nil {
+				return err
+			}
+			images = append(images, image)
+			cids[name] = cid
+		}
+
+		out.WriteString(m.HTML[last:start])
+		out.WriteString("cid:" + cid)
+		last = end
+	}
+	out.WriteString(m.HTML[last:])
+
+	m.HTML = out.String()
+	m.Images = append(m.Images, images...)
+	return nil
+}
+
+// newImage returns an embedded image with the given content ID, detecting its
+// type from filename or content
+func newImage(cid string, filename string, content []byte) (*Attachment, error) {
+	mimeType := mime.TypeByExtension(path.Ext(filename))
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = http.DetectContentType(content)
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return nil, fmt.Errorf("mandrill: %q is not an image (%s)", filename, mimeType)
+	}
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+
+	return &Attachment{
+		Type:    mimeType,
+		Name:    cid,
+		Content: base64.StdEncoding.EncodeToString(content),
+	}, nil
+}
+
+// localImagePath returns the cleaned fs path for a local or relative src
+func localImagePath(src string) (string, bool) {
+	src = strings.TrimSpace(src)
+	lower := strings.ToLower(src)
+	if src == "" || strings.HasPrefix(src, "//") || strings.Contains(lower, "*|") || strings.Contains(lower, "{{") {
+		return "", false
+	}
+	if i := strings.Index(lower, ":"); i >= 0 && !strings.ContainsAny(lower[:i], "/?#") {
+		// has a scheme: http:, https:, cid:, data:, ...
+		return "", false
+	}
+	if i := strings.IndexAny(src, "?#"); i >= 0 {
+		src = src[:i]
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+src), "/")
+	if name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+// imageContentID derives a stable content ID from an image path
+func imageContentID(name string) string {
+	sum := sha1.Sum([]byte(name))
+	base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	base = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, base)
+	return base + "-" + hex.EncodeToString(sum[:4])
+}
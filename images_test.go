@@ -0,0 +1,88 @@
+package mandrill
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var pngContent = []byte("\x89PNG\r\n\x1a\n0000")
+
+// InlineLocalImages //////////
+
+func Test_InlineLocalImages(t *testing.T) {
+	fsys := fstest.MapFS{
+		"images/logo.png": {Data: pngContent},
+		"banner":          {Data: pngContent},
+	}
+	m := &Message{HTML: `<img src="images/logo.png"><IMG alt="x" SRC='/images/logo.png?v=2'><img src="./banner"><img src="https://example.com/a.png"><img src="cid:x"><img src="*|LOGO|*">`}
+
+	err := m.InlineLocalImages(fsys)
+	expect(t, err, nil)
+
+	logo := imageContentID("images/logo.png")
+	banner := imageContentID("banner")
+	expect(t, m.HTML, `<img src="cid:`+logo+`"><IMG alt="x" SRC='cid:`+logo+`'><img src="cid:`+banner+`"><img src="https://example.com/a.png"><img src="cid:x"><img src="*|LOGO|*">`)
+
+	expect(t, len(m.Images), 2)
+	expect(t, m.Images[0].Name, logo)
+	expect(t, m.Images[0].Type, "image/png")
+	expect(t, m.Images[0].Content, base64.StdEncoding.EncodeToString(pngContent))
+	expect(t, m.Images[1].Type, "image/png")
+	expect(t, strings.HasPrefix(logo, "logo-"), true)
+}
+
+func Test_InlineLocalImages_Missing(t *testing.T) {
+	m := &Message{HTML: `<img src="missing.png">`}
+	refute(t, m.InlineLocalImages(fstest.MapFS{}), nil)
+	expect(t, m.HTML, `<img src="missing.png">`)
+}
+
+func Test_InlineImageAssets(t *testing.T) {
+	m := &Message{HTML: `<p><img src="logo.png"></p>`}
+	err := m.InlineImageAssets(map[string][]byte{"logo.png": pngContent})
+	expect(t, err, nil)
+	expect(t, m.HTML, `<p><img src="cid:`+imageContentID("logo.png")+`"></p>`)
+
+	m = &Message{HTML: `<img src="notes.txt">`}
+	refute(t, m.InlineImageAssets(map[string][]byte{"notes.txt": []byte("hello")}), nil)
+	expect(t, len(m.Images), 0)
+}
+
+// AddInlineImage //////////
+
+func Test_AddInlineImage(t *testing.T) {
+	m := &Message{}
+	cid, err := m.AddInlineImage("logo.png", pngContent)
+	expect(t, err, nil)
+	expect(t, cid, "cid:"+imageContentID("logo.png"))
+	expect(t, len(m.Images), 1)
+	expect(t, m.Images[0].Name, imageContentID("logo.png"))
+	expect(t, m.Images[0].Type, "image/png")
+
+	again, err := m.AddInlineImage("logo.png", pngContent)
+	expect(t, err, nil)
+	expect(t, again, cid)
+	expect(t, len(m.Images), 1)
+}
+
+func Test_AddInlineImage_Conflict(t *testing.T) {
+	m := &Message{}
+	_, err := m.AddInlineImage("logo.png", pngContent)
+	expect(t, err, nil)
+
+	other := append(append([]byte(nil), pngContent...), 0)
+	cid, err := m.AddInlineImage("logo.png", other)
+	refute(t, err, nil)
+	expect(t, cid, "")
+	expect(t, len(m.Images), 1)
+}
+
+func Test_AddInlineImage_NotImage(t *testing.T) {
+	m := &Message{}
+	cid, err := m.AddInlineImage("notes", []byte("hello"))
+	refute(t, err, nil)
+	expect(t, cid, "")
+	expect(t, len(m.Images), 0)
+}
@@ -0,0 +1,209 @@
+package mandrill
+
+import (
+	"context"
+)
+
+// InboundDomain is a domain configured for inbound delivery
+type InboundDomain struct {
+	// the domain name that is accepting mail
+	Domain string `json:"domain"`
+	// the date and time that the inbound domain was added
+	CreatedAt Time `json:"created_at"`
+	// true if this inbound domain has successfully set up an MX record to deliver mail to the Mandrill servers
+	ValidMX bool `json:"valid_mx"`
+}
+
+// InboundRoute is a mailbox route on an inbound domain
+type InboundRoute struct {
+	// the unique identifier of the route
+	ID string `json:"id"`
+	// the search pattern that the mailbox name should match
+	Pattern string `json:"pattern"`
+	// the webhook URL where inbound messages will be published
+	URL string `json:"url"`
+}
+
+// InboundRecipient is a route a raw inbound message was delivered to
+type InboundRecipient struct {
+	// the email address that matched the route
+	Email string `json:"email"`
+	// the route's pattern that matched
+	Pattern string `json:"pattern"`
+	// the webhook URL that the message was posted to
+	URL string `json:"url"`
+}
+
+// InboundDomains lists the domains that have been configured for inbound delivery
+func (c *Client) InboundDomains() ([]*InboundDomain, error) {
+	return c.InboundDomainsContext(context.Background())
+}
+
+// InboundDomainsContext is like InboundDomains but with a context
+func (c *Client) InboundDomainsContext(ctx context.Context) (domains []*InboundDomain, err error) {
+	var data struct {
+		Key string `json:"key"`
+	}
+
+	data.Key = c.Key
+
+	err = c.call(ctx, "inbound/domains.json", data, &domains)
+	return domains, err
+}
+
+// InboundAddDomain adds an inbound domain to the account
+func (c *Client) InboundAddDomain(domain string) (*InboundDomain, error) {
+	return c.InboundAddDomainContext(context.Background(), domain)
+}
+
+// InboundAddDomainContext is like InboundAddDomain but with a context
+func (c *Client) InboundAddDomainContext(ctx context.Context, domain string) (*InboundDomain, error) {
+	return c.inboundDomainCall(ctx, "inbound/add-domain.json", domain)
+}
+
+// InboundCheckDomain checks the MX settings for an inbound domain. The domain must have already been added.
+func (c *Client) InboundCheckDomain(domain string) (*InboundDomain, error) {
+	return c.InboundCheckDomainContext(context.Background(), domain)
+}
+
+// InboundCheckDomainContext is like InboundCheckDomain but with a context
+func (c *Client) InboundCheckDomainContext(ctx context.Context, domain string) (*InboundDomain, error) {
+	return c.inboundDomainCall(ctx, "inbound/check-domain.json", domain)
+}
+
+// InboundDeleteDomain deletes an inbound domain from the account. All mail will stop routing for this domain immediately.
+func (c *Client) InboundDeleteDomain(domain string) (*InboundDomain, error) {
+	return c.InboundDeleteDomainContext(context.Background(), domain)
+}
+
+// InboundDeleteDomainContext is like InboundDeleteDomain but with a context
+func (c *Client) InboundDeleteDomainContext(ctx context.Context, domain string) (*InboundDomain, error) {
+	return c.inboundDomainCall(ctx, "inbound/delete-domain.json", domain)
+}
+
+func (c *Client) inboundDomainCall(ctx context.Context, path string, domain string) (result *InboundDomain, err error) {
+	var data struct {
+		Key    string `json:"key"`
+		Domain string `json:"domain"`
+	}
+
+	data.Key = c.Key
+	data.Domain = domain
+
+	err = c.call(ctx, path, data, &result)
+	return result, err
+}
+
+// InboundRoutes lists the mailbox routes defined for an inbound domain
+func (c *Client) InboundRoutes(domain string) ([]*InboundRoute, error) {
+	return c.InboundRoutesContext(context.Background(), domain)
+}
+
+// InboundRoutesContext is like InboundRoutes but with a context
+func (c *Client) InboundRoutesContext(ctx context.Context, domain string) (routes []*InboundRoute, err error) {
+	var data struct {
+		Key    string `json:"key"`
+		Domain string `json:"domain"`
+	}
+
+	data.Key = c.Key
+	data.Domain = domain
+
+	err = c.call(ctx, "inbound/routes.json", data, &routes)
+	return routes, err
+}
+
+// InboundAddRoute adds a new mailbox route to an inbound domain, posting
+// messages whose mailbox matches pattern (e.g. "mailbox-*") to url
+func (c *Client) InboundAddRoute(domain string, pattern string, url string) (*InboundRoute, error) {
+	return c.InboundAddRouteContext(context.Background(), domain, pattern, url)
+}
+
+// InboundAddRouteContext is like InboundAddRoute but with a context
+func (c *Client) InboundAddRouteContext(ctx context.Context, domain string, pattern string, url string) (route *InboundRoute, err error) {
+	var data struct {
+		Key     string `json:"key"`
+		Domain  string `json:"domain"`
+		Pattern string `json:"pattern"`
+		URL     string `json:"url"`
+	}
+
+	data.Key = c.Key
+	data.Domain = domain
+	data.Pattern = pattern
+	data.URL = url
+
+	err = c.call(ctx, "inbound/add-route.json", data, &route)
+	return route, err
+}
+
+// InboundUpdateRoute updates the pattern or webhook of an existing inbound mailbox route. Empty values are left unchanged.
+func (c *Client) InboundUpdateRoute(id string, pattern string, url string) (*InboundRoute, error) {
+	return c.InboundUpdateRouteContext(context.Background(), id, pattern, url)
+}
+
+// InboundUpdateRouteContext is like InboundUpdateRoute but with a context
+func (c *Client) InboundUpdateRouteContext(ctx context.Context, id string, pattern string, url string) (route *InboundRoute, err error) {
+	var data struct {
+		Key     string `json:"key"`
+		ID      string `json:"id"`
+		Pattern string `json:"pattern,omitempty"`
+		URL     string `json:"url,omitempty"`
+	}
+
+	data.Key = c.Key
+	data.ID = id
+	data.Pattern = pattern
+	data.URL = url
+
+	err = c.call(ctx, "inbound/update-route.json", data, &route)
+	return route, err
+}
+
+// InboundDeleteRoute deletes an existing inbound mailbox route
+func (c *Client) InboundDeleteRoute(id string) (*InboundRoute, error) {
+	return c.InboundDeleteRouteContext(context.Background(), id)
+}
+
+// InboundDeleteRouteContext is like InboundDeleteRoute but with a context
+func (c *Client) InboundDeleteRouteContext(ctx context.Context, id string) (route *InboundRoute, err error) {
+	var data struct {
+		Key string `json:"key"`
+		ID  string `json:"id"`
+	}
+
+	data.Key = c.Key
+	data.ID = id
+
+	err = c.call(ctx, "inbound/delete-route.json", data, &route)
+	return route, err
+}
+
+// InboundSendRaw takes a raw MIME document as if it had been sent to an inbound
+// address and routes it. to optionally overrides the recipients in the document;
+// mailFrom, helo and clientAddress are optional SMTP envelope details.
+func (c *Client) InboundSendRaw(rawMessage string, to []string, mailFrom string, helo string, clientAddress string) ([]*InboundRecipient, error) {
+	return c.InboundSendRawContext(context.Background(), rawMessage, to, mailFrom, helo, clientAddress)
+}
+
+// InboundSendRawContext is like InboundSendRaw but with a context
+func (c *Client) InboundSendRawContext(ctx context.Context, rawMessage string, to []string, mailFrom string, helo string, clientAddress string) (recipients []*InboundRecipient, err error) {
+	var data struct {
+		Key           string   `json:"key"`
+		RawMessage    string   `json:"raw_message"`
+		To            []string `json:"to,omitempty"`
+		MailFrom      string   `json:"mail_from,omitempty"`
+		Helo          string   `json:"helo,omitempty"`
+		ClientAddress string   `json:"client_address,omitempty"`
+	}
+
+	data.Key = c.Key
+	data.RawMessage = rawMessage
+	data.To = to
+	data.MailFrom = mailFrom
+	data.Helo = helo
+	data.ClientAddress = clientAddress
+
+	err = c.call(ctx, "inbound/send-raw.json", data, &recipients)
+	return recipients, err
+}
@@ -0,0 +1,106 @@
+package mandrill
+
+import (
+	"reflect"
+	"testing"
+)
+
+const inboundDomainJSON = `{"domain":"inbound.example.com","created_at":"2013-01-01 15:30:27","valid_mx":true}`
+
+const inboundRouteJSON = `{"id":"7.23","pattern":"mailbox-*","url":"http://example.com/webhook-url"}`
+
+// Inbound domains //////////
+
+func Test_InboundDomains(t *testing.T) {
+	server, c, req := testRecorder(200, "["+inboundDomainJSON+"]")
+	defer server.Close()
+
+	domains, err := c.InboundDomains()
+	expect(t, err, nil)
+	expect(t, req.Path, "/inbound/domains.json")
+	expect(t, req.Payload["key"], "APIKEY")
+	expect(t, len(domains), 1)
+	expect(t, domains[0].ValidMX, true)
+}
+
+func Test_InboundDomainCalls(t *testing.T) {
+	server, c, req := testRecorder(200, inboundDomainJSON)
+	defer server.Close()
+
+	calls := map[string]func(string) (*InboundDomain, error){
+		"/inbound/add-domain.json":    c.InboundAddDomain,
+		"/inbound/check-domain.json":  c.InboundCheckDomain,
+		"/inbound/delete-domain.json": c.InboundDeleteDomain,
+	}
+	for path, call := range calls {
+		domain, err := call("inbound.example.com")
+		expect(t, err, nil)
+		expect(t, req.Path, path)
+		expect(t, req.Payload["domain"], "inbound.example.com")
+		expect(t, domain.Domain, "inbound.example.com")
+	}
+}
+
+// Inbound routes //////////
+
+func Test_InboundRoutes(t *testing.T) {
+	server, c, req := testRecorder(200, "["+inboundRouteJSON+"]")
+	defer server.Close()
+
+	routes, err := c.InboundRoutes("inbound.example.com")
+	expect(t, err, nil)
+	expect(t, req.Path, "/inbound/routes.json")
+	expect(t, req.Payload["domain"], "inbound.example.com")
+	expect(t, routes[0].Pattern, "mailbox-*")
+}
+
+func Test_InboundAddRoute(t *testing.T) {
+	server, c, req := testRecorder(200, inboundRouteJSON)
+	defer server.Close()
+
+	route, err := c.InboundAddRoute("inbound.example.com", "mailbox-*", "http://example.com/webhook-url")
+	expect(t, err, nil)
+	expect(t, req.Path, "/inbound/add-route.json")
+	expect(t, req.Payload["pattern"], "mailbox-*")
+	expect(t, req.Payload["url"], "http://example.com/webhook-url")
+	expect(t, route.ID, "7.23")
+}
+
+func Test_InboundUpdateRoute(t *testing.T) {
+	server, c, req := testRecorder(200, inboundRouteJSON)
+	defer server.Close()
+
+	_, err := c.InboundUpdateRoute("7.23", "", "http://example.com/new")
+	expect(t, err, nil)
+	expect(t, req.Path, "/inbound/update-route.json")
+	expect(t, req.Payload["id"], "7.23")
+	expect(t, req.Payload["url"], "http://example.com/new")
+	_, hasPattern := req.Payload["pattern"]
+	expect(t, hasPattern, false)
+}
+
+func Test_InboundDeleteRoute(t *testing.T) {
+	server, c, req := testRecorder(200, inboundRouteJSON)
+	defer server.Close()
+
+	route, err := c.InboundDeleteRoute("7.23")
+	expect(t, err, nil)
+	expect(t, req.Path, "/inbound/delete-route.json")
+	expect(t, route.URL, "http://example.com/webhook-url")
+}
+
+// InboundSendRaw //////////
+
+func Test_InboundSendRaw(t *testing.T) {
+	server, c, req := testRecorder(200, `[{"email":"mailbox-123@inbound.example.com","pattern":"mailbox-*","url":"http://example.com/webhook-url"}]`)
+	defer server.Close()
+
+	recipients, err := c.InboundSendRaw("From: sender@example.com\nSubject: Hi\n\nBody", []string{"mailbox-123@inbound.example.com"}, "sender@example.com", "", "127.0.0.1")
+	expect(t, err, nil)
+	expect(t, req.Path, "/inbound/send-raw.json")
+	expect(t, reflect.DeepEqual(req.Payload["to"], []interface{}{"mailbox-123@inbound.example.com"}), true)
+	expect(t, req.Payload["client_address"], "127.0.0.1")
+	_, hasHelo := req.Payload["helo"]
+	expect(t, hasHelo, false)
+	expect(t, recipients[0].Pattern, "mailbox-*")
+}
@@ -0,0 +1,252 @@
+package mandrill
+
+import (
+	"context"
+)
+
+// IP is a dedicated IP address
+type IP struct {
+	// the ip address
+	IP string `json:"ip"`
+	// the date and time that the dedicated IP was created
+	CreatedAt Time `json:"created_at"`
+	// the name of the pool that this dedicated IP belongs to
+	Pool string `json:"pool"`
+	// the domain name (reverse dns) of this dedicated IP
+	Domain string `json:"domain"`
+	// information about the ip's custom dns, if it has been configured
+	CustomDNS *IPCustomDNS `json:"custom_dns,omitempty"`
+	// information about the ip's warmup status
+	Warmup *IPWarmup `json:"warmup,omitempty"`
+}
+
+// IPCustomDNS describes a dedicated IP's custom reverse DNS
+type IPCustomDNS struct {
+	// a boolean indicating whether custom dns has been configured for this ip
+	Enabled bool `json:"enabled"`
+	// whether the ip's custom dns is currently valid
+	Valid bool `json:"valid"`
+	// if the ip's custom dns is invalid, this will include details about the error
+	Error string `json:"error"`
+}
+
+// IPWarmup describes a dedicated IP's warmup status
+type IPWarmup struct {
+	// whether the ip is currently in warmup mode
+	WarmingUp bool `json:"warming_up"`
+	// the start time for the warmup process
+	StartAt Time `json:"start_at"`
+	// the end date and time for the warmup process
+	EndAt Time `json:"end_at"`
+}
+
+// IPPool is a pool of dedicated IPs
+type IPPool struct {
+	// this pool's name
+	Name string `json:"name"`
+	// the date and time that this pool was created
+	CreatedAt Time `json:"created_at"`
+	// the dedicated IPs in this pool
+	IPs []*IP `json:"ips"`
+}
+
+// IPProvision is the result of requesting a new dedicated IP
+type IPProvision struct {
+	// the date and time that the request was created
+	RequestedAt Time `json:"requested_at"`
+}
+
+// IPDeleteResult is the outcome of deleting a dedicated IP
+type IPDeleteResult struct {
+	// the ip address
+	IP string `json:"ip"`
+	// whether the ip was deleted
+	Deleted bool `json:"deleted"`
+}
+
+// IPPoolDeleteResult is the outcome of deleting an IP pool
+type IPPoolDeleteResult struct {
+	// the name of the pool
+	Pool string `json:"pool"`
+	// whether the pool was deleted
+	Deleted bool `json:"deleted"`
+}
+
+// IPsList lists the account's dedicated IPs
+func (c *Client) IPsList() ([]*IP, error) {
+	return c.IPsListContext(context.Background())
+}
+
+// IPsListContext is like IPsList but with a context
+func (c *Client) IPsListContext(ctx context.Context) (ips []*IP, err error) {
+	var data struct {
+		Key string `json:"key"`
+	}
+
+	data.Key = c.Key
+
+	err = c.call(ctx, "ips/list.json", data, &ips)
+	return ips, err
+}
+
+// IPsInfo retrieves information about a single dedicated IP
+func (c *Client) IPsInfo(ip string) (*IP, error) {
+	return c.IPsInfoContext(context.Background(), ip)
+}
+
+// IPsInfoContext is like IPsInfo but with a context
+func (c *Client) IPsInfoContext(ctx context.Context, ip string) (*IP, error) {
+	return c.ipCall(ctx, "ips/info.json", ip)
+}
+
+// IPsProvision requests an additional dedicated IP, optionally warming it up and
+// assigning it to pool. Provisioning typically completes within a few minutes.
+func (c *Client) IPsProvision(warmup bool, pool string) (*IPProvision, error) {
+	return c.IPsProvisionContext(context.Background(), warmup, pool)
+}
+
+// IPsProvisionContext is like IPsProvision but with a context
+func (c *Client) IPsProvisionContext(ctx context.Context, warmup bool, pool string) (provision *IPProvision, err error) {
+	var data struct {
+		Key    string `json:"key"`
+		Warmup bool   `json:"warmup"`
+		Pool   string `json:"pool,omitempty"`
+	}
+
+	data.Key = c.Key
+	data.Warmup = warmup
+	data.Pool = pool
+
+	err = c.call(ctx, "ips/provision.json", data, &provision)
+	return provision, err
+}
+
+// IPsStartWarmup begins the warmup process for a dedicated IP
+func (c *Client) IPsStartWarmup(ip string) (*IP, error) {
+	return c.IPsStartWarmupContext(context.Background(), ip)
+}
+
+// IPsStartWarmupContext is like IPsStartWarmup but with a context
+func (c *Client) IPsStartWarmupContext(ctx context.Context, ip string) (*IP, error) {
+	return c.ipCall(ctx, "ips/start-warmup.json", ip)
+}
+
+// IPsCancelWarmup cancels the warmup process for a dedicated IP
+func (c *Client) IPsCancelWarmup(ip string) (*IP, error) {
+	return c.IPsCancelWarmupContext(context.Background(), ip)
+}
+
+// IPsCancelWarmupContext is like IPsCancelWarmup but with a context
+func (c *Client) IPsCancelWarmupContext(ctx context.Context, ip string) (*IP, error) {
+	return c.ipCall(ctx, "ips/cancel-warmup.json", ip)
+}
+
+func (c *Client) ipCall(ctx context.Context, path string, ip string) (result *IP, err error) {
+	var data struct {
+		Key string `json:"key"`
+		IP  string `json:"ip"`
+	}
+
+	data.Key = c.Key
+	data.IP = ip
+
+	err = c.call(ctx, path, data, &result)
+	return result, err
+}
+
+// IPsSetPool moves a dedicated IP to a different pool, creating the pool first if createPool is true
+func (c *Client) IPsSetPool(ip string, pool string, createPool bool) (*IP, error) {
+	return c.IPsSetPoolContext(context.Background(), ip, pool, createPool)
+}
+
+// IPsSetPoolContext is like IPsSetPool but with a context
+func (c *Client) IPsSetPoolContext(ctx context.Context, ip string, pool string, createPool bool) (result *IP, err error) {
+	var data struct {
+		Key        string `json:"key"`
+		IP         string `json:"ip"`
+		Pool       string `json:"pool"`
+		CreatePool bool   `json:"create_pool"`
+	}
+
+	data.Key = c.Key
+	data.IP = ip
+	data.Pool = pool
+	data.CreatePool = createPool
+
+	err = c.call(ctx, "ips/set-pool.json", data, &result)
+	return result, err
+}
+
+// IPsDelete deletes a dedicated IP. This is permanent and cannot be undone.
+func (c *Client) IPsDelete(ip string) (*IPDeleteResult, error) {
+	return c.IPsDeleteContext(context.Background(), ip)
+}
+
+// IPsDeleteContext is like IPsDelete but with a context
+func (c *Client) IPsDeleteContext(ctx context.Context, ip string) (result *IPDeleteResult, err error) {
+	var data struct {
+		Key string `json:"key"`
+		IP  string `json:"ip"`
+	}
+
+	data.Key = c.Key
+	data.IP = ip
+
+	err = c.call(ctx, "ips/delete.json", data, &result)
+	return result, err
+}
+
+// IPsListPools lists the account's dedicated IP pools
+func (c *Client) IPsListPools() ([]*IPPool, error) {
+	return c.IPsListPoolsContext(context.Background())
+}
+
+// IPsListPoolsContext is like IPsListPools but with a context
+func (c *Client) IPsListPoolsContext(ctx context.Context) (pools []*IPPool, err error) {
+	var data struct {
+		Key string `json:"key"`
+	}
+
+	data.Key = c.Key
+
+	err = c.call(ctx, "ips/list-pools.json", data, &pools)
+	return pools, err
+}
+
+// IPsCreatePool creates a pool. If the pool already exists, it is returned unchanged.
+func (c *Client) IPsCreatePool(pool string) (*IPPool, error) {
+	return c.IPsCreatePoolContext(context.Background(), pool)
+}
+
+// IPsCreatePoolContext is like IPsCreatePool but with a context
+func (c *Client) IPsCreatePoolContext(ctx context.Context, pool string) (result *IPPool, err error) {
+	var data struct {
+		Key  string `json:"key"`
+		Pool string `json:"pool"`
+	}
+
+	data.Key = c.Key
+	data.Pool = pool
+
+	err = c.call(ctx, "ips/create-pool.json", data, &result)
+	return result, err
+}
+
+// IPsDeletePool deletes a pool. A pool must be empty before you can delete it.
+func (c *Client) IPsDeletePool(pool string) (*IPPoolDeleteResult, error) {
+	return c.IPsDeletePoolContext(context.Background(), pool)
+}
+
+// IPsDeletePoolContext is like IPsDeletePool but with a context
+func (c *Client) IPsDeletePoolContext(ctx context.Context, pool string) (result *IPPoolDeleteResult, err error) {
+	var data struct {
+		Key  string `json:"key"`
+		Pool string `json:"pool"`
+	}
+
+	data.Key = c.Key
+	data.Pool = pool
+
+	err = c.call(ctx, "ips/delete-pool.json", data, &result)
+	return result, err
+}
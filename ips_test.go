@@ -0,0 +1,117 @@
+package mandrill
+
+import (
+	"testing"
+)
+
+const ipJSON = `{
+	"ip": "127.0.0.1",
+	"created_at": "2013-01-01 15:50:31",
+	"pool": "Main Pool",
+	"domain": "mail7.example.mandrillapp.com",
+	"custom_dns": {"enabled": true, "valid": false, "error": "Example custom_dns error"},
+	"warmup": {"warming_up": true, "start_at": "2013-03-01 12:00:01", "end_at": "2013-03-31 12:00:01"}
+}`
+
+const ipPoolJSON = `{"name":"Main Pool","created_at":"2013-01-01 15:50:31","ips":[` + ipJSON + `]}`
+
+// IPs //////////
+
+func Test_IPsList(t *testing.T) {
+	server, c, req := testRecorder(200, "["+ipJSON+"]")
+	defer server.Close()
+
+	ips, err := c.IPsList()
+	expect(t, err, nil)
+	expect(t, req.Path, "/ips/list.json")
+	expect(t, ips[0].IP, "127.0.0.1")
+	expect(t, ips[0].CustomDNS.Error, "Example custom_dns error")
+	expect(t, ips[0].Warmup.WarmingUp, true)
+	expect(t, ips[0].Warmup.EndAt.Day(), 31)
+}
+
+func Test_IPsCalls(t *testing.T) {
+	server, c, req := testRecorder(200, ipJSON)
+	defer server.Close()
+
+	calls := map[string]func(string) (*IP, error){
+		"/ips/info.json":          c.IPsInfo,
+		"/ips/start-warmup.json":  c.IPsStartWarmup,
+		"/ips/cancel-warmup.json": c.IPsCancelWarmup,
+	}
+	for path, call := range calls {
+		ip, err := call("127.0.0.1")
+		expect(t, err, nil)
+		expect(t, req.Path, path)
+		expect(t, req.Payload["ip"], "127.0.0.1")
+		expect(t, ip.Pool, "Main Pool")
+	}
+}
+
+func Test_IPsProvision(t *testing.T) {
+	server, c, req := testRecorder(200, `{"requested_at":"2013-01-01 01:52:21"}`)
+	defer server.Close()
+
+	provision, err := c.IPsProvision(true, "Main Pool")
+	expect(t, err, nil)
+	expect(t, req.Path, "/ips/provision.json")
+	expect(t, req.Payload["warmup"], true)
+	expect(t, req.Payload["pool"], "Main Pool")
+	expect(t, provision.RequestedAt.Minute(), 52)
+}
+
+func Test_IPsSetPool(t *testing.T) {
+	server, c, req := testRecorder(200, ipJSON)
+	defer server.Close()
+
+	_, err := c.IPsSetPool("127.0.0.1", "Warmup Pool", true)
+	expect(t, err, nil)
+	expect(t, req.Path, "/ips/set-pool.json")
+	expect(t, req.Payload["pool"], "Warmup Pool")
+	expect(t, req.Payload["create_pool"], true)
+}
+
+func Test_IPsDelete(t *testing.T) {
+	server, c, req := testRecorder(200, `{"ip":"127.0.0.1","deleted":true}`)
+	defer server.Close()
+
+	result, err := c.IPsDelete("127.0.0.1")
+	expect(t, err, nil)
+	expect(t, req.Path, "/ips/delete.json")
+	expect(t, result.Deleted, true)
+}
+
+// IP pools //////////
+
+func Test_IPsListPools(t *testing.T) {
+	server, c, req := testRecorder(200, "["+ipPoolJSON+"]")
+	defer server.Close()
+
+	pools, err := c.IPsListPools()
+	expect(t, err, nil)
+	expect(t, req.Path, "/ips/list-pools.json")
+	expect(t, pools[0].Name, "Main Pool")
+	expect(t, pools[0].IPs[0].IP, "127.0.0.1")
+}
+
+func Test_IPsCreatePool(t *testing.T) {
+	server, c, req := testRecorder(200, ipPoolJSON)
+	defer server.Close()
+
+	pool, err := c.IPsCreatePool("Main Pool")
+	expect(t, err, nil)
+	expect(t, req.Path, "/ips/create-pool.json")
+	expect(t, req.Payload["pool"], "Main Pool")
+	expect(t, pool.Name, "Main Pool")
+}
+
+func Test_IPsDeletePool(t *testing.T) {
+	server, c, req := testRecorder(200, `{"pool":"Main Pool","deleted":true}`)
+	defer server.Close()
+
+	result, err := c.IPsDeletePool("Main Pool")
+	expect(t, err, nil)
+	expect(t, req.Path, "/ips/delete-pool.json")
+	expect(t, result.Pool, "Main Pool")
+	expect(t, result.Deleted, true)
+}
@@ -0,0 +1,87 @@
+//go:build go1.23
+
+package mandrill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// ErrSearchTruncated is yielded by SearchIter for a day with more matches than the search limit
+var ErrSearchTruncated = errors.New("mandrill: search results truncated")
+
+// SearchIter returns an iterator over the messages matching params, newest day
+// first. Mandrill caps each search at 1000 results, so the range is searched one
+// UTC day at a time. A day with as many results as the limit yields its results
+// and then an error wrapping ErrSearchTruncated; stop ranging, or carry on to the
+// earlier days. A zero From searches the 7 days before To, like Mandrill; a zero
+// To searches up to now. Client.PageInterval spaces the searches. Any other
+// error is yielded once and ends the iteration.
+//
+//	for result, err := range client.SearchIter(ctx, params) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    load(result)
+//	}
+func (c *Client) SearchIter(ctx context.Context, params SearchParams) iter.Seq2[*SearchResult, error] {
+	return func(yield func(*SearchResult, error) bool) {
+		to := params.Range.To
+		if to.IsZero() {
+			to = now()
+		}
+		from := params.Range.From
+		if from.IsZero() {
+			from = to.AddDate(0, 0, -searchDefaultDays)
+		}
+		limit := params.Limit
+		if limit <= 0 {
+			limit = 1000
+		}
+
+		first := dayStart(from)
+		for day := dayStart(to); !day.Before(first); day = day.AddDate(0, 0, -1) {
+			if !day.Equal(dayStart(to)) {
+				if err := c.pageWait(ctx); err != nil {
+					yield(nil, err)
+					return
+				}
+			}
+
+			page := params
+			page.Range = DateRange{From: day, To: day}
+			page.Limit = limit
+			results, err := c.MessagesSearchContext(ctx, page)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, result := range results {
+				if !yield(result, nil) {
+					return
+				}
+			}
+			if len(results) >= limit && !yield(nil, fmt.Errorf("%w: %s has more than %d matches", ErrSearchTruncated, day.Format(DateFormat), limit)) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the remaining rows. A read error is yielded once and ends the iteration.
+func (r *ExportRows) All() iter.Seq2[[]string, error] {
+	return func(yield func([]string, error) bool) {
+		for {
+			row, err := r.Next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if !yield(row, err) || err != nil {
+				return
+			}
+		}
+	}
+}
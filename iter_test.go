@@ -0,0 +1,151 @@
+//go:build go1.23
+
+package mandrill
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// searchServer answers each day's search with the ids listed for it
+func searchServer(days map[string][]string) (*httptest.Server, *Client, *[]string) {
+	var searched []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		day := payload["date_from"].(string)
+		searched = append(searched, day+"/"+payload["date_to"].(string))
+		if day == "2024-03-01" {
+			w.WriteHeader(500)
+			fmt.Fprint(w, `{"status":"error","code":-1,"name":"GeneralError","message":"Oops"}`)
+			return
+		}
+		results := []string{}
+		for _, id := range days[day] {
+			results = append(results, fmt.Sprintf(`{"_id":%q}`, id))
+		}
+		fmt.Fprint(w, "["+strings.Join(results, ",")+"]")
+	}))
+	client := &Client{Key: "APIKEY", BaseURL: server.URL + "/", HTTPClient: &http.Client{}}
+	return server, client, &searched
+}
+
+// Iterators //////////
+
+func Test_SearchIter(t *testing.T) {
+	freezeNow(t, time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC))
+	server, c, searched := searchServer(map[string][]string{
+		"2024-03-05": {"e", "d"},
+		"2024-03-03": {"c"},
+	})
+	defer server.Close()
+
+	var ids []string
+	for result, err := range c.SearchIter(context.Background(), SearchParams{Query: "email:example.com", Range: LastDays(3)}) {
+		expect(t, err, nil)
+		ids = append(ids, result.ID)
+	}
+	expect(t, strings.Join(ids, ","), "e,d,c")
+	expect(t, strings.Join(*searched, " "), "2024-03-05/2024-03-05 2024-03-04/2024-03-04 2024-03-03/2024-03-03")
+}
+
+func Test_SearchIter_DefaultRange(t *testing.T) {
+	freezeNow(t, time.Date(2024, 3, 12, 12, 0, 0, 0, time.UTC))
+	server, c, searched := searchServer(nil)
+	defer server.Close()
+
+	for range c.SearchIter(context.Background(), SearchParams{}) {
+	}
+	expect(t, len(*searched), 8)
+	expect(t, (*searched)[7], "2024-03-05/2024-03-05")
+}
+
+func Test_SearchIter_Truncated(t *testing.T) {
+	freezeNow(t, time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC))
+	server, c, searched := searchServer(map[string][]string{
+		"2024-03-05": {"c", "b"},
+		"2024-03-04": {"a"},
+	})
+	defer server.Close()
+
+	var ids []string
+	var truncated error
+	for result, err := range c.SearchIter(context.Background(), SearchParams{Range: LastDays(2), Limit: 2}) {
+		if err != nil {
+			truncated = err
+			continue
+		}
+		ids = append(ids, result.ID)
+	}
+	expect(t, strings.Join(ids, ","), "c,b,a")
+	expect(t, errors.Is(truncated, ErrSearchTruncated), true)
+	expect(t, truncated.Error(), "mandrill: search results truncated: 2024-03-05 has more than 2 matches")
+	expect(t, len(*searched), 2)
+}
+
+func Test_SearchIter_Error(t *testing.T) {
+	freezeNow(t, time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC))
+	server, c, searched := searchServer(map[string][]string{"2024-03-02": {"b"}})
+	defer server.Close()
+
+	var errs []error
+	for _, err := range c.SearchIter(context.Background(), SearchParams{Range: LastDays(5)}) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	expect(t, len(errs), 1)
+	expect(t, errs[0].Error(), "Oops")
+	expect(t, len(*searched), 2)
+}
+
+func Test_SearchIter_Break(t *testing.T) {
+	freezeNow(t, time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC))
+	server, c, searched := searchServer(map[string][]string{"2024-03-05": {"b", "a"}})
+	defer server.Close()
+
+	for range c.SearchIter(context.Background(), SearchParams{Range: LastDays(3)}) {
+		break
+	}
+	expect(t, len(*searched), 1)
+}
+
+func Test_SearchIter_PageInterval(t *testing.T) {
+	freezeNow(t, time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC))
+	server, c, searched := searchServer(nil)
+	defer server.Close()
+	c.PageInterval = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var errs []error
+	for _, err := range c.SearchIter(ctx, SearchParams{Range: LastDays(3)}) {
+		errs = append(errs, err)
+	}
+	expect(t, len(*searched), 1)
+	expect(t, errs[0], context.DeadlineExceeded)
+}
+
+func Test_ExportRows_All(t *testing.T) {
+	server := exportServer(t, "Email\nbob@example.com\nkim@example.com\n")
+	defer server.Close()
+
+	rows, err := ClientWithKey("APIKEY").OpenExport(context.Background(), server.URL+"/exports/1.zip")
+	expect(t, err, nil)
+	defer rows.Close()
+
+	var emails []string
+	for row, err := range rows.All() {
+		expect(t, err, nil)
+		emails = append(emails, row[0])
+	}
+	expect(t, strings.Join(emails, ","), "bob@example.com,kim@example.com")
+}
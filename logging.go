@@ -0,0 +1,60 @@
+package mandrill
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Logger receives the client's Debug output. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+type stdLogger struct{}
+
+func (stdLogger) Printf(format string, v ...interface{}) {
+	log.Printf(format, v...)
+}
+
+// logRequest logs a finished API request. It's deferred by sendApiRequest,
+// so status and err are read once the request has completed.
+func (c *Client) logRequest(path string, data interface{}, start time.Time, status *int, err *error) {
+	logger := c.Logger
+	if logger == nil {
+		logger = stdLogger{}
+	}
+
+	line := fmt.Sprintf("mandrill: POST %s key=%s status=%d duration=%s", path, redactKey(c.Key), *status, time.Since(start))
+	if n, ok := recipientCount(data); ok {
+		line += fmt.Sprintf(" recipients=%d", n)
+	}
+	if *err != nil {
+		line += fmt.Sprintf(" error=%q", (*err).Error())
+	}
+	logger.Printf("%s", line)
+}
+
+// redactKey keeps the last four characters of the key so log lines can tell keys apart
+func redactKey(key string) string {
+	if len(key) <= 4 {
+		return strings.Repeat("*", len(key))
+	}
+	return strings.Repeat("*", len(key)-4) + key[len(key)-4:]
+}
+
+// recipientCount reports the number of recipients of a send payload
+func recipientCount(data interface{}) (int, bool) {
+	var message *Message
+	switch payload := data.(type) {
+	case *sendPayload:
+		message = payload.Message
+	case *sendTemplatePayload:
+		message = payload.Message
+	}
+	if message == nil {
+		return 0, false
+	}
+	return len(message.To), true
+}
@@ -0,0 +1,70 @@
+package mandrill
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type testLogger struct {
+	lines []string
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+// Debug //////////
+
+func Test_Debug_LogsSends(t *testing.T) {
+	server, c, _ := testRecorder(200, `[{"email":"bob@example.com","status":"sent"}]`)
+	defer server.Close()
+	logger := &testLogger{}
+	c.Key = "y2cQvBBfdFoZNByVaKsJsA"
+	c.Debug = true
+	c.Logger = logger
+
+	message := &Message{}
+	message.AddRecipient("bob@example.com", "Bob", "to")
+	message.AddRecipient("sam@example.com", "Sam", "cc")
+	_, err := c.MessagesSend(message)
+	expect(t, err, nil)
+
+	expect(t, len(logger.lines), 1)
+	line := logger.lines[0]
+	expect(t, strings.HasPrefix(line, "mandrill: POST messages/send.json key=******************sJsA status=200 duration="), true)
+	expect(t, strings.HasSuffix(line, " recipients=2"), true)
+	expect(t, strings.Contains(line, "y2cQvBBfdFoZNByVaKsJsA"), false)
+}
+
+func Test_Debug_LogsErrors(t *testing.T) {
+	server, c, _ := testRecorder(500, `{"status":"error","code":-1,"name":"Invalid_Key","message":"Invalid API key"}`)
+	defer server.Close()
+	logger := &testLogger{}
+	c.Debug = true
+	c.Logger = logger
+
+	_, err := c.Ping()
+	refute(t, err, nil)
+
+	expect(t, len(logger.lines), 1)
+	expect(t, strings.Contains(logger.lines[0], "users/ping.json key=**IKEY status=500"), true)
+	expect(t, strings.HasSuffix(logger.lines[0], ` error="Invalid API key"`), true)
+}
+
+func Test_Debug_Off(t *testing.T) {
+	server, c, _ := testRecorder(200, `"PONG!"`)
+	defer server.Close()
+	logger := &testLogger{}
+	c.Logger = logger
+
+	_, err := c.Ping()
+	expect(t, err, nil)
+	expect(t, len(logger.lines), 0)
+}
+
+func Test_redactKey(t *testing.T) {
+	expect(t, redactKey(""), "")
+	expect(t, redactKey("abc"), "***")
+	expect(t, redactKey("abcdef"), "**cdef")
+}
@@ -46,12 +46,31 @@ package mandrill
 
 import (
 	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/mail"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
 )
 
+// Version is the library version sent in the User-Agent header
+const Version = "1.0.0"
+
+// ErrURLNotAllowed is returned when a request URL is outside of the client's AllowedHosts or AllowedSchemes
+var ErrURLNotAllowed = errors.New("mandrill: url not allowed")
+
+// ErrInFlightTimeout is returned when a request waited longer than InFlightTimeout for a free slot
+var ErrInFlightTimeout = errors.New("mandrill: timed out waiting for an in-flight request slot")
+
 // Client manages requests to the Mandrill API
 type Client struct {
 	// mandrill API key
@@ -60,6 +79,123 @@ type Client struct {
 	BaseURL string
 	// Requests are transported through this client
 	HTTPClient *http.Client
+	// optional list of hosts the client may send requests to, e.g. "mandrillapp.com". Empty allows any host.
+	// Redirects are checked too.
+	AllowedHosts []string
+	// optional list of URL schemes the client may use, e.g. "https". Empty allows any scheme.
+	AllowedSchemes []string
+	// optional checks run against every message before it is sent
+	ContentCheckers []ContentChecker
+	// tags, metadata and Google Analytics settings merged into every message sent
+	Defaults MessageDefaults
+	// optional middleware run around every MessagesSend and MessagesSendTemplate call.
+	// BeforeSend runs in order, AfterSend in reverse order. Both get the copy of the message
+	// being sent, with Defaults applied; changes don't reach the caller's message.
+	Middleware []Middleware
+	// optional callback invoked with the outcome of every MessagesSend and MessagesSendTemplate call
+	OnResult func(message *Message, responses []*Response, err error)
+	// optional callback invoked for each response whose Status or RejectionReason isn't a documented value
+	OnUnknownStatus func(response *Response)
+	// when true, sends return an *UnknownStatusError (alongside the responses) if any response has an undocumented Status or RejectionReason
+	StrictStatuses bool
+	// number of concurrent sends used by SendIndividually. Defaults to DefaultFanOutConcurrency.
+	FanOutConcurrency int
+	// optional per-recipient-domain rate limits applied by SendIndividually
+	DomainThrottle *DomainThrottle
+	// when true, responses with mistyped values fail to decode instead of leaving those
+	// fields at their zero values. Unknown fields are always ignored.
+	StrictDecoding bool
+	// interval between polls made by WaitForExport and WaitForDelivery. Defaults to DefaultPollInterval.
+	PollInterval time.Duration
+	// optional pause between the successive searches made by SearchIter and Engagement
+	PageInterval time.Duration
+	// optional cap on concurrent API requests. Requests beyond the cap wait for a free slot.
+	// Set it before the client's first request: the cap is fixed then, and shared by copies of the client.
+	MaxInFlight int
+	// how long a request may wait for a free slot when MaxInFlight is set. Zero waits indefinitely.
+	InFlightTimeout time.Duration
+	// when true, every API request is logged with its path, status code, duration and recipient count
+	Debug bool
+	// receives the Debug output. Defaults to the standard library's log package.
+	Logger Logger
+	// number of times a throttled (HTTP 429) request is retried after waiting out its Retry-After.
+	// Zero returns a *ThrottledError immediately.
+	ThrottleRetries int
+	// optional cap on throttled retries across all calls, shared by every call made with the client
+	RetryBudget *RetryBudget
+	// optional time limit for each API request, applied on top of HTTPClient's own timeout.
+	// WithTimeout overrides it for a single call.
+	Timeout time.Duration
+	// optional app identifier appended to the User-Agent header, e.g. "billing-service/2.3"
+	UserAgent string
+	// optional store of recently sent Message.IdempotencyKey values, used to refuse duplicate sends
+	IdempotencyStore IdempotencyStore
+	// optional circuit breaker that fails requests fast with ErrCircuitOpen during Mandrill outages
+	CircuitBreaker *CircuitBreaker
+	// optional script for sends made with the SANDBOX_SUCCESS and SANDBOX_ERROR keys, replacing their
+	// fixed results, e.g. to simulate per-recipient rejections or API errors such as &Error{Name: "Unknown_Subaccount"}
+	SandboxResponder func(message *Message) ([]*Response, error)
+	// when true, sends made with the SANDBOX_SUCCESS and SANDBOX_ERROR keys fail with ErrSandboxKey.
+	// Client constructors set it from the package's RefuseSandboxKeys.
+	RefuseSandboxKeys bool
+
+	inFlight *inFlightLimiter
+}
+
+// MessageSender is implemented by *Client. Application code can depend on it
+// and substitute a fake in unit tests.
+type MessageSender interface {
+	Ping() (string, error)
+	PingContext(ctx context.Context) (string, error)
+	MessagesSend(message *Message) ([]*Response, error)
+	MessagesSendContext(ctx context.Context, message *Message) ([]*Response, error)
+	MessagesSendTemplate(message *Message, templateName string, contents interface{}) ([]*Response, error)
+	MessagesSendTemplateContext(ctx context.Context, message *Message, templateName string, contents interface{}) ([]*Response, error)
+}
+
+var _ MessageSender = (*Client)(nil)
+
+// ContentChecker inspects, and may modify, a message before it is sent.
+// Returning an error vetoes the send and is returned to the caller.
+type ContentChecker interface {
+	CheckContent(message *Message) error
+}
+
+// Middleware inspects and may modify messages before they are sent, and observes the results
+type Middleware interface {
+	// BeforeSend is called before the message is sent. Returning an error aborts the send.
+	BeforeSend(message *Message) error
+	// AfterSend is called with the outcome of the send, including aborted sends
+	AfterSend(message *Message, responses []*Response, err error)
+}
+
+// MiddlewareFuncs adapts a pair of functions to the Middleware interface. Either may be nil.
+type MiddlewareFuncs struct {
+	Before func(message *Message) error
+	After  func(message *Message, responses []*Response, err error)
+}
+
+// BeforeSend calls f.Before if set
+func (f MiddlewareFuncs) BeforeSend(message *Message) error {
+	if f.Before == nil {
+		return nil
+	}
+	return f.Before(message)
+}
+
+// AfterSend calls f.After if set
+func (f MiddlewareFuncs) AfterSend(message *Message, responses []*Response, err error) {
+	if f.After != nil {
+		f.After(message, responses, err)
+	}
+}
+
+// ContentCheckerFunc adapts a function to the ContentChecker interface
+type ContentCheckerFunc func(message *Message) error
+
+// CheckContent calls f(message)
+func (f ContentCheckerFunc) CheckContent(message *Message) error {
+	return f(message)
 }
 
 // Message represents the message payload sent to the API
@@ -80,22 +216,22 @@ type Message struct {
 	Headers map[string]string `json:"headers,omitempty"`
 	// whether or not this message is important, and should be delivered ahead of non-important messages
 	Important bool `json:"important,omitempty"`
-	// whether or not to turn on open tracking for the message
-	TrackOpens bool `json:"track_opens,omitempty"`
-	// whether or not to turn on click tracking for the message
-	TrackClicks bool `json:"track_clicks,omitempty"`
+	// whether or not to turn on open tracking for the message. nil uses the account default; see SetTrackOpens.
+	TrackOpens *bool `json:"track_opens,omitempty"`
+	// whether or not to turn on click tracking for the message. nil uses the account default; see SetTrackClicks.
+	TrackClicks *bool `json:"track_clicks,omitempty"`
 	// whether or not to automatically generate a text part for messages that are not given text
 	AutoText bool `json:"auto_text,omitempty"`
 	// whether or not to automatically generate an HTML part for messages that are not given HTML
 	AutoHTML bool `json:"auto_html,omitempty"`
-	// whether or not to automatically inline all CSS styles provided in the message HTML - only for HTML documents less than 256KB in size
-	InlineCSS bool `json:"inline_css,omitempty"`
+	// whether or not to automatically inline all CSS styles provided in the message HTML - only for HTML documents less than 256KB in size. nil uses the account default; see SetInlineCSS.
+	InlineCSS *bool `json:"inline_css,omitempty"`
 	// whether or not to strip the query string from URLs when aggregating tracked URL data
 	URLStripQS bool `json:"url_strip_qs,omitempty"`
-	// whether or not to expose all recipients in to "To" header for each email
-	PreserveRecipients bool `json:"preserve_recipients,omitempty"`
-	// set to false to remove content logging for sensitive emails
-	ViewContentLink bool `json:"view_content_link,omitempty"`
+	// whether or not to expose all recipients in to "To" header for each email. nil uses the account default; see SetPreserveRecipients.
+	PreserveRecipients *bool `json:"preserve_recipients,omitempty"`
+	// set to false to remove content logging for sensitive emails. nil uses the account default; see SetViewContentLink.
+	ViewContentLink *bool `json:"view_content_link,omitempty"`
 	// an optional address to receive an exact copy of each recipient's email
 	BCCAddress string `json:"bcc_address,omitempty"`
 	// a custom domain to use for tracking opens and clicks instead of mandrillapp.com
@@ -134,6 +270,9 @@ type Message struct {
 	IPPool string `json:"-"`
 	// when this message should be sent as a UTC timestamp in YYYY-MM-DD HH:MM:SS format. If you specify a time in the past, the message will be sent immediately. An additional fee applies for scheduled email, and this feature is only available to accounts with a positive balance.
 	SendAt string `json:"-"`
+	// optional key identifying this send, e.g. "receipt-" + orderID. When the client has an
+	// IdempotencyStore, a message whose key was already sent returns ErrDuplicateSend. Not sent to Mandrill.
+	IdempotencyKey string `json:"-"`
 }
 
 // To is a single recipient's information.
@@ -191,6 +330,42 @@ type Response struct {
 	Id string `json:"_id"`
 }
 
+var knownStatuses = map[string]bool{
+	"sent":      true,
+	"queued":    true,
+	"scheduled": true,
+	"rejected":  true,
+	"invalid":   true,
+}
+
+var knownRejectionReasons = map[string]bool{
+	"":                true,
+	"hard-bounce":     true,
+	"soft-bounce":     true,
+	"spam":            true,
+	"unsub":           true,
+	"custom":          true,
+	"invalid-sender":  true,
+	"invalid":         true,
+	"test-mode-limit": true,
+	"unsigned":        true,
+	"rule":            true,
+}
+
+func (res *Response) knownStatus() bool {
+	return knownStatuses[res.Status] && knownRejectionReasons[res.RejectionReason]
+}
+
+// UnknownStatusError is returned when StrictStatuses is set and a response carries an undocumented Status or RejectionReason
+type UnknownStatusError struct {
+	Response *Response
+}
+
+// Error describes the unexpected status
+func (err *UnknownStatusError) Error() string {
+	return fmt.Sprintf("mandrill: unknown status %q (reject_reason %q) for %s", err.Response.Status, err.Response.RejectionReason, err.Response.Email)
+}
+
 // Error reprents an error from the Mandrill API
 // * Invalid_Key -The provided API key is not a valid Mandrill API key\r
 // * PaymentRequired -The requested feature requires payment.\r
@@ -202,134 +377,611 @@ type Error struct {
 	Code    int    `json:"code"`
 	Name    string `json:"name"`
 	Message string `json:"message"`
+	// the HTTP status code of the response
+	StatusCode int `json:"-"`
+	// the raw response body, set when it couldn't be decoded as a Mandrill error, e.g. a proxy's HTML error page
+	Body []byte `json:"-"`
 }
 
-// Error returns err.Message
+// maxErrorBody caps how much of an undecodable body Error includes in its message
+const maxErrorBody = 200
+
+// Error returns err.Message, or describes the HTTP response when the body wasn't a Mandrill error
 func (err Error) Error() string {
-	return err.Message
+	if err.Message != "" || err.StatusCode == 0 {
+		return err.Message
+	}
+	body := strings.TrimSpace(string(err.Body))
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody] + "..."
+	}
+	if body == "" {
+		return fmt.Sprintf("mandrill: HTTP %d", err.StatusCode)
+	}
+	return fmt.Sprintf("mandrill: HTTP %d: %s", err.StatusCode, body)
 }
 
 // ClientWithKey returns a mandrill.Client pointer armed with the supplied Mandrill API key
 // For integration testing, you can supply `SANDBOX_SUCCESS` or `SANDBOX_ERROR` as the API key.
+// When RefuseSandboxKeys is set, sends made with those keys fail with ErrSandboxKey.
 func ClientWithKey(key string) *Client {
 	return &Client{
-		Key:        key,
-		HTTPClient: &http.Client{},
-		BaseURL:    "https://mandrillapp.com/api/1.0/",
+		Key:               key,
+		HTTPClient:        &http.Client{},
+		BaseURL:           "https://mandrillapp.com/api/1.0/",
+		RefuseSandboxKeys: RefuseSandboxKeys,
+	}
+}
+
+// RefuseSandboxKeys is the default for new clients' RefuseSandboxKeys: NewClient and ClientFromEnv reject
+// the `SANDBOX_SUCCESS` and `SANDBOX_ERROR` keys, and sends from a ClientWithKey client using them fail.
+// It defaults to true when the ENVIRONMENT, APP_ENV or GO_ENV environment variable is "production" or "prod",
+// and is read when a client is created.
+var RefuseSandboxKeys = isProductionEnv()
+
+// ErrSandboxKey is returned when a sandbox key is used while RefuseSandboxKeys is set
+var ErrSandboxKey = errors.New("mandrill: sandbox API key refused")
+
+// NewClient is like ClientWithKey, but returns ErrSandboxKey for sandbox keys when RefuseSandboxKeys is set
+func NewClient(key string) (*Client, error) {
+	if RefuseSandboxKeys && isSandboxKey(key) {
+		return nil, ErrSandboxKey
+	}
+	return ClientWithKey(key), nil
+}
+
+// ErrNoAPIKey is returned by ClientFromEnv when MANDRILL_API_KEY is empty
+var ErrNoAPIKey = errors.New("mandrill: MANDRILL_API_KEY is not set")
+
+// ClientFromEnv returns a client configured from the environment:
+//
+//	MANDRILL_API_KEY     the API key (required)
+//	MANDRILL_BASE_URL    overrides the API base, e.g. for a proxy
+//	MANDRILL_SUBACCOUNT  subaccount used by messages that don't set one
+//
+// Like NewClient, it returns ErrSandboxKey for sandbox keys when RefuseSandboxKeys is set.
+func ClientFromEnv() (*Client, error) {
+	key := strings.TrimSpace(os.Getenv("MANDRILL_API_KEY"))
+	if key == "" {
+		return nil, ErrNoAPIKey
+	}
+
+	c, err := NewClient(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if baseURL := strings.TrimSpace(os.Getenv("MANDRILL_BASE_URL")); baseURL != "" {
+		if !strings.HasSuffix(baseURL, "/") {
+			baseURL += "/"
+		}
+		c.BaseURL = baseURL
+	}
+	c.Defaults.Subaccount = strings.TrimSpace(os.Getenv("MANDRILL_SUBACCOUNT"))
+
+	return c, nil
+}
+
+func isSandboxKey(key string) bool {
+	return key == "SANDBOX_SUCCESS" || key == "SANDBOX_ERROR"
+}
+
+func isProductionEnv() bool {
+	for _, name := range []string{"ENVIRONMENT", "APP_ENV", "GO_ENV"} {
+		switch strings.ToLower(os.Getenv(name)) {
+		case "production", "prod":
+			return true
+		}
 	}
+	return false
 }
 
+// Ping validates the API key and returns "PONG!"
 func (c *Client) Ping() (pong string, err error) {
+	return c.PingContext(context.Background())
+}
+
+// PingContext is like Ping but with a context
+func (c *Client) PingContext(ctx context.Context) (pong string, err error) {
 	var data struct {
 		Key string `json:"key"`
 	}
 
 	data.Key = c.Key
 
-	body, err := c.sendApiRequest(data, "users/ping.json")
+	body, err := c.sendApiRequest(ctx, data, "users/ping.json")
 	if err != nil {
 		return pong, err
 	}
 
-	err = json.Unmarshal(body, &pong)
+	err = c.decode(body, &pong)
 	return pong, err
 }
 
-// MessagesSend sends a message via an API client
-func (c *Client) MessagesSend(message *Message) (responses []*Response, err error) {
+// Pong is the structured response of Ping2
+type Pong struct {
+	Ping string `json:"PING"`
+}
+
+// Ping2 validates the API key like Ping but decodes the structured users/ping2 response.
+// An invalid key returns an *Error named "Invalid_Key"; any other error is a transport
+// or decoding failure.
+func (c *Client) Ping2() (*Pong, error) {
+	return c.Ping2Context(context.Background())
+}
 
+// Ping2Context is like Ping2 but with a context
+func (c *Client) Ping2Context(ctx context.Context) (pong *Pong, err error) {
 	var data struct {
-		Key     string   `json:"key"`
-		Message *Message `json:"message,omitempty"`
-		// Remapped from Message.Async
-		Async bool `json:"async,omitempty"`
-		// Remapped from Message.IPPool
-		IPPool string `json:"ip_pool,omitempty"`
-		// Remapped from Message.SendAt
-		SendAt string `json:"send_at,omitempty"`
+		Key string `json:"key"`
 	}
 
 	data.Key = c.Key
-	data.Message = message
-	data.Async = message.Async
-	data.IPPool = message.IPPool
-	data.SendAt = message.SendAt
 
-	return c.sendMessagePayload(data, "messages/send.json")
+	err = c.call(ctx, "users/ping2.json", data, &pong)
+	return pong, err
+}
+
+// MessagesSend sends a message via an API client
+func (c *Client) MessagesSend(message *Message) (responses []*Response, err error) {
+	return c.MessagesSendContext(context.Background(), message)
+}
+
+// MessagesSendContext is like MessagesSend but with a context
+func (c *Client) MessagesSendContext(ctx context.Context, message *Message) (responses []*Response, err error) {
+
+	return c.sendMessage(ctx, message, "messages/send.json", func(message *Message) interface{} {
+		data := newSendPayload(message)
+		data.Key = c.Key
+		return data
+	})
 }
 
 // MessagesSendTemplate sends a message using a Mandrill template
+// contents may be a []*Variable, a map[string]string, a map[string]interface{} or nil.
 func (c *Client) MessagesSendTemplate(message *Message, templateName string, contents interface{}) (responses []*Response, err error) {
+	return c.MessagesSendTemplateContext(context.Background(), message, templateName, contents)
+}
 
-	var data struct {
-		Key             string      `json:"key"`
-		TemplateName    string      `json:"template_name,omitempty"`
-		TemplateContent []*Variable `json:"template_content"`
-		Message         *Message    `json:"message,omitempty"`
-		// Remapped from Message.Async
-		Async bool `json:"async,omitempty"`
-		// Remapped from Message.IPPool
-		IPPool string `json:"ip_pool,omitempty"`
-		// Remapped from Message.SendAt
-		SendAt string `json:"send_at,omitempty"`
+// MessagesSendTemplateContext is like MessagesSendTemplate but with a context
+func (c *Client) MessagesSendTemplateContext(ctx context.Context, message *Message, templateName string, contents interface{}) (responses []*Response, err error) {
+
+	data, err := newSendTemplatePayload(message, templateName, contents)
+	if err != nil {
+		return responses, err
 	}
 
-	data.Key = c.Key
-	data.TemplateName = templateName
-	data.TemplateContent = ConvertMapToVariables(contents)
+	return c.sendMessage(ctx, message, "messages/send-template.json", func(message *Message) interface{} {
+		data.Key = c.Key
+		data.remap(message)
+		return data
+	})
+}
+
+// BuildSendPayload returns the messages/send JSON payload for the message without
+// the API key, e.g. for queueing the message and sending it later with Call.
+// Addresses are normalized as in MessagesSend, but middleware, content checkers and
+// defaults are not applied.
+func (c *Client) BuildSendPayload(message *Message) ([]byte, error) {
+	message, err := message.normalizedCopy()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(newSendPayload(message))
+}
+
+// BuildSendTemplatePayload returns the messages/send-template JSON payload for
+// the message without the API key. See BuildSendPayload.
+func (c *Client) BuildSendTemplatePayload(message *Message, templateName string, contents interface{}) ([]byte, error) {
+	message, err := message.normalizedCopy()
+	if err != nil {
+		return nil, err
+	}
+	data, err := newSendTemplatePayload(message, templateName, contents)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(data)
+}
+
+// Call sends a JSON object payload, such as one from BuildSendPayload, to an API
+// path like "messages/send.json" with the client's key added, and returns the raw
+// response body
+func (c *Client) Call(path string, payload []byte) (body []byte, err error) {
+	return c.CallContext(context.Background(), path, payload)
+}
+
+// CallContext is like Call but with a context
+func (c *Client) CallContext(ctx context.Context, path string, payload []byte) (body []byte, err error) {
+	var data map[string]json.RawMessage
+	if err = json.Unmarshal(payload, &data); err != nil {
+		return body, err
+	}
+	if data == nil {
+		return body, errors.New("mandrill: payload must be a JSON object")
+	}
+
+	data["key"], _ = json.Marshal(c.Key)
+
+	return c.sendApiRequest(ctx, data, path)
+}
+
+type sendPayload struct {
+	Key     string   `json:"key,omitempty"`
+	Message *Message `json:"message,omitempty"`
+	// Remapped from Message.Async
+	Async bool `json:"async,omitempty"`
+	// Remapped from Message.IPPool
+	IPPool string `json:"ip_pool,omitempty"`
+	// Remapped from Message.SendAt
+	SendAt string `json:"send_at,omitempty"`
+}
+
+func newSendPayload(message *Message) *sendPayload {
+	data := &sendPayload{}
+	data.remap(message)
+	return data
+}
+
+func (data *sendPayload) remap(message *Message) {
 	data.Message = message
 	data.Async = message.Async
 	data.IPPool = message.IPPool
 	data.SendAt = message.SendAt
+}
 
-	return c.sendMessagePayload(data, "messages/send-template.json")
+type sendTemplatePayload struct {
+	TemplateName    string      `json:"template_name,omitempty"`
+	TemplateContent []*Variable `json:"template_content"`
+	sendPayload
 }
 
-func (c *Client) sendMessagePayload(data interface{}, path string) (responses []*Response, err error) {
+func newSendTemplatePayload(message *Message, templateName string, contents interface{}) (*sendTemplatePayload, error) {
+	templateContent, err := ConvertTemplateContent(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &sendTemplatePayload{TemplateName: templateName, TemplateContent: templateContent}
+	data.remap(message)
+	return data, nil
+}
+
+// sendMessage applies the client's defaults to a copy of the message, runs the copy through
+// the middleware and content checkers, then sends the payload the payload func builds from it.
+// The caller's message is never modified, so it can be sent again or from several goroutines.
+func (c *Client) sendMessage(ctx context.Context, original *Message, path string, payload func(*Message) interface{}) (responses []*Response, err error) {
+
+	if c.OnResult != nil {
+		defer func() { c.OnResult(original, responses, err) }()
+	}
+
+	message := original.clone()
+	c.Defaults.apply(message)
+
+	ran := 0
+	defer func() {
+		for i := ran - 1; i >= 0; i-- {
+			c.Middleware[i].AfterSend(message, responses, err)
+		}
+	}()
+
+	for _, mw := range c.Middleware {
+		ran++
+		if err = mw.BeforeSend(message); err != nil {
+			return responses, err
+		}
+	}
+
+	for _, checker := range c.ContentCheckers {
+		if err = checker.CheckContent(message); err != nil {
+			return responses, err
+		}
+	}
+
+	if err = message.normalizeAddresses(); err != nil {
+		return responses, err
+	}
+
+	if c.IdempotencyStore != nil && message.IdempotencyKey != "" {
+		return c.sendIdempotent(ctx, message, payload(message), path)
+	}
+
+	return c.sendMessagePayload(ctx, message, payload(message), path)
+}
+
+// sendMessagePayload sends the payload of a message send. message is only used by the SandboxResponder.
+func (c *Client) sendMessagePayload(ctx context.Context, message *Message, data interface{}, path string) (responses []*Response, err error) {
+	responses, _, err = c.deliverMessagePayload(ctx, message, data, path)
+	return responses, err
+}
+
+// deliverMessagePayload is sendMessagePayload, also reporting whether a failed send was certainly
+// refused, i.e. a retry can't send the message twice. See sendRefused.
+func (c *Client) deliverMessagePayload(ctx context.Context, message *Message, data interface{}, path string) (responses []*Response, refused bool, err error) {
+
+	if c.RefuseSandboxKeys && isSandboxKey(c.Key) {
+		return nil, true, ErrSandboxKey
+	}
+
+	if c.SandboxResponder != nil && isSandboxKey(c.Key) {
+		responses, err = c.SandboxResponder(message)
+		if err != nil {
+			return responses, true, err
+		}
+		if responses == nil {
+			responses = make([]*Response, 0)
+		}
+		return responses, false, c.checkStatuses(responses)
+	}
 
 	if c.Key == "SANDBOX_SUCCESS" {
-		return []*Response{}, nil
+		return sandboxResponses(message), false, nil
 	}
 
 	if c.Key == "SANDBOX_ERROR" {
-		return nil, errors.New("SANDBOX_ERROR")
+		return nil, true, errors.New("SANDBOX_ERROR")
 	}
 
-	body, err := c.sendApiRequest(data, path)
+	written := &requestWritten{}
+	body, err := c.sendApiRequest(written.trace(ctx), data, path)
 	if err != nil {
-		return responses, err
+		return responses, sendRefused(err, written.Load()), err
 	}
 	responses = make([]*Response, 0)
-	err = json.Unmarshal(body, &responses)
-	return responses, err
+	if err = c.decode(body, &responses); err != nil {
+		return responses, false, err
+	}
+	if responses == nil {
+		responses = make([]*Response, 0)
+	}
+	return responses, false, c.checkStatuses(responses)
 }
 
-func (c *Client) sendApiRequest(data interface{}, path string) (body []byte, err error) {
-	payload, _ := json.Marshal(data)
+// sandboxResponses returns a "sent" response with a random id for each recipient
+func sandboxResponses(message *Message) []*Response {
+	responses := make([]*Response, 0)
+	if message == nil {
+		return responses
+	}
+	for _, to := range message.To {
+		id := make([]byte, 16)
+		rand.Read(id)
+		responses = append(responses, &Response{Email: to.Email, Status: "sent", Id: hex.EncodeToString(id)})
+	}
+	return responses
+}
 
-	resp, err := c.HTTPClient.Post(c.BaseURL+path, "application/json", bytes.NewReader(payload))
+// call sends data to an API path and decodes the response into v
+func (c *Client) call(ctx context.Context, path string, data interface{}, v interface{}) error {
+	body, err := c.sendApiRequest(ctx, data, path)
 	if err != nil {
+		return err
+	}
+	return c.decode(body, v)
+}
+
+func (c *Client) checkStatuses(responses []*Response) error {
+	for _, res := range responses {
+		if res.knownStatus() {
+			continue
+		}
+		if c.OnUnknownStatus != nil {
+			c.OnUnknownStatus(res)
+		}
+		if c.StrictStatuses {
+			return &UnknownStatusError{Response: res}
+		}
+	}
+	return nil
+}
+
+func (c *Client) sendApiRequest(ctx context.Context, data interface{}, path string) (body []byte, err error) {
+	payload, _ := json.Marshal(data)
+
+	status := 0
+	if c.Debug {
+		defer c.logRequest(path, data, time.Now(), &status, &err)
+	}
+
+	attempts := 0
+	if info, ok := ctx.Value(callInfoKey{}).(*CallInfo); ok {
+		defer info.record(path, time.Now(), &attempts, &status, &body)
+	}
+
+	if err = c.CheckURL(c.BaseURL + path); err != nil {
 		return body, err
 	}
 
+	for attempt := 0; ; attempt++ {
+		if c.CircuitBreaker != nil && !c.CircuitBreaker.allow() {
+			return body, ErrCircuitOpen
+		}
+		body, status, err = c.doRequest(ctx, path, payload)
+		attempts++
+		if c.CircuitBreaker != nil {
+			c.CircuitBreaker.record(ctx, err)
+		}
+		throttled, ok := err.(*ThrottledError)
+		if !ok || attempt >= c.ThrottleRetries {
+			return body, err
+		}
+		if c.RetryBudget != nil && !c.RetryBudget.take() {
+			return body, &RetryBudgetError{Throttled: throttled}
+		}
+		if err = throttled.wait(ctx); err != nil {
+			return body, err
+		}
+	}
+}
+
+// doRequest makes a single POST to the API path
+func (c *Client) doRequest(ctx context.Context, path string, payload []byte) (body []byte, status int, err error) {
+	if timeout := c.requestTimeout(ctx); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	release, err := c.acquireInFlight(ctx)
+	if err != nil {
+		return body, status, err
+	}
+	defer release()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return body, status, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", c.userAgent())
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return body, status, err
+	}
+
 	defer resp.Body.Close()
+	status = resp.StatusCode
 	body, err = ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return body, err
+		return body, status, err
 	}
 
 	if resp.StatusCode >= 400 {
-		resError := &Error{}
-		err = json.Unmarshal(body, resError)
-		return body, resError
+		resError := &Error{StatusCode: resp.StatusCode}
+		if json.Unmarshal(body, resError) != nil || resError.Name == "" {
+			resError.Body = body
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return body, status, &ThrottledError{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")), Err: resError}
+		}
+		return body, status, resError
+	}
+
+	return body, status, err
+}
+
+// httpClient returns HTTPClient, with redirects checked against AllowedHosts and AllowedSchemes when either is set,
+// so a redirect can't resend the payload and its API key elsewhere
+func (c *Client) httpClient() *http.Client {
+	if len(c.AllowedHosts) == 0 && len(c.AllowedSchemes) == 0 {
+		return c.HTTPClient
+	}
+
+	client := *c.HTTPClient
+	next := client.CheckRedirect
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := c.CheckURL(req.URL.String()); err != nil {
+			return err
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	return &client
+}
+
+// userAgent identifies the library, followed by the client's UserAgent if set
+func (c *Client) userAgent() string {
+	ua := "keighl-mandrill/" + Version + " (+https://github.com/keighl/mandrill)"
+	if c.UserAgent != "" {
+		ua += " " + c.UserAgent
+	}
+	return ua
+}
+
+// inFlightLimiter holds a client's in-flight request slots. It lives behind a pointer so
+// Client values can still be copied, with the copies sharing the slots.
+type inFlightLimiter struct {
+	slots chan struct{}
+}
+
+// inFlightMu guards creating clients' limiters on their first request
+var inFlightMu sync.Mutex
+
+// inFlightSlots returns the client's slots, sized from MaxInFlight on first use
+func (c *Client) inFlightSlots() chan struct{} {
+	inFlightMu.Lock()
+	defer inFlightMu.Unlock()
+
+	if c.inFlight == nil {
+		c.inFlight = &inFlightLimiter{slots: make(chan struct{}, c.MaxInFlight)}
+	}
+	return c.inFlight.slots
+}
+
+func (c *Client) acquireInFlight(ctx context.Context) (release func(), err error) {
+	if c.MaxInFlight <= 0 {
+		return func() {}, nil
 	}
 
-	return body, err
+	slots := c.inFlightSlots()
+	release = func() { <-slots }
+
+	var timeout <-chan time.Time
+	if c.InFlightTimeout > 0 {
+		timer := time.NewTimer(c.InFlightTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return release, nil
+	case <-timeout:
+		return nil, ErrInFlightTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// CheckURL returns ErrURLNotAllowed if rawurl's host or scheme is not permitted by
+// the client's AllowedHosts and AllowedSchemes. Anything that fetches a URL on
+// behalf of the client should call it first.
+func (c *Client) CheckURL(rawurl string) error {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return err
+	}
+
+	if len(c.AllowedSchemes) > 0 && !containsFold(c.AllowedSchemes, u.Scheme) {
+		return fmt.Errorf("%w: scheme %q", ErrURLNotAllowed, u.Scheme)
+	}
+
+	if len(c.AllowedHosts) > 0 && !containsFold(c.AllowedHosts, u.Hostname()) {
+		return fmt.Errorf("%w: host %q", ErrURLNotAllowed, u.Hostname())
+	}
+
+	return nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
 }
 
 // AddRecipient appends a recipient to the message
 // easier than message.To = []*To{&To{email, name}}
+// email may also be a combined address such as "Bob Johnson <bob@example.com>",
+// in which case the parsed name is used unless name is given.
 func (m *Message) AddRecipient(email string, name string, sendType string) {
+	if strings.ContainsRune(email, '<') {
+		if address, err := mail.ParseAddress(email); err == nil {
+			email = address.Address
+			if name == "" {
+				name = address.Name
+			}
+		}
+	}
 	to := &To{email, name, sendType}
 	m.To = append(m.To, to)
 }
@@ -358,6 +1010,24 @@ func ConvertMapToVariables(i interface{}) []*Variable {
 	return variables
 }
 
+// ConvertTemplateContent converts template content given as a []*Variable,
+// map[string]string, map[string]interface{} or nil into a []*Variable, and
+// returns an error for any other type
+func ConvertTemplateContent(contents interface{}) ([]*Variable, error) {
+	switch contents := contents.(type) {
+	case nil:
+		return []*Variable{}, nil
+	case []*Variable:
+		if contents == nil {
+			return []*Variable{}, nil
+		}
+		return contents, nil
+	case map[string]string, map[string]interface{}:
+		return ConvertMapToVariables(contents), nil
+	}
+	return nil, fmt.Errorf("mandrill: unsupported template content type %T", contents)
+}
+
 // MapToVars converts a regular string/string map into the Variable struct
 // Alias of `ConvertMapToVariables`
 func MapToVars(m interface{}) []*Variable {
@@ -1,12 +1,19 @@
 package mandrill
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func expect(t *testing.T, a interface{}, b interface{}) {
@@ -36,10 +43,33 @@ func testTools(code int, body string) (*httptest.Server, *Client) {
 	}
 	httpClient := &http.Client{Transport: tr}
 
-	client := &Client{"APIKEY", server.URL + "/", httpClient}
+	client := &Client{Key: "APIKEY", BaseURL: server.URL + "/", HTTPClient: httpClient}
 	return server, client
 }
 
+type testRequest struct {
+	Path    string
+	Header  http.Header
+	Payload map[string]interface{}
+}
+
+// testRecorder is like testTools, but also records the path, headers and JSON payload of the last request
+func testRecorder(code int, body string) (*httptest.Server, *Client, *testRequest) {
+	req := &testRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req.Path = r.URL.Path
+		req.Header = r.Header
+		req.Payload = map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&req.Payload)
+		w.WriteHeader(code)
+		fmt.Fprintln(w, body)
+	}))
+
+	client := ClientWithKey("APIKEY")
+	client.BaseURL = server.URL + "/"
+	return server, client, req
+}
+
 // ClientWithKey //////
 
 func Test_ClientWithKey(t *testing.T) {
@@ -47,6 +77,91 @@ func Test_ClientWithKey(t *testing.T) {
 	refute(t, c, nil)
 }
 
+// NewClient //////////
+
+func Test_NewClient(t *testing.T) {
+	defer func(refuse bool) { RefuseSandboxKeys = refuse }(RefuseSandboxKeys)
+
+	RefuseSandboxKeys = false
+	c, err := NewClient("SANDBOX_SUCCESS")
+	expect(t, err, nil)
+	expect(t, c.Key, "SANDBOX_SUCCESS")
+
+	RefuseSandboxKeys = true
+	c, err = NewClient("SANDBOX_ERROR")
+	expect(t, err, ErrSandboxKey)
+	expect(t, c, (*Client)(nil))
+
+	c, err = NewClient("APIKEY")
+	expect(t, err, nil)
+	expect(t, c.Key, "APIKEY")
+}
+
+func Test_ClientWithKey_RefuseSandboxKeys(t *testing.T) {
+	defer func(refuse bool) { RefuseSandboxKeys = refuse }(RefuseSandboxKeys)
+
+	RefuseSandboxKeys = true
+	c := ClientWithKey("SANDBOX_SUCCESS")
+	expect(t, c.RefuseSandboxKeys, true)
+	responses, err := c.MessagesSend(&Message{})
+	expect(t, err, ErrSandboxKey)
+	expect(t, len(responses), 0)
+
+	c.RefuseSandboxKeys = false
+	_, err = c.MessagesSend(&Message{})
+	expect(t, err, nil)
+
+	RefuseSandboxKeys = false
+	expect(t, ClientWithKey("SANDBOX_SUCCESS").RefuseSandboxKeys, false)
+}
+
+func Test_ClientFromEnv(t *testing.T) {
+	t.Setenv("MANDRILL_API_KEY", "APIKEY")
+	t.Setenv("MANDRILL_BASE_URL", "https://proxy.example.com/api/1.0")
+	t.Setenv("MANDRILL_SUBACCOUNT", "customer-123")
+
+	c, err := ClientFromEnv()
+	expect(t, err, nil)
+	expect(t, c.Key, "APIKEY")
+	expect(t, c.BaseURL, "https://proxy.example.com/api/1.0/")
+	expect(t, c.Defaults.Subaccount, "customer-123")
+}
+
+func Test_ClientFromEnv_Defaults(t *testing.T) {
+	t.Setenv("MANDRILL_API_KEY", "APIKEY")
+	t.Setenv("MANDRILL_BASE_URL", "")
+	t.Setenv("MANDRILL_SUBACCOUNT", "")
+
+	c, err := ClientFromEnv()
+	expect(t, err, nil)
+	expect(t, c.BaseURL, "https://mandrillapp.com/api/1.0/")
+	expect(t, c.Defaults.Subaccount, "")
+}
+
+func Test_ClientFromEnv_Errors(t *testing.T) {
+	defer func(refuse bool) { RefuseSandboxKeys = refuse }(RefuseSandboxKeys)
+
+	t.Setenv("MANDRILL_API_KEY", "")
+	c, err := ClientFromEnv()
+	expect(t, err, ErrNoAPIKey)
+	expect(t, c, (*Client)(nil))
+
+	RefuseSandboxKeys = true
+	t.Setenv("MANDRILL_API_KEY", "SANDBOX_SUCCESS")
+	_, err = ClientFromEnv()
+	expect(t, err, ErrSandboxKey)
+}
+
+func Test_isProductionEnv(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "")
+	t.Setenv("APP_ENV", "")
+	t.Setenv("GO_ENV", "")
+	expect(t, isProductionEnv(), false)
+
+	t.Setenv("APP_ENV", "Production")
+	expect(t, isProductionEnv(), true)
+}
+
 // MessagesSendTemplate //////////
 
 func Test_MessagesSendTemplate_Success(t *testing.T) {
@@ -74,14 +189,40 @@ func Test_MessagesSendTemplate_Fail(t *testing.T) {
 	expect(t, len(responses), 0)
 
 	correctResponse := &Error{
-		Status:  "error",
-		Code:    12,
-		Name:    "Unknown_Subaccount",
-		Message: "No subaccount exists with the id 'customer-123'",
+		Status:     "error",
+		Code:       12,
+		Name:       "Unknown_Subaccount",
+		Message:    "No subaccount exists with the id 'customer-123'",
+		StatusCode: 400,
 	}
 	expect(t, reflect.DeepEqual(correctResponse, err), true)
 }
 
+func Test_MessagesSendTemplate_Variables(t *testing.T) {
+	var payload struct {
+		TemplateContent []*Variable `json:"template_content"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		fmt.Fprintln(w, `[]`)
+	}))
+	defer server.Close()
+
+	m := ClientWithKey("APIKEY")
+	m.BaseURL = server.URL + "/"
+	vars := []*Variable{{"main", "one"}, {"main", "two"}}
+	_, err := m.MessagesSendTemplate(&Message{}, "cheese", vars)
+
+	expect(t, err, nil)
+	expect(t, reflect.DeepEqual(payload.TemplateContent, vars), true)
+}
+
+func Test_MessagesSendTemplate_BadContent(t *testing.T) {
+	client := ClientWithKey("SANDBOX_SUCCESS")
+	_, err := client.MessagesSendTemplate(&Message{}, "cheese", "CHEESE")
+	expect(t, err.Error(), "mandrill: unsupported template content type string")
+}
+
 // MessagesSend //////////
 
 func Test_MessageSend_Success(t *testing.T) {
@@ -109,14 +250,414 @@ func Test_MessageSend_Fail(t *testing.T) {
 	expect(t, len(responses), 0)
 
 	correctResponse := &Error{
-		Status:  "error",
-		Code:    12,
-		Name:    "Unknown_Subaccount",
-		Message: "No subaccount exists with the id 'customer-123'",
+		Status:     "error",
+		Code:       12,
+		Name:       "Unknown_Subaccount",
+		Message:    "No subaccount exists with the id 'customer-123'",
+		StatusCode: 400,
 	}
 	expect(t, reflect.DeepEqual(correctResponse, err), true)
 }
 
+// Defaults //////////
+
+func Test_Defaults(t *testing.T) {
+	server, client, req := testRecorder(200, `[]`)
+	defer server.Close()
+	client.Defaults = MessageDefaults{
+		Tags:                    []string{"checkout", "receipts"},
+		Metadata:                map[string]string{"env": "production", "service": "checkout"},
+		GoogleAnalyticsDomains:  []string{"example.com"},
+		GoogleAnalyticsCampaign: "default",
+		Subaccount:              "customer-123",
+	}
+
+	message := &Message{Tags: []string{"receipts"}, Metadata: map[string]string{"env": "staging"}}
+	_, err := client.MessagesSend(message)
+	expect(t, err, nil)
+
+	sent := req.Payload["message"].(map[string]interface{})
+	expect(t, reflect.DeepEqual(sent["tags"], []interface{}{"receipts", "checkout"}), true)
+	expect(t, reflect.DeepEqual(sent["metadata"], map[string]interface{}{"env": "staging", "service": "checkout"}), true)
+	expect(t, reflect.DeepEqual(sent["google_analytics_domains"], []interface{}{"example.com"}), true)
+	expect(t, sent["google_analytics_campaign"], "default")
+	expect(t, sent["subaccount"], "customer-123")
+
+	message = &Message{Subaccount: "customer-456"}
+	_, err = client.MessagesSend(message)
+	expect(t, err, nil)
+	sent = req.Payload["message"].(map[string]interface{})
+	expect(t, sent["subaccount"], "customer-456")
+}
+
+func Test_Defaults_LeaveMessageUnchanged(t *testing.T) {
+	server, client, req := testRecorder(200, `[]`)
+	defer server.Close()
+	client.Defaults = MessageDefaults{Tags: []string{"checkout"}, Metadata: map[string]string{"env": "production"}}
+
+	message := &Message{Tags: []string{"receipts"}, Metadata: map[string]string{"order": "42"}}
+	message.AddRecipient("bob@bücher.example", "", "to")
+	for i := 0; i < 2; i++ {
+		_, err := client.MessagesSend(message)
+		expect(t, err, nil)
+	}
+
+	sent := req.Payload["message"].(map[string]interface{})
+	expect(t, reflect.DeepEqual(sent["tags"], []interface{}{"receipts", "checkout"}), true)
+	expect(t, reflect.DeepEqual(message.Tags, []string{"receipts"}), true)
+	expect(t, reflect.DeepEqual(message.Metadata, map[string]string{"order": "42"}), true)
+	expect(t, message.To[0].Email, "bob@bücher.example")
+}
+
+func Test_Defaults_ConcurrentSends(t *testing.T) {
+	client := ClientWithKey("SANDBOX_SUCCESS")
+	client.Defaults = MessageDefaults{Tags: []string{"checkout"}, Metadata: map[string]string{"env": "production"}}
+	client.Middleware = []Middleware{MiddlewareFuncs{Before: func(message *Message) error {
+		message.Metadata["sent_by"] = "worker"
+		return nil
+	}}}
+
+	message := &Message{Metadata: map[string]string{"order": "42"}}
+	message.AddRecipient("bob@example.com", "", "to")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.MessagesSend(message)
+		}()
+	}
+	wg.Wait()
+	expect(t, len(message.Tags), 0)
+	expect(t, len(message.Metadata), 1)
+}
+
+// Middleware //////////
+
+func Test_Middleware(t *testing.T) {
+	server, m, req := testRecorder(200, `[{"email":"bob@example.com","status":"sent","reject_reason":"","_id":"1"}]`)
+	defer server.Close()
+
+	calls := []string{}
+	m.Middleware = []Middleware{
+		MiddlewareFuncs{
+			Before: func(message *Message) error {
+				calls = append(calls, "before env")
+				message.Metadata = map[string]string{"env": "test"}
+				return nil
+			},
+			After: func(message *Message, responses []*Response, err error) {
+				calls = append(calls, "after env")
+			},
+		},
+		MiddlewareFuncs{
+			Before: func(message *Message) error {
+				calls = append(calls, "before footer")
+				message.HTML += "<footer/>"
+				return nil
+			},
+			After: func(message *Message, responses []*Response, err error) {
+				calls = append(calls, fmt.Sprintf("after footer %d %v", len(responses), err))
+			},
+		},
+	}
+
+	message := &Message{HTML: "<p>Hi</p>"}
+	_, err := m.MessagesSend(message)
+	expect(t, err, nil)
+	sent := req.Payload["message"].(map[string]interface{})
+	expect(t, sent["html"], "<p>Hi</p><footer/>")
+	expect(t, reflect.DeepEqual(sent["metadata"], map[string]interface{}{"env": "test"}), true)
+	expect(t, message.HTML, "<p>Hi</p>")
+	expect(t, len(message.Metadata), 0)
+	expect(t, reflect.DeepEqual(calls, []string{"before env", "before footer", "after footer 1 <nil>", "after env"}), true)
+}
+
+func Test_Middleware_Abort(t *testing.T) {
+	server, m := testTools(200, `[]`)
+	defer server.Close()
+
+	abort := errors.New("abort")
+	afters := 0
+	m.Middleware = []Middleware{
+		MiddlewareFuncs{After: func(*Message, []*Response, error) { afters++ }},
+		MiddlewareFuncs{Before: func(*Message) error { return abort }},
+		MiddlewareFuncs{Before: func(*Message) error { t.Error("should not run"); return nil }},
+	}
+
+	_, err := m.MessagesSendTemplate(&Message{}, "cheese", nil)
+	expect(t, err, abort)
+	expect(t, afters, 1)
+}
+
+// OnResult //////////
+
+func Test_OnResult(t *testing.T) {
+	server, m := testTools(200, `[{"email":"bob@example.com","status":"sent","reject_reason":"","_id":"1"}]`)
+	defer server.Close()
+
+	var gotMessage *Message
+	var gotResponses []*Response
+	var gotErr error
+	calls := 0
+	m.OnResult = func(message *Message, responses []*Response, err error) {
+		calls++
+		gotMessage, gotResponses, gotErr = message, responses, err
+	}
+
+	message := &Message{}
+	responses, _ := m.MessagesSend(message)
+	expect(t, calls, 1)
+	expect(t, gotMessage, message)
+	expect(t, reflect.DeepEqual(gotResponses, responses), true)
+	expect(t, gotErr, nil)
+
+	veto := errors.New("veto")
+	m.ContentCheckers = []ContentChecker{ContentCheckerFunc(func(*Message) error { return veto })}
+	m.MessagesSendTemplate(message, "cheese", nil)
+	expect(t, calls, 2)
+	expect(t, gotErr, veto)
+}
+
+// MaxInFlight //////////
+
+func Test_MaxInFlight(t *testing.T) {
+	server, c := testTools(200, `"PONG!"`)
+	defer server.Close()
+	c.MaxInFlight = 1
+	c.InFlightTimeout = 20 * time.Millisecond
+
+	release, err := c.acquireInFlight(context.Background())
+	expect(t, err, nil)
+
+	_, err = c.Ping()
+	expect(t, err, ErrInFlightTimeout)
+
+	release()
+	_, err = c.Ping()
+	expect(t, err, nil)
+	expect(t, len(c.inFlight.slots), 0)
+}
+
+func Test_MaxInFlight_CopiedClient(t *testing.T) {
+	server, c := testTools(200, `"PONG!"`)
+	defer server.Close()
+	c.MaxInFlight = 1
+	c.InFlightTimeout = 20 * time.Millisecond
+
+	release, err := c.acquireInFlight(context.Background())
+	expect(t, err, nil)
+	defer release()
+
+	copied := *c
+	_, err = copied.Ping()
+	expect(t, err, ErrInFlightTimeout)
+}
+
+// BuildSendPayload / Call //////////
+
+func Test_BuildSendPayload(t *testing.T) {
+	c := ClientWithKey("APIKEY")
+	message := &Message{Subject: "Hi", IPPool: "Main Pool", SendAt: "2024-01-01 10:00:00"}
+	message.AddRecipient("bob@example.com", "", "to")
+
+	payload, err := c.BuildSendPayload(message)
+	expect(t, err, nil)
+	expect(t, string(payload), `{"message":{"subject":"Hi","to":[{"email":"bob@example.com","type":"to"}]},"ip_pool":"Main Pool","send_at":"2024-01-01 10:00:00"}`)
+
+	payload, err = c.BuildSendTemplatePayload(message, "welcome", []*Variable{{"header", "Hi"}})
+	expect(t, err, nil)
+	expect(t, string(payload), `{"template_name":"welcome","template_content":[{"name":"header","content":"Hi"}],"message":{"subject":"Hi","to":[{"email":"bob@example.com","type":"to"}]},"ip_pool":"Main Pool","send_at":"2024-01-01 10:00:00"}`)
+
+	_, err = c.BuildSendTemplatePayload(message, "welcome", 42)
+	refute(t, err, nil)
+}
+
+func Test_Call(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expect(t, r.URL.Path, "/messages/send.json")
+		json.NewDecoder(r.Body).Decode(&received)
+		fmt.Fprintln(w, `[{"email":"bob@example.com","status":"queued","_id":"1"}]`)
+	}))
+	defer server.Close()
+
+	c := ClientWithKey("APIKEY")
+	c.BaseURL = server.URL + "/"
+
+	message := &Message{}
+	message.AddRecipient("bob@example.com", "", "to")
+	payload, _ := c.BuildSendPayload(message)
+
+	body, err := c.Call("messages/send.json", payload)
+	expect(t, err, nil)
+	expect(t, received["key"], "APIKEY")
+	refute(t, received["message"], nil)
+
+	responses := []*Response{}
+	json.Unmarshal(body, &responses)
+	expect(t, responses[0].Status, "queued")
+
+	_, err = c.Call("messages/send.json", []byte("nope"))
+	refute(t, err, nil)
+
+	for _, payload := range []string{`null`, `[]`, `"x"`} {
+		_, err = c.Call("messages/send.json", []byte(payload))
+		refute(t, err, nil)
+	}
+}
+
+// ContentCheckers //////////
+
+func Test_ContentCheckers(t *testing.T) {
+	server, m, req := testRecorder(200, `[{"email":"bob@example.com","status":"sent","reject_reason":"","_id":"1"}]`)
+	defer server.Close()
+
+	m.ContentCheckers = []ContentChecker{
+		ContentCheckerFunc(func(message *Message) error {
+			message.Subject = "[staging] " + message.Subject
+			return nil
+		}),
+	}
+	message := &Message{Subject: "Hi"}
+	_, err := m.MessagesSend(message)
+	expect(t, err, nil)
+	expect(t, req.Payload["message"].(map[string]interface{})["subject"], "[staging] Hi")
+	expect(t, message.Subject, "Hi")
+
+	veto := errors.New("spammy")
+	m.ContentCheckers = append(m.ContentCheckers, ContentCheckerFunc(func(message *Message) error {
+		return veto
+	}))
+	responses, err := m.MessagesSendTemplate(message, "cheese", nil)
+	expect(t, len(responses), 0)
+	expect(t, err, veto)
+}
+
+// Unknown statuses //////////
+
+func Test_OnUnknownStatus(t *testing.T) {
+	server, m := testTools(200, `[{"email":"bob@example.com","status":"sent","reject_reason":null,"_id":"1"},{"email":"jill@example.com","status":"deferred","reject_reason":"","_id":"2"}]`)
+	defer server.Close()
+
+	unknown := []string{}
+	m.OnUnknownStatus = func(res *Response) {
+		unknown = append(unknown, res.Email)
+	}
+
+	responses, err := m.MessagesSend(&Message{})
+	expect(t, err, nil)
+	expect(t, len(responses), 2)
+	expect(t, reflect.DeepEqual(unknown, []string{"jill@example.com"}), true)
+}
+
+func Test_StrictStatuses(t *testing.T) {
+	server, m := testTools(200, `[{"email":"bob@example.com","status":"rejected","reject_reason":"new-reason","_id":"1"}]`)
+	defer server.Close()
+	m.StrictStatuses = true
+
+	responses, err := m.MessagesSend(&Message{})
+	expect(t, len(responses), 1)
+	statusErr, ok := err.(*UnknownStatusError)
+	expect(t, ok, true)
+	expect(t, statusErr.Response, responses[0])
+	expect(t, err.Error(), `mandrill: unknown status "rejected" (reject_reason "new-reason") for bob@example.com`)
+}
+
+// Context //////////
+
+func Test_MessagesSendContext_Canceled(t *testing.T) {
+	server, m := testTools(200, `[]`)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := m.MessagesSendContext(ctx, &Message{})
+	expect(t, errors.Is(err, context.Canceled), true)
+
+	_, err = m.MessagesSendTemplateContext(ctx, &Message{}, "cheese", nil)
+	expect(t, errors.Is(err, context.Canceled), true)
+
+	_, err = m.PingContext(ctx)
+	expect(t, errors.Is(err, context.Canceled), true)
+}
+
+func Test_MessagesSendContext_Deadline(t *testing.T) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	c := ClientWithKey("APIKEY")
+	c.BaseURL = server.URL + "/"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := c.MessagesSendContext(ctx, &Message{})
+	expect(t, errors.Is(err, context.DeadlineExceeded), true)
+}
+
+func Test_MaxInFlight_Context(t *testing.T) {
+	c := ClientWithKey("APIKEY")
+	c.MaxInFlight = 1
+	release, _ := c.acquireInFlight(context.Background())
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.PingContext(ctx)
+	expect(t, err, context.Canceled)
+}
+
+// User-Agent //////////
+
+func Test_UserAgent(t *testing.T) {
+	server, c, req := testRecorder(200, `"PONG!"`)
+	defer server.Close()
+
+	_, err := c.Ping()
+	expect(t, err, nil)
+	expect(t, req.Header.Get("User-Agent"), "keighl-mandrill/"+Version+" (+https://github.com/keighl/mandrill)")
+
+	c.UserAgent = "billing-service/2.3"
+	_, err = c.Ping()
+	expect(t, err, nil)
+	expect(t, req.Header.Get("User-Agent"), "keighl-mandrill/"+Version+" (+https://github.com/keighl/mandrill) billing-service/2.3")
+}
+
+// MessageSender //////////
+
+type fakeSender struct {
+	MessageSender
+	sent []*Message
+}
+
+func (f *fakeSender) MessagesSend(message *Message) ([]*Response, error) {
+	f.sent = append(f.sent, message)
+	return []*Response{{Email: message.To[0].Email, Status: "sent"}}, nil
+}
+
+func sendWelcome(sender MessageSender, email string) error {
+	message := &Message{Subject: "Welcome!"}
+	message.AddRecipient(email, "", "to")
+	_, err := sender.MessagesSend(message)
+	return err
+}
+
+func Test_MessageSender(t *testing.T) {
+	fake := &fakeSender{}
+	expect(t, sendWelcome(fake, "bob@example.com"), nil)
+	expect(t, len(fake.sent), 1)
+	expect(t, fake.sent[0].To[0].Email, "bob@example.com")
+
+	expect(t, sendWelcome(ClientWithKey("SANDBOX_SUCCESS"), "bob@example.com"), nil)
+}
+
 // Ping //////////
 
 func Test_Ping_Success(t *testing.T) {
@@ -128,6 +669,27 @@ func Test_Ping_Success(t *testing.T) {
 	expect(t, err, nil)
 }
 
+func Test_Ping2_Success(t *testing.T) {
+	server, c, req := testRecorder(200, `{"PING":"PONG!"}`)
+	defer server.Close()
+	pong, err := c.Ping2()
+
+	expect(t, err, nil)
+	expect(t, req.Path, "/users/ping2.json")
+	expect(t, pong.Ping, "PONG!")
+}
+
+func Test_Ping2_InvalidKey(t *testing.T) {
+	server, c, _ := testRecorder(500, `{"status":"error","code":-1,"name":"Invalid_Key","message":"Invalid API key"}`)
+	defer server.Close()
+	pong, err := c.Ping2()
+
+	expect(t, pong, (*Pong)(nil))
+	apiErr, ok := err.(*Error)
+	expect(t, ok, true)
+	expect(t, apiErr.Name, "Invalid_Key")
+}
+
 func Test_Ping_Fail(t *testing.T) {
 	server, m := testTools(400, `{"status":"error","code":-1,"name":"Invalid_Key","message":"Invalid API key"}`)
 	defer server.Close()
@@ -136,10 +698,11 @@ func Test_Ping_Fail(t *testing.T) {
 	expect(t, response, "")
 
 	correctMessagesResponse := &Error{
-		Status:  "error",
-		Code:    -1,
-		Name:    "Invalid_Key",
-		Message: "Invalid API key",
+		Status:     "error",
+		Code:       -1,
+		Name:       "Invalid_Key",
+		Message:    "Invalid API key",
+		StatusCode: 400,
 	}
 	expect(t, reflect.DeepEqual(correctMessagesResponse, err), true)
 }
@@ -148,8 +711,25 @@ func Test_Ping_Fail(t *testing.T) {
 
 func Test_SANDBOX_SUCCESS(t *testing.T) {
 	client := ClientWithKey("SANDBOX_SUCCESS")
-	_, err := client.MessagesSend(&Message{})
+	responses, err := client.MessagesSend(&Message{})
+	expect(t, err, nil)
+	expect(t, len(responses), 0)
+
+	message := &Message{}
+	message.AddRecipient("bob@example.com", "Bob", "to")
+	message.AddRecipient("jill@example.com", "Jill", "cc")
+	responses, err = client.MessagesSend(message)
+	expect(t, err, nil)
+	expect(t, len(responses), 2)
+	expect(t, responses[0].Email, "bob@example.com")
+	expect(t, responses[0].Status, "sent")
+	expect(t, len(responses[0].Id), 32)
+	refute(t, responses[0].Id, responses[1].Id)
+	expect(t, responses[1].Email, "jill@example.com")
+
+	responses, err = client.MessagesSendRaw("Subject: Hi\r\n\r\nHi", "", "", []string{"raw@example.com"}, nil)
 	expect(t, err, nil)
+	expect(t, responses[0].Email, "raw@example.com")
 }
 
 func Test_SANDBOX_ERROR(t *testing.T) {
@@ -158,6 +738,118 @@ func Test_SANDBOX_ERROR(t *testing.T) {
 	refute(t, err, nil)
 }
 
+func Test_SandboxResponder(t *testing.T) {
+	client := ClientWithKey("SANDBOX_SUCCESS")
+	client.SandboxResponder = func(message *Message) ([]*Response, error) {
+		responses := []*Response{}
+		for _, to := range message.To {
+			status, reason := "queued", ""
+			if to.Email == "bounce@example.com" {
+				status, reason = "rejected", "hard-bounce"
+			}
+			responses = append(responses, &Response{Email: to.Email, Status: status, RejectionReason: reason})
+		}
+		return responses, nil
+	}
+
+	message := &Message{}
+	message.AddRecipient("bob@example.com", "Bob", "to")
+	message.AddRecipient("bounce@example.com", "Bounce", "to")
+	responses, err := client.MessagesSend(message)
+	expect(t, err, nil)
+	expect(t, responses[0].Status, "queued")
+	expect(t, responses[1].RejectionReason, "hard-bounce")
+
+	responses, err = client.MessagesSendRaw("Subject: Hi\r\n\r\nHi", "", "", []string{"bounce@example.com"}, nil)
+	expect(t, err, nil)
+	expect(t, responses[0].Status, "rejected")
+}
+
+func Test_SandboxResponder_Error(t *testing.T) {
+	client := ClientWithKey("SANDBOX_ERROR")
+	client.SandboxResponder = func(message *Message) ([]*Response, error) {
+		return nil, &Error{Status: "error", Code: 12, Name: "Unknown_Subaccount", Message: "No subaccount exists with the id 'customer-123'"}
+	}
+
+	responses, err := client.MessagesSendTemplate(&Message{}, "welcome", nil)
+	expect(t, len(responses), 0)
+	expect(t, err.(*Error).Name, "Unknown_Subaccount")
+}
+
+func Test_SandboxResponder_StrictStatuses(t *testing.T) {
+	client := ClientWithKey("SANDBOX_SUCCESS")
+	client.StrictStatuses = true
+	client.SandboxResponder = func(message *Message) ([]*Response, error) {
+		return []*Response{{Email: "bob@example.com", Status: "bogus"}}, nil
+	}
+
+	_, err := client.MessagesSend(&Message{})
+	_, ok := err.(*UnknownStatusError)
+	expect(t, ok, true)
+}
+
+func Test_SandboxResponder_RealKey(t *testing.T) {
+	server, client, req := testRecorder(200, `[]`)
+	defer server.Close()
+	client.SandboxResponder = func(message *Message) ([]*Response, error) {
+		t.Error("responder called for a real key")
+		return nil, nil
+	}
+
+	_, err := client.MessagesSend(&Message{})
+	expect(t, err, nil)
+	expect(t, req.Path, "/messages/send.json")
+}
+
+// CheckURL //////////
+
+func Test_CheckURL(t *testing.T) {
+	c := ClientWithKey("APIKEY")
+	expect(t, c.CheckURL("http://internal.local/"), nil)
+
+	c.AllowedHosts = []string{"mandrillapp.com"}
+	c.AllowedSchemes = []string{"https"}
+	expect(t, c.CheckURL("https://MandrillApp.com/api/1.0/"), nil)
+	expect(t, errors.Is(c.CheckURL("https://169.254.169.254/"), ErrURLNotAllowed), true)
+	expect(t, errors.Is(c.CheckURL("http://mandrillapp.com/"), ErrURLNotAllowed), true)
+}
+
+func Test_MessageSend_HostNotAllowed(t *testing.T) {
+	server, m := testTools(200, `[]`)
+	defer server.Close()
+	m.AllowedHosts = []string{"mandrillapp.com"}
+	_, err := m.MessagesSend(&Message{})
+	expect(t, errors.Is(err, ErrURLNotAllowed), true)
+}
+
+func Test_MessageSend_RedirectNotAllowed(t *testing.T) {
+	var leaked int32
+	elsewhere := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&leaked, 1)
+		fmt.Fprintln(w, `[]`)
+	}))
+	defer elsewhere.Close()
+	target := strings.Replace(elsewhere.URL, "127.0.0.1", "localhost", 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target+r.URL.Path, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	m := ClientWithKey("APIKEY")
+	m.BaseURL = server.URL + "/"
+	m.AllowedHosts = []string{"127.0.0.1"}
+	_, err := m.MessagesSend(&Message{})
+	expect(t, errors.Is(err, ErrURLNotAllowed), true)
+	expect(t, atomic.LoadInt32(&leaked), int32(0))
+
+	m.AllowedHosts = []string{"127.0.0.1", "localhost"}
+	_, err = m.MessagesSend(&Message{})
+	expect(t, err, nil)
+	expect(t, atomic.LoadInt32(&leaked), int32(1))
+	expect(t, m.HTTPClient.CheckRedirect == nil, true)
+}
+
 // AddRecipient //////////
 
 func Test_AddRecipient(t *testing.T) {
@@ -167,6 +859,14 @@ func Test_AddRecipient(t *testing.T) {
 	expect(t, reflect.DeepEqual(m.To, tos), true)
 }
 
+func Test_AddRecipient_CombinedAddress(t *testing.T) {
+	m := &Message{}
+	m.AddRecipient("Bob Johnson <bob@example.com>", "", "to")
+	m.AddRecipient(`"Jill" <jill@example.com>`, "Jill Smith", "cc")
+	tos := []*To{{"bob@example.com", "Bob Johnson", "to"}, {"jill@example.com", "Jill Smith", "cc"}}
+	expect(t, reflect.DeepEqual(m.To, tos), true)
+}
+
 // ConvertMapToVariables /////
 
 func Test_ConvertMapToVariables(t *testing.T) {
@@ -188,6 +888,25 @@ func Test_ConvertMapToVariables_BadType(t *testing.T) {
 	expect(t, len(target), 0)
 }
 
+func Test_ConvertTemplateContent(t *testing.T) {
+	vars, err := ConvertTemplateContent(nil)
+	expect(t, err, nil)
+	expect(t, len(vars), 0)
+	refute(t, vars, nil)
+
+	hand := []*Variable{{"name", "bob"}}
+	vars, err = ConvertTemplateContent(hand)
+	expect(t, err, nil)
+	expect(t, reflect.DeepEqual(vars, hand), true)
+
+	vars, err = ConvertTemplateContent(map[string]string{"name": "bob"})
+	expect(t, err, nil)
+	expect(t, reflect.DeepEqual(vars, hand), true)
+
+	_, err = ConvertTemplateContent(42)
+	refute(t, err, nil)
+}
+
 func Test_MapToVars(t *testing.T) {
 	m := map[string]interface{}{"name": "bob"}
 	target := MapToVars(m)
@@ -217,3 +936,37 @@ func Test_ErrorError(t *testing.T) {
 	e := Error{Message: "CHEEEEEESE"}
 	expect(t, e.Error(), "CHEEEEEESE")
 }
+
+func Test_ErrorError_RawBody(t *testing.T) {
+	e := Error{StatusCode: 502, Body: []byte("<html>Bad Gateway</html>\n")}
+	expect(t, e.Error(), "mandrill: HTTP 502: <html>Bad Gateway</html>")
+
+	e = Error{StatusCode: 503}
+	expect(t, e.Error(), "mandrill: HTTP 503")
+
+	e = Error{StatusCode: 502, Body: []byte(strings.Repeat("x", 300))}
+	expect(t, e.Error(), "mandrill: HTTP 502: "+strings.Repeat("x", 200)+"...")
+}
+
+func Test_Error_UndecodableBody(t *testing.T) {
+	server, c, _ := testRecorder(502, "<html>Bad Gateway</html>")
+	defer server.Close()
+
+	_, err := c.Ping()
+	apiErr, ok := err.(*Error)
+	expect(t, ok, true)
+	expect(t, apiErr.StatusCode, 502)
+	expect(t, strings.TrimSpace(string(apiErr.Body)), "<html>Bad Gateway</html>")
+	expect(t, err.Error(), "mandrill: HTTP 502: <html>Bad Gateway</html>")
+}
+
+func Test_Error_DecodedBody(t *testing.T) {
+	server, c, _ := testRecorder(500, `{"status":"error","code":-1,"name":"Invalid_Key","message":"Invalid API key"}`)
+	defer server.Close()
+
+	_, err := c.Ping()
+	apiErr := err.(*Error)
+	expect(t, apiErr.StatusCode, 500)
+	expect(t, apiErr.Body == nil, true)
+	expect(t, err.Error(), "Invalid API key")
+}
@@ -0,0 +1,92 @@
+package mandrilltest
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/keighl/mandrill"
+)
+
+// Cassette //////////
+
+func Test_Cassette_RecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	// record against a live server
+	server := NewServer()
+	server.SetStatus("bounce@example.com", "rejected", "hard-bounce")
+	server.SetError("users/ping.json", 500, &mandrill.Error{Status: "error", Code: -1, Name: "Invalid_Key", Message: "Invalid API key"})
+
+	recorder, err := NewCassette(path, ModeRecord)
+	expect(t, err, nil)
+	client := mandrill.ClientWithKey("SECRET-KEY")
+	client.BaseURL = server.URL
+	client.HTTPClient = recorder.HTTPClient()
+
+	message := &mandrill.Message{Subject: "Hello"}
+	message.AddRecipient("bounce@example.com", "Bounce", "to")
+	recorded, err := client.MessagesSend(message)
+	expect(t, err, nil)
+	_, err = client.Ping()
+	expect(t, err.(*mandrill.Error).Name, "Invalid_Key")
+
+	expect(t, recorder.Save(), nil)
+	server.Close()
+
+	data, _ := ioutil.ReadFile(path)
+	expect(t, strings.Contains(string(data), "SECRET-KEY"), false)
+	expect(t, strings.Contains(string(data), `"path": "messages/send.json"`), true)
+
+	// replay without a server or a real key
+	player, err := NewCassette(path, ModeReplay)
+	expect(t, err, nil)
+	client = mandrill.ClientWithKey("ANY-KEY")
+	client.BaseURL = "https://mandrillapp.com/api/1.0/"
+	client.HTTPClient = player.HTTPClient()
+
+	replayed, err := client.MessagesSend(message)
+	expect(t, err, nil)
+	expect(t, replayed[0].Status, "rejected")
+	expect(t, replayed[0].Id, recorded[0].Id)
+
+	_, err = client.Ping()
+	apiErr := err.(*mandrill.Error)
+	expect(t, apiErr.Name, "Invalid_Key")
+	expect(t, apiErr.StatusCode, 500)
+
+	// each interaction replays once
+	_, err = client.Ping()
+	expect(t, strings.Contains(err.Error(), "no recorded interaction for users/ping.json"), true)
+}
+
+func Test_Cassette_ReplayMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	ioutil.WriteFile(path, []byte(`[{"path":"messages/send.json","request":{"message":{"subject":"Hello","to":null}},"status_code":200,"response":"[]"}]`), 0644)
+
+	player, err := NewCassette(path, ModeReplay)
+	expect(t, err, nil)
+	client := mandrill.ClientWithKey("KEY")
+	client.HTTPClient = player.HTTPClient()
+
+	_, err = client.MessagesSend(&mandrill.Message{Subject: "Goodbye"})
+	refute(t, err, nil)
+
+	responses, err := client.MessagesSend(&mandrill.Message{Subject: "Hello"})
+	expect(t, err, nil)
+	expect(t, len(responses), 0)
+}
+
+func Test_Cassette_Missing(t *testing.T) {
+	_, err := NewCassette(filepath.Join(t.TempDir(), "missing.json"), ModeReplay)
+	refute(t, err, nil)
+}
+
+func Test_Cassette_SaveReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	player := &Cassette{Path: path, Mode: ModeReplay}
+	expect(t, player.Save(), nil)
+	_, err := ioutil.ReadFile(path)
+	refute(t, err, nil)
+}
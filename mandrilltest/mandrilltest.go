@@ -0,0 +1,206 @@
+// Package mandrilltest provides an in-process server speaking the Mandrill
+// JSON protocol, for testing code that sends email.
+//
+//	server := mandrilltest.NewServer()
+//	defer server.Close()
+//	server.SetStatus("bounce@example.com", "rejected", "hard-bounce")
+//
+//	client := server.Client()
+//	responses, err := client.MessagesSend(message)
+//
+//	sent := server.Messages()
+package mandrilltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/keighl/mandrill"
+)
+
+// Request is an API request received by the server
+type Request struct {
+	// the API path, e.g. "messages/send.json"
+	Path string
+	// the decoded JSON payload
+	Payload map[string]interface{}
+	// the raw JSON payload
+	Body []byte
+}
+
+type scriptedError struct {
+	code int
+	err  *mandrill.Error
+}
+
+// Server is a fake Mandrill API. Sends get a "sent" response for every
+// recipient unless scripted otherwise; other paths respond with an empty
+// JSON object unless given a response with SetResponse.
+type Server struct {
+	// the base URL of the server, e.g. "http://127.0.0.1:51234/"
+	URL string
+
+	server *httptest.Server
+
+	mu        sync.Mutex
+	requests  []*Request
+	statuses  map[string]*mandrill.Response
+	errors    map[string]*scriptedError
+	responses map[string]interface{}
+	nextID    int
+}
+
+// NewServer starts a server. Close it when the test is done.
+func NewServer() *Server {
+	s := &Server{
+		statuses:  map[string]*mandrill.Response{},
+		errors:    map[string]*scriptedError{},
+		responses: map[string]interface{}{},
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL + "/"
+	return s
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a client that sends its requests to the server
+func (s *Server) Client() *mandrill.Client {
+	client := mandrill.ClientWithKey("MANDRILLTEST")
+	client.BaseURL = s.URL
+	return client
+}
+
+// SetStatus scripts the response status, and optional reject reason, for sends to email
+func (s *Server) SetStatus(email, status, rejectReason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.statuses[strings.ToLower(email)] = &mandrill.Response{Status: status, RejectionReason: rejectReason}
+}
+
+// SetError makes requests to path, e.g. "messages/send.json", fail with the HTTP code and API error.
+// A nil err clears the script.
+func (s *Server) SetError(path string, code int, err *mandrill.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		delete(s.errors, path)
+		return
+	}
+	s.errors[path] = &scriptedError{code: code, err: err}
+}
+
+// SetResponse makes requests to path respond with v encoded as JSON
+func (s *Server) SetResponse(path string, v interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses[path] = v
+}
+
+// Requests returns the requests received so far
+func (s *Server) Requests() []*Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*Request(nil), s.requests...)
+}
+
+// Messages returns the messages of the messages/send and messages/send-template requests received so far
+func (s *Server) Messages() []*mandrill.Message {
+	messages := []*mandrill.Message{}
+	for _, req := range s.Requests() {
+		if req.Path != "messages/send.json" && req.Path != "messages/send-template.json" {
+			continue
+		}
+		var payload struct {
+			Message *mandrill.Message `json:"message"`
+		}
+		if json.Unmarshal(req.Body, &payload) == nil && payload.Message != nil {
+			messages = append(messages, payload.Message)
+		}
+	}
+	return messages
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	req := &Request{Path: strings.TrimPrefix(r.URL.Path, "/"), Body: body}
+	json.Unmarshal(body, &req.Payload)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, req)
+	w.Header().Set("Content-Type", "application/json")
+
+	if scripted, ok := s.errors[req.Path]; ok {
+		w.WriteHeader(scripted.code)
+		json.NewEncoder(w).Encode(scripted.err)
+		return
+	}
+
+	if v, ok := s.responses[req.Path]; ok {
+		json.NewEncoder(w).Encode(v)
+		return
+	}
+
+	switch req.Path {
+	case "messages/send.json", "messages/send-template.json", "messages/send-raw.json":
+		json.NewEncoder(w).Encode(s.sendResponses(req.Payload))
+	case "users/ping.json":
+		json.NewEncoder(w).Encode("PONG!")
+	case "users/ping2.json":
+		json.NewEncoder(w).Encode(map[string]string{"PING": "PONG!"})
+	default:
+		json.NewEncoder(w).Encode(map[string]interface{}{})
+	}
+}
+
+// sendResponses builds a response for each recipient of a send payload
+func (s *Server) sendResponses(payload map[string]interface{}) []*mandrill.Response {
+	responses := []*mandrill.Response{}
+	for _, email := range recipients(payload) {
+		s.nextID++
+		response := &mandrill.Response{Email: email, Status: "sent", Id: fmt.Sprintf("%032x", s.nextID)}
+		if scripted, ok := s.statuses[strings.ToLower(email)]; ok {
+			response.Status = scripted.Status
+			response.RejectionReason = scripted.RejectionReason
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+// recipients reads the recipient addresses of messages/send, send-template and send-raw payloads
+func recipients(payload map[string]interface{}) []string {
+	var to []interface{}
+	if message, ok := payload["message"].(map[string]interface{}); ok {
+		to, _ = message["to"].([]interface{})
+	} else {
+		to, _ = payload["to"].([]interface{})
+	}
+
+	emails := []string{}
+	for _, recipient := range to {
+		switch r := recipient.(type) {
+		case map[string]interface{}:
+			if email, ok := r["email"].(string); ok {
+				emails = append(emails, email)
+			}
+		case string:
+			emails = append(emails, r)
+		}
+	}
+	return emails
+}
@@ -0,0 +1,111 @@
+package mandrilltest
+
+import (
+	"testing"
+
+	"github.com/keighl/mandrill"
+)
+
+func expect(t *testing.T, a interface{}, b interface{}) {
+	if a != b {
+		t.Errorf("Expected %v (type %[1]T) - Got %v (type %[2]T)", b, a)
+	}
+}
+
+func refute(t *testing.T, a interface{}, b interface{}) {
+	if a == b {
+		t.Errorf("Did not expect %v (type %[1]T) - Got %v (type %[2]T)", b, a)
+	}
+}
+
+func testMessage() *mandrill.Message {
+	message := &mandrill.Message{Subject: "Hello"}
+	message.AddRecipient("bob@example.com", "Bob", "to")
+	message.AddRecipient("Bounce@Example.com", "Bounce", "cc")
+	return message
+}
+
+// Server //////////
+
+func Test_Server_Send(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.SetStatus("bounce@example.com", "rejected", "hard-bounce")
+
+	responses, err := server.Client().MessagesSend(testMessage())
+	expect(t, err, nil)
+	expect(t, len(responses), 2)
+	expect(t, responses[0].Email, "bob@example.com")
+	expect(t, responses[0].Status, "sent")
+	refute(t, responses[0].Id, "")
+	expect(t, responses[1].Status, "rejected")
+	expect(t, responses[1].RejectionReason, "hard-bounce")
+	refute(t, responses[1].Id, responses[0].Id)
+
+	requests := server.Requests()
+	expect(t, len(requests), 1)
+	expect(t, requests[0].Path, "messages/send.json")
+	expect(t, requests[0].Payload["key"], "MANDRILLTEST")
+
+	messages := server.Messages()
+	expect(t, len(messages), 1)
+	expect(t, messages[0].Subject, "Hello")
+	expect(t, messages[0].To[1].Type, "cc")
+}
+
+func Test_Server_SendTemplateAndRaw(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	responses, err := client.MessagesSendTemplate(testMessage(), "welcome", nil)
+	expect(t, err, nil)
+	expect(t, len(responses), 2)
+
+	responses, err = client.MessagesSendRaw("Subject: Hi\r\n\r\nHi", "", "", []string{"raw@example.com"}, nil)
+	expect(t, err, nil)
+	expect(t, responses[0].Email, "raw@example.com")
+
+	expect(t, len(server.Messages()), 1)
+	expect(t, server.Requests()[0].Payload["template_name"], "welcome")
+}
+
+func Test_Server_SetError(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.SetError("messages/send.json", 500, &mandrill.Error{Status: "error", Code: 12, Name: "Unknown_Subaccount", Message: "No subaccount"})
+
+	_, err := server.Client().MessagesSend(testMessage())
+	apiErr, ok := err.(*mandrill.Error)
+	expect(t, ok, true)
+	expect(t, apiErr.Name, "Unknown_Subaccount")
+	expect(t, apiErr.StatusCode, 500)
+
+	server.SetError("messages/send.json", 0, nil)
+	_, err = server.Client().MessagesSend(testMessage())
+	expect(t, err, nil)
+}
+
+func Test_Server_SetResponse(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.SetResponse("users/info.json", map[string]interface{}{"username": "tester", "reputation": 80})
+
+	user, err := server.Client().UsersInfo()
+	expect(t, err, nil)
+	expect(t, user.Username, "tester")
+	expect(t, user.Reputation, 80)
+}
+
+func Test_Server_Ping(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	pong, err := server.Client().Ping()
+	expect(t, err, nil)
+	expect(t, pong, "PONG!")
+
+	pong2, err := server.Client().Ping2()
+	expect(t, err, nil)
+	expect(t, pong2.Ping, "PONG!")
+}
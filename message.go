@@ -0,0 +1,264 @@
+package mandrill
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"net/mail"
+	"regexp"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+const (
+	preheaderStart = "<!--mandrill:preheader-->"
+	preheaderEnd   = "<!--/mandrill:preheader-->"
+	preheaderStyle = "display:none;font-size:1px;color:#ffffff;line-height:1px;max-height:0px;max-width:0px;opacity:0;overflow:hidden;mso-hide:all;"
+	// zero-width padding keeps clients from pulling body text into the preview after the preheader
+	preheaderPadding = "&#847;&zwnj;&nbsp;"
+)
+
+var (
+	bodyTagPattern   = regexp.MustCompile(`(?i)<body[^>]*>`)
+	preheaderPattern = regexp.MustCompile(regexp.QuoteMeta(preheaderStart) + `(?s:.*?)` + regexp.QuoteMeta(preheaderEnd))
+)
+
+// MessageDefaults are merged into every message sent by a Client.
+// Values already set on a message take precedence. Defaults are applied to
+// the copy being sent, leaving the caller's message unchanged.
+type MessageDefaults struct {
+	// tags added to every message
+	Tags []string
+	// metadata added to every message, e.g. {"env": "production"}
+	Metadata map[string]string
+	// Google Analytics domains added to every message
+	GoogleAnalyticsDomains []string
+	// utm_campaign used when a message doesn't set one
+	GoogleAnalyticsCampaign string
+	// subaccount used when a message doesn't set one
+	Subaccount string
+}
+
+func (d *MessageDefaults) apply(m *Message) {
+	m.Tags = appendMissing(m.Tags, d.Tags)
+	m.GoogleAnalyticsDomains = appendMissing(m.GoogleAnalyticsDomains, d.GoogleAnalyticsDomains)
+
+	if m.GoogleAnalyticsCampaign == "" {
+		m.GoogleAnalyticsCampaign = d.GoogleAnalyticsCampaign
+	}
+
+	if m.Subaccount == "" {
+		m.Subaccount = d.Subaccount
+	}
+
+	for k, v := range d.Metadata {
+		if m.Metadata == nil {
+			m.Metadata = map[string]string{}
+		}
+		if _, ok := m.Metadata[k]; !ok {
+			m.Metadata[k] = v
+		}
+	}
+}
+
+func appendMissing(list []string, values []string) []string {
+	for _, v := range values {
+		found := false
+		for _, item := range list {
+			if item == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// SetPreheader inserts hidden preview text at the top of the message HTML body,
+// replacing any preheader set previously
+func (m *Message) SetPreheader(text string) {
+	snippet := preheaderStart +
+		`<div style="` + preheaderStyle + `">` +
+		html.EscapeString(text) +
+		strings.Repeat(preheaderPadding, 90) +
+		`</div>` + preheaderEnd
+
+	if preheaderPattern.MatchString(m.HTML) {
+		m.HTML = preheaderPattern.ReplaceAllLiteralString(m.HTML, snippet)
+		return
+	}
+
+	if loc := bodyTagPattern.FindStringIndex(m.HTML); loc != nil {
+		m.HTML = m.HTML[:loc[1]] + snippet + m.HTML[loc[1]:]
+		return
+	}
+
+	m.HTML = snippet + m.HTML
+}
+
+// AddRecipients validates and appends a recipient for each email address. Combined
+// addresses such as "Bob Johnson <bob@example.com>" are split into the email and name.
+// Nothing is appended if any address or the send type is invalid.
+func (m *Message) AddRecipients(emails []string, sendType string) error {
+	if err := validateSendType(sendType); err != nil {
+		return err
+	}
+
+	tos := make([]*To, 0, len(emails))
+	for _, email := range emails {
+		to, err := parseRecipient(email, "", sendType)
+		if err != nil {
+			return err
+		}
+		tos = append(tos, to)
+	}
+
+	m.To = append(m.To, tos...)
+	return nil
+}
+
+// AddRecipientAddresses validates and appends a recipient for each parsed address.
+// Nothing is appended if any address or the send type is invalid.
+func (m *Message) AddRecipientAddresses(addresses []*mail.Address, sendType string) error {
+	if err := validateSendType(sendType); err != nil {
+		return err
+	}
+
+	tos := make([]*To, 0, len(addresses))
+	for _, address := range addresses {
+		if address == nil {
+			return fmt.Errorf("mandrill: invalid recipient: nil address")
+		}
+		to, err := parseRecipient(address.Address, address.Name, sendType)
+		if err != nil {
+			return err
+		}
+		tos = append(tos, to)
+	}
+
+	m.To = append(m.To, tos...)
+	return nil
+}
+
+// AddRecipientsTo validates and appends a copy of each recipient, keeping its
+// own name and send type. Combined addresses are split as in AddRecipients.
+// Nothing is appended if any recipient is invalid.
+func (m *Message) AddRecipientsTo(recipients []To) error {
+	tos := make([]*To, 0, len(recipients))
+	for _, recipient := range recipients {
+		if err := validateSendType(recipient.Type); err != nil {
+			return err
+		}
+		to, err := parseRecipient(recipient.Email, recipient.Name, recipient.Type)
+		if err != nil {
+			return err
+		}
+		tos = append(tos, to)
+	}
+
+	m.To = append(m.To, tos...)
+	return nil
+}
+
+// SetReplyTo validates email and sets it as the message's Reply-To header,
+// creating the Headers map if needed. An empty email removes the header.
+func (m *Message) SetReplyTo(email string) error {
+	if email == "" {
+		delete(m.Headers, "Reply-To")
+		return nil
+	}
+	if _, err := mail.ParseAddress(email); err != nil {
+		return fmt.Errorf("mandrill: invalid reply-to %q: %v", email, err)
+	}
+	if m.Headers == nil {
+		m.Headers = map[string]string{}
+	}
+	m.Headers["Reply-To"] = email
+	return nil
+}
+
+// parseRecipient validates email, which may be a combined address such as "Bob Johnson <bob@example.com>",
+// and returns a recipient with the bare address. The parsed display name is used unless name is given.
+func parseRecipient(email, name, sendType string) (*To, error) {
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return nil, fmt.Errorf("mandrill: invalid recipient %q: %v", email, err)
+	}
+	if name == "" {
+		name = address.Name
+	}
+	return &To{Email: address.Address, Name: name, Type: sendType}, nil
+}
+
+func validateSendType(sendType string) error {
+	switch sendType {
+	case "", "to", "cc", "bcc":
+		return nil
+	}
+	return fmt.Errorf("mandrill: invalid recipient type %q", sendType)
+}
+
+// SetSendAt schedules the message for t, converting it to the UTC timestamp Mandrill expects.
+// The zero time clears the schedule.
+func (m *Message) SetSendAt(t time.Time) {
+	if t.IsZero() {
+		m.SendAt = ""
+		return
+	}
+	m.SendAt = t.UTC().Format(TimestampFormat)
+}
+
+// Bool returns a pointer to v, for the message options that distinguish false from unset
+func Bool(v bool) *bool {
+	return &v
+}
+
+// SetTrackOpens turns open tracking on or off, overriding the account default
+func (m *Message) SetTrackOpens(v bool) {
+	m.TrackOpens = Bool(v)
+}
+
+// SetTrackClicks turns click tracking on or off, overriding the account default
+func (m *Message) SetTrackClicks(v bool) {
+	m.TrackClicks = Bool(v)
+}
+
+// SetInlineCSS turns CSS inlining on or off, overriding the account default
+func (m *Message) SetInlineCSS(v bool) {
+	m.InlineCSS = Bool(v)
+}
+
+// SetPreserveRecipients sets whether every recipient is exposed in the "To" header, overriding the account default
+func (m *Message) SetPreserveRecipients(v bool) {
+	m.PreserveRecipients = Bool(v)
+}
+
+// SetViewContentLink turns content logging on or off, overriding the account default
+func (m *Message) SetViewContentLink(v bool) {
+	m.ViewContentLink = Bool(v)
+}
+
+// SetHTMLTemplate executes tpl with data and uses the result as the message HTML. The HTML is left untouched if the template fails.
+func (m *Message) SetHTMLTemplate(tpl *template.Template, data interface{}) error {
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("mandrill: html template %q: %v", tpl.Name(), err)
+	}
+	m.HTML = buf.String()
+	return nil
+}
+
+// SetTextTemplate executes tpl with data and uses the result as the message Text. The Text is left untouched if the template fails.
+func (m *Message) SetTextTemplate(tpl *texttemplate.Template, data interface{}) error {
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("mandrill: text template %q: %v", tpl.Name(), err)
+	}
+	m.Text = buf.String()
+	return nil
+}
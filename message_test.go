@@ -0,0 +1,203 @@
+package mandrill
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/mail"
+	"reflect"
+	"strings"
+	"testing"
+	texttemplate "text/template"
+	"time"
+)
+
+// SetPreheader //////////
+
+func Test_SetPreheader(t *testing.T) {
+	m := &Message{HTML: `<html><BODY class="x"><h1>Hi</h1></BODY></html>`}
+	m.SetPreheader("Your <receipt>")
+
+	expect(t, strings.HasPrefix(m.HTML, `<html><BODY class="x">`+preheaderStart+`<div style="display:none;`), true)
+	expect(t, strings.Contains(m.HTML, "Your &lt;receipt&gt;"+preheaderPadding), true)
+	expect(t, strings.HasSuffix(m.HTML, preheaderEnd+"<h1>Hi</h1></BODY></html>"), true)
+
+	m.SetPreheader("Updated")
+	expect(t, strings.Count(m.HTML, preheaderStart), 1)
+	expect(t, strings.Contains(m.HTML, "Updated"), true)
+	expect(t, strings.Contains(m.HTML, "receipt"), false)
+}
+
+func Test_SetPreheader_NoBody(t *testing.T) {
+	m := &Message{HTML: "<h1>Hi</h1>"}
+	m.SetPreheader("Preview")
+	expect(t, strings.HasPrefix(m.HTML, preheaderStart), true)
+	expect(t, strings.HasSuffix(m.HTML, "<h1>Hi</h1>"), true)
+}
+
+// AddRecipients //////////
+
+func Test_AddRecipients(t *testing.T) {
+	m := &Message{}
+	err := m.AddRecipients([]string{"bob@example.com", "jill@example.com"}, "cc")
+	expect(t, err, nil)
+	tos := []*To{{"bob@example.com", "", "cc"}, {"jill@example.com", "", "cc"}}
+	expect(t, reflect.DeepEqual(m.To, tos), true)
+}
+
+func Test_AddRecipients_Combined(t *testing.T) {
+	m := &Message{}
+	err := m.AddRecipients([]string{"Bob Johnson <bob@example.com>", "<jill@example.com>"}, "to")
+	expect(t, err, nil)
+	tos := []*To{{"bob@example.com", "Bob Johnson", "to"}, {"jill@example.com", "", "to"}}
+	expect(t, reflect.DeepEqual(m.To, tos), true)
+}
+
+func Test_AddRecipients_Invalid(t *testing.T) {
+	m := &Message{}
+	refute(t, m.AddRecipients([]string{"bob@example.com", "nope"}, "to"), nil)
+	refute(t, m.AddRecipients([]string{"bob@example.com"}, "from"), nil)
+	expect(t, len(m.To), 0)
+}
+
+func Test_AddRecipientsTo(t *testing.T) {
+	m := &Message{}
+	recipients := []To{{"bob@example.com", "Bob Johnson", "to"}, {"jill@example.com", "", "bcc"}}
+	err := m.AddRecipientsTo(recipients)
+	expect(t, err, nil)
+	tos := []*To{{"bob@example.com", "Bob Johnson", "to"}, {"jill@example.com", "", "bcc"}}
+	expect(t, reflect.DeepEqual(m.To, tos), true)
+
+	recipients[0].Email = "changed@example.com"
+	expect(t, m.To[0].Email, "bob@example.com")
+}
+
+func Test_AddRecipientsTo_Combined(t *testing.T) {
+	m := &Message{}
+	err := m.AddRecipientsTo([]To{{Email: "Bob Johnson <bob@example.com>", Type: "to"}, {Email: "Jill <jill@example.com>", Name: "Jill Smith"}})
+	expect(t, err, nil)
+	tos := []*To{{"bob@example.com", "Bob Johnson", "to"}, {"jill@example.com", "Jill Smith", ""}}
+	expect(t, reflect.DeepEqual(m.To, tos), true)
+}
+
+func Test_AddRecipientsTo_Invalid(t *testing.T) {
+	m := &Message{}
+	refute(t, m.AddRecipientsTo([]To{{Email: "bob@example.com"}, {Email: "nope"}}), nil)
+	refute(t, m.AddRecipientsTo([]To{{Email: "bob@example.com", Type: "from"}}), nil)
+	expect(t, len(m.To), 0)
+}
+
+func Test_AddRecipientAddresses(t *testing.T) {
+	m := &Message{}
+	err := m.AddRecipientAddresses([]*mail.Address{{Name: "Bob Johnson", Address: "bob@example.com"}}, "to")
+	expect(t, err, nil)
+	tos := []*To{{"bob@example.com", "Bob Johnson", "to"}}
+	expect(t, reflect.DeepEqual(m.To, tos), true)
+
+	refute(t, m.AddRecipientAddresses([]*mail.Address{nil}, "to"), nil)
+	expect(t, len(m.To), 1)
+}
+
+// SetSendAt //////////
+
+func Test_SetSendAt(t *testing.T) {
+	m := &Message{}
+	m.SetSendAt(time.Date(2024, 3, 1, 20, 30, 0, 0, time.FixedZone("PST", -8*3600)))
+	expect(t, m.SendAt, "2024-03-02 04:30:00")
+
+	payload := newSendPayload(m)
+	expect(t, payload.SendAt, "2024-03-02 04:30:00")
+
+	m.SetSendAt(time.Time{})
+	expect(t, m.SendAt, "")
+}
+
+// Tri-state options //////////
+
+func Test_TriStateOptions(t *testing.T) {
+	m := &Message{}
+	data, _ := json.Marshal(m)
+	for _, key := range []string{"track_opens", "track_clicks", "inline_css", "preserve_recipients", "view_content_link"} {
+		expect(t, strings.Contains(string(data), key), false)
+	}
+
+	m.SetTrackOpens(false)
+	m.SetTrackClicks(true)
+	m.SetInlineCSS(false)
+	m.SetPreserveRecipients(true)
+	m.SetViewContentLink(false)
+
+	var payload map[string]interface{}
+	data, _ = json.Marshal(m)
+	json.Unmarshal(data, &payload)
+	expect(t, payload["track_opens"], false)
+	expect(t, payload["track_clicks"], true)
+	expect(t, payload["inline_css"], false)
+	expect(t, payload["preserve_recipients"], true)
+	expect(t, payload["view_content_link"], false)
+}
+
+func Test_Bool(t *testing.T) {
+	m := &Message{TrackOpens: Bool(false)}
+	expect(t, *m.TrackOpens, false)
+	refute(t, Bool(true), Bool(true))
+}
+
+// SetHTMLTemplate //////////
+
+func Test_SetHTMLTemplate(t *testing.T) {
+	tpl := template.Must(template.New("welcome").Parse(`<p>Hi {{.Name}}</p>`))
+	m := &Message{}
+	err := m.SetHTMLTemplate(tpl, map[string]string{"Name": "<Bob>"})
+	expect(t, err, nil)
+	expect(t, m.HTML, "<p>Hi &lt;Bob&gt;</p>")
+}
+
+func Test_SetHTMLTemplate_Error(t *testing.T) {
+	tpl := template.Must(template.New("welcome").Parse(`<p>{{.Name.Missing}}</p>`))
+	m := &Message{HTML: "<p>before</p>"}
+	err := m.SetHTMLTemplate(tpl, struct{ Name string }{"Bob"})
+	refute(t, err, nil)
+	expect(t, strings.HasPrefix(err.Error(), `mandrill: html template "welcome": `), true)
+	expect(t, m.HTML, "<p>before</p>")
+}
+
+// SetTextTemplate //////////
+
+func Test_SetTextTemplate(t *testing.T) {
+	data := map[string]string{"Name": "<Bob>"}
+	m := &Message{}
+	expect(t, m.SetHTMLTemplate(template.Must(template.New("h").Parse(`<p>Hi {{.Name}}</p>`)), data), nil)
+	expect(t, m.SetTextTemplate(texttemplate.Must(texttemplate.New("t").Parse(`Hi {{.Name}}`)), data), nil)
+	expect(t, m.HTML, "<p>Hi &lt;Bob&gt;</p>")
+	expect(t, m.Text, "Hi <Bob>")
+}
+
+func Test_SetTextTemplate_Error(t *testing.T) {
+	tpl := texttemplate.Must(texttemplate.New("welcome").Parse(`{{.Name.Missing}}`))
+	m := &Message{Text: "before"}
+	err := m.SetTextTemplate(tpl, struct{ Name string }{"Bob"})
+	refute(t, err, nil)
+	expect(t, strings.HasPrefix(err.Error(), `mandrill: text template "welcome": `), true)
+	expect(t, m.Text, "before")
+}
+
+// SetReplyTo //////////
+
+func Test_SetReplyTo(t *testing.T) {
+	m := &Message{}
+	expect(t, m.SetReplyTo("replies@example.com"), nil)
+	expect(t, m.Headers["Reply-To"], "replies@example.com")
+
+	expect(t, m.SetReplyTo("Support <support@example.com>"), nil)
+	expect(t, m.Headers["Reply-To"], "Support <support@example.com>")
+
+	expect(t, m.SetReplyTo(""), nil)
+	_, ok := m.Headers["Reply-To"]
+	expect(t, ok, false)
+}
+
+func Test_SetReplyTo_Invalid(t *testing.T) {
+	m := &Message{}
+	refute(t, m.SetReplyTo("not-an-email"), nil)
+	expect(t, m.Headers == nil, true)
+}
@@ -0,0 +1,423 @@
+package mandrill
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RawSendOptions are the optional parameters of MessagesSendRaw
+type RawSendOptions struct {
+	// enable a background sending mode that is optimized for bulk sending
+	Async bool
+	// the name of the dedicated ip pool that should be used to send the message
+	IPPool string
+	// when this message should be sent as a UTC timestamp in YYYY-MM-DD HH:MM:SS format
+	SendAt string
+	// a custom domain to use for the message's return-path
+	ReturnPathDomain string
+}
+
+// MessagesSendRaw sends a pre-built MIME document through Mandrill untouched.
+// fromEmail, fromName and to override the envelope values found in the
+// document when they are not empty, and are normalized like MessagesSend's
+// addresses. Addresses inside the document itself are sent as they are. opts may be nil.
+func (c *Client) MessagesSendRaw(rawMessage, fromEmail, fromName string, to []string, opts *RawSendOptions) ([]*Response, error) {
+	return c.MessagesSendRawContext(context.Background(), rawMessage, fromEmail, fromName, to, opts)
+}
+
+// MessagesSendRawContext is like MessagesSendRaw but with a context
+func (c *Client) MessagesSendRawContext(ctx context.Context, rawMessage, fromEmail, fromName string, to []string, opts *RawSendOptions) ([]*Response, error) {
+	var data struct {
+		Key              string   `json:"key"`
+		RawMessage       string   `json:"raw_message"`
+		FromEmail        string   `json:"from_email,omitempty"`
+		FromName         string   `json:"from_name,omitempty"`
+		To               []string `json:"to,omitempty"`
+		Async            bool     `json:"async,omitempty"`
+		IPPool           string   `json:"ip_pool,omitempty"`
+		SendAt           string   `json:"send_at,omitempty"`
+		ReturnPathDomain string   `json:"return_path_domain,omitempty"`
+	}
+
+	message := &Message{FromEmail: fromEmail, FromName: fromName}
+	for _, email := range to {
+		message.To = append(message.To, &To{Email: email, Type: "to"})
+	}
+	if err := message.normalizeAddresses(); err != nil {
+		return nil, err
+	}
+
+	data.Key = c.Key
+	data.RawMessage = rawMessage
+	data.FromEmail = message.FromEmail
+	data.FromName = fromName
+	for _, recipient := range message.To {
+		data.To = append(data.To, recipient.Email)
+	}
+	if opts != nil {
+		data.Async = opts.Async
+		data.IPPool = opts.IPPool
+		data.SendAt = opts.SendAt
+		data.ReturnPathDomain = opts.ReturnPathDomain
+	}
+
+	return c.sendMessagePayload(ctx, message, data, "messages/send-raw.json")
+}
+
+type parsedAttachment struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Binary  bool   `json:"binary"`
+	Content string `json:"content"`
+}
+
+type parsedMessage struct {
+	Subject     string                 `json:"subject"`
+	FromEmail   string                 `json:"from_email"`
+	FromName    string                 `json:"from_name"`
+	To          []*To                  `json:"to"`
+	Headers     map[string]interface{} `json:"headers"`
+	Text        string                 `json:"text"`
+	HTML        string                 `json:"html"`
+	Attachments []*parsedAttachment    `json:"attachments"`
+	Images      []*parsedAttachment    `json:"images"`
+}
+
+// MessagesParse parses a raw MIME document into a Message with its headers,
+// attachments and images populated, e.g. for re-sending archived emails.
+// Headers that appear more than once keep their first value.
+func (c *Client) MessagesParse(rawMIME string) (*Message, error) {
+	return c.MessagesParseContext(context.Background(), rawMIME)
+}
+
+// MessagesParseContext is like MessagesParse but with a context
+func (c *Client) MessagesParseContext(ctx context.Context, rawMIME string) (*Message, error) {
+	var data struct {
+		Key        string `json:"key"`
+		RawMessage string `json:"raw_message"`
+	}
+
+	data.Key = c.Key
+	data.RawMessage = rawMIME
+
+	parsed := &parsedMessage{}
+	if err := c.call(ctx, "messages/parse.json", data, parsed); err != nil {
+		return nil, err
+	}
+
+	message := &Message{
+		Subject:     parsed.Subject,
+		FromEmail:   parsed.FromEmail,
+		FromName:    parsed.FromName,
+		To:          parsed.To,
+		Text:        parsed.Text,
+		HTML:        parsed.HTML,
+		Attachments: convertParsedAttachments(parsed.Attachments),
+		Images:      convertParsedAttachments(parsed.Images),
+	}
+
+	message.Headers = flattenHeaders(parsed.Headers)
+
+	return message, nil
+}
+
+// flattenHeaders keeps the first value of headers that appear more than once
+func flattenHeaders(raw map[string]interface{}) map[string]string {
+	if len(raw) == 0 {
+		return nil
+	}
+	headers := make(map[string]string, len(raw))
+	for name, value := range raw {
+		switch v := value.(type) {
+		case string:
+			headers[name] = v
+		case []interface{}:
+			if len(v) > 0 {
+				headers[name] = fmt.Sprint(v[0])
+			}
+		default:
+			headers[name] = fmt.Sprint(v)
+		}
+	}
+	return headers
+}
+
+// convertParsedAttachments base64 encodes plain text parts so every
+// Attachment carries base64 content, as messages/send expects
+func convertParsedAttachments(parsed []*parsedAttachment) []*Attachment {
+	if len(parsed) == 0 {
+		return nil
+	}
+	attachments := make([]*Attachment, len(parsed))
+	for i, p := range parsed {
+		content := p.Content
+		if !p.Binary {
+			content = base64.StdEncoding.EncodeToString([]byte(content))
+		}
+		attachments[i] = &Attachment{Type: p.Type, Name: p.Name, Content: content}
+	}
+	return attachments
+}
+
+// MessageInfo is the delivery history of a sent message
+type MessageInfo struct {
+	// the Unix timestamp from when this message was sent
+	Ts Time `json:"ts"`
+	// the message's unique id
+	ID string `json:"_id"`
+	// the email address of the sender
+	Sender string `json:"sender"`
+	// the unique name of the template used, if any
+	Template string `json:"template"`
+	// the message's subject line
+	Subject string `json:"subject"`
+	// the recipient email address
+	Email string `json:"email"`
+	// list of tags on this message
+	Tags []string `json:"tags"`
+	// how many times has this message been opened
+	Opens int `json:"opens"`
+	// list of individual opens for the message
+	OpensDetail []*MessageOpen `json:"opens_detail"`
+	// how many times has a link been clicked in this message
+	Clicks int `json:"clicks"`
+	// list of individual clicks for the message
+	ClicksDetail []*MessageClick `json:"clicks_detail"`
+	// sending status of this message: sent, bounced, rejected
+	State string `json:"state"`
+	// any custom metadata provided when the message was sent
+	Metadata map[string]string `json:"metadata"`
+	// a log of up to 3 smtp events for the message
+	SMTPEvents []*SMTPEvent `json:"smtp_events"`
+}
+
+// MessageOpen is a single open of a message
+type MessageOpen struct {
+	// the unix timestamp from when the message was opened
+	Ts Time `json:"ts"`
+	// the IP address that generated the open
+	IP string `json:"ip"`
+	// the approximate region and country that the opening IP is located
+	Location string `json:"location"`
+	// the email client or browser data of the open
+	UA string `json:"ua"`
+}
+
+// MessageClick is a single click on a link in a message
+type MessageClick struct {
+	// the unix timestamp from when the message was clicked
+	Ts Time `json:"ts"`
+	// the URL that was clicked on
+	URL string `json:"url"`
+	// the IP address that generated the click
+	IP string `json:"ip"`
+	// the approximate region and country that the clicking IP is located
+	Location string `json:"location"`
+	// the email client or browser data of the click
+	UA string `json:"ua"`
+}
+
+// SMTPEvent is a delivery attempt of a message
+type SMTPEvent struct {
+	// the Unix timestamp when the event occurred
+	Ts Time `json:"ts"`
+	// the message's state as a result of this event
+	Type string `json:"type"`
+	// the SMTP response from the recipient's server
+	Diag string `json:"diag"`
+}
+
+// MessagesInfo returns the delivery history of a recently sent message
+func (c *Client) MessagesInfo(id string) (*MessageInfo, error) {
+	return c.MessagesInfoContext(context.Background(), id)
+}
+
+// MessagesInfoContext is like MessagesInfo but with a context
+func (c *Client) MessagesInfoContext(ctx context.Context, id string) (info *MessageInfo, err error) {
+	var data struct {
+		Key string `json:"key"`
+		ID  string `json:"id"`
+	}
+
+	data.Key = c.Key
+	data.ID = id
+
+	err = c.call(ctx, "messages/info.json", data, &info)
+	return info, err
+}
+
+// WaitForDelivery polls MessagesInfo every interval until the message reaches a final
+// state ("sent", "bounced" or "rejected") and returns that state. A zero interval uses
+// Client.PollInterval. Messages Mandrill doesn't know about yet (Unknown_Message) are
+// polled again, since sent messages take a moment to become searchable. It returns
+// ctx.Err() once ctx is done, so give it a deadline.
+func (c *Client) WaitForDelivery(ctx context.Context, id string, interval time.Duration) (state string, err error) {
+	if interval <= 0 {
+		interval = c.PollInterval
+	}
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		info, err := c.MessagesInfoContext(ctx, id)
+		if err != nil {
+			if apiErr, ok := err.(*Error); !ok || apiErr.Name != "Unknown_Message" {
+				return state, err
+			}
+		} else if info != nil {
+			switch info.State {
+			case "sent", "bounced", "rejected":
+				return info.State, nil
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return state, ctx.Err()
+		}
+	}
+}
+
+// MessageContent is the stored content of a recently sent message
+type MessageContent struct {
+	// the Unix timestamp from when this message was sent
+	Ts Time `json:"ts"`
+	// the message's unique id
+	ID string `json:"_id"`
+	// the email address of the sender
+	FromEmail string `json:"from_email"`
+	// the alias of the sender (if any)
+	FromName string `json:"from_name"`
+	// the message's subject line
+	Subject string `json:"subject"`
+	// the message recipient's information
+	To *To `json:"to"`
+	// list of tags on this message
+	Tags []string `json:"tags"`
+	// the key-value pairs of the custom MIME headers for the message's main document.
+	// Headers that appear more than once keep their first value.
+	Headers map[string]string `json:"-"`
+	// the text part of the message, if any
+	Text string `json:"text"`
+	// the HTML part of the message, if any
+	HTML string `json:"html"`
+	// an array of any attachments that can be found in the message, with base64 encoded content
+	Attachments []*Attachment `json:"attachments"`
+}
+
+// UnmarshalJSON flattens the message's raw headers into Headers
+func (content *MessageContent) UnmarshalJSON(b []byte) error {
+	type plain MessageContent
+	var raw struct {
+		*plain
+		Headers map[string]interface{} `json:"headers"`
+	}
+	raw.plain = (*plain)(content)
+	// a mistyped field still decodes the rest, which tolerant decoding keeps, so keep the headers too
+	err := json.Unmarshal(b, &raw)
+	content.Headers = flattenHeaders(raw.Headers)
+	return err
+}
+
+// MessagesContent returns the full content of a recently sent message, e.g. for audits or resending
+func (c *Client) MessagesContent(id string) (*MessageContent, error) {
+	return c.MessagesContentContext(context.Background(), id)
+}
+
+// MessagesContentContext is like MessagesContent but with a context
+func (c *Client) MessagesContentContext(ctx context.Context, id string) (content *MessageContent, err error) {
+	var data struct {
+		Key string `json:"key"`
+		ID  string `json:"id"`
+	}
+
+	data.Key = c.Key
+	data.ID = id
+
+	err = c.call(ctx, "messages/content.json", data, &content)
+	return content, err
+}
+
+// ScheduledMessage is a message waiting in the scheduled queue
+type ScheduledMessage struct {
+	// the scheduled message id
+	ID string `json:"_id"`
+	// the UTC timestamp when the message was created
+	CreatedAt Time `json:"created_at"`
+	// the UTC timestamp when the message will be sent
+	SendAt Time `json:"send_at"`
+	// the email's sender address
+	FromEmail string `json:"from_email"`
+	// the email's recipient
+	To string `json:"to"`
+	// the email's subject
+	Subject string `json:"subject"`
+}
+
+// MessagesListScheduled returns the scheduled emails. toEmail optionally
+// limits the results to messages sent to that address.
+func (c *Client) MessagesListScheduled(toEmail string) ([]*ScheduledMessage, error) {
+	return c.MessagesListScheduledContext(context.Background(), toEmail)
+}
+
+// MessagesListScheduledContext is like MessagesListScheduled but with a context
+func (c *Client) MessagesListScheduledContext(ctx context.Context, toEmail string) (messages []*ScheduledMessage, err error) {
+	var data struct {
+		Key string `json:"key"`
+		To  string `json:"to,omitempty"`
+	}
+
+	data.Key = c.Key
+	data.To = toEmail
+
+	err = c.call(ctx, "messages/list-scheduled.json", data, &messages)
+	return messages, err
+}
+
+// MessagesCancelScheduled removes a scheduled message from the queue and returns it
+func (c *Client) MessagesCancelScheduled(id string) (*ScheduledMessage, error) {
+	return c.MessagesCancelScheduledContext(context.Background(), id)
+}
+
+// MessagesCancelScheduledContext is like MessagesCancelScheduled but with a context
+func (c *Client) MessagesCancelScheduledContext(ctx context.Context, id string) (message *ScheduledMessage, err error) {
+	var data struct {
+		Key string `json:"key"`
+		ID  string `json:"id"`
+	}
+
+	data.Key = c.Key
+	data.ID = id
+
+	err = c.call(ctx, "messages/cancel-scheduled.json", data, &message)
+	return message, err
+}
+
+// MessagesReschedule changes the send time of a scheduled message and returns it
+func (c *Client) MessagesReschedule(id string, sendAt time.Time) (*ScheduledMessage, error) {
+	return c.MessagesRescheduleContext(context.Background(), id, sendAt)
+}
+
+// MessagesRescheduleContext is like MessagesReschedule but with a context
+func (c *Client) MessagesRescheduleContext(ctx context.Context, id string, sendAt time.Time) (message *ScheduledMessage, err error) {
+	var data struct {
+		Key    string `json:"key"`
+		ID     string `json:"id"`
+		SendAt string `json:"send_at"`
+	}
+
+	data.Key = c.Key
+	data.ID = id
+	data.SendAt = sendAt.UTC().Format(TimestampFormat)
+
+	err = c.call(ctx, "messages/reschedule.json", data, &message)
+	return message, err
+}
@@ -0,0 +1,287 @@
+package mandrill
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const rawMIME = "From: sender@example.com\r\nTo: bob@example.com\r\nSubject: Receipt\r\n\r\nThanks!"
+
+// Send raw //////////
+
+func Test_MessagesSendRaw(t *testing.T) {
+	server, c, req := testRecorder(200, `[{"email":"bob@example.com","status":"sent","_id":"1"}]`)
+	defer server.Close()
+
+	responses, err := c.MessagesSendRaw(rawMIME, "sender@example.com", "Sender", []string{"bob@example.com"}, &RawSendOptions{IPPool: "Main Pool"})
+	expect(t, err, nil)
+	expect(t, req.Path, "/messages/send-raw.json")
+	expect(t, req.Payload["raw_message"], rawMIME)
+	expect(t, req.Payload["from_name"], "Sender")
+	expect(t, reflect.DeepEqual(req.Payload["to"], []interface{}{"bob@example.com"}), true)
+	expect(t, req.Payload["ip_pool"], "Main Pool")
+	expect(t, responses[0].Status, "sent")
+}
+
+func Test_MessagesSendRaw_NoOptions(t *testing.T) {
+	server, c, req := testRecorder(200, `[]`)
+	defer server.Close()
+
+	_, err := c.MessagesSendRaw(rawMIME, "", "", nil, nil)
+	expect(t, err, nil)
+	_, ok := req.Payload["from_email"]
+	expect(t, ok, false)
+	_, ok = req.Payload["async"]
+	expect(t, ok, false)
+}
+
+func Test_MessagesSendRaw_UnknownStatus(t *testing.T) {
+	server, c, _ := testRecorder(200, `[{"email":"bob@example.com","status":"bogus","_id":"1"}]`)
+	defer server.Close()
+	c.StrictStatuses = true
+
+	_, err := c.MessagesSendRaw(rawMIME, "", "", nil, nil)
+	refute(t, err, nil)
+}
+
+// Parse //////////
+
+func Test_MessagesParse(t *testing.T) {
+	server, c, req := testRecorder(200, `{
+		"subject": "Some Subject",
+		"from_email": "sender@example.com",
+		"from_name": "Sender Name",
+		"to": [{"email": "recipient.email@example.com", "name": "Recipient Name"}],
+		"headers": {"Reply-To": "replies@example.com", "Received": ["one", "two"]},
+		"text": "Some text content",
+		"html": "<p>Some HTML content</p>",
+		"attachments": [
+			{"name": "notes.txt", "type": "text/plain", "binary": false, "content": "hello"},
+			{"name": "logo.gif", "type": "image/gif", "binary": true, "content": "R0lGODlh"}
+		],
+		"images": [{"name": "IMAGEID", "type": "image/png", "content": "iVBORw0K"}]
+	}`)
+	defer server.Close()
+
+	message, err := c.MessagesParse(rawMIME)
+	expect(t, err, nil)
+	expect(t, req.Path, "/messages/parse.json")
+	expect(t, req.Payload["raw_message"], rawMIME)
+	expect(t, message.Subject, "Some Subject")
+	expect(t, message.FromName, "Sender Name")
+	expect(t, message.To[0].Email, "recipient.email@example.com")
+	expect(t, message.Headers["Reply-To"], "replies@example.com")
+	expect(t, message.Headers["Received"], "one")
+	expect(t, message.HTML, "<p>Some HTML content</p>")
+	expect(t, message.Attachments[0].Content, "aGVsbG8=")
+	expect(t, message.Attachments[1].Content, "R0lGODlh")
+	expect(t, message.Images[0].Name, "IMAGEID")
+}
+
+func Test_MessagesParse_Error(t *testing.T) {
+	server, c, _ := testRecorder(400, `{"status":"error","code":-1,"name":"ValidationError","message":"bad MIME"}`)
+	defer server.Close()
+
+	message, err := c.MessagesParse("nope")
+	refute(t, err, nil)
+	expect(t, message, (*Message)(nil))
+}
+
+// Info //////////
+
+const messageInfoJSON = `{
+	"ts": 1365190000,
+	"_id": "abc123abc123abc123abc123",
+	"sender": "sender@example.com",
+	"template": "example-template",
+	"subject": "example subject",
+	"email": "recipient.email@example.com",
+	"tags": ["password-reset"],
+	"opens": 1,
+	"opens_detail": [{"ts": 1365190001, "ip": "55.55.55.55", "location": "Georgia, US", "ua": "Linux/Ubuntu/Chrome/Chrome 28.0.1500.53"}],
+	"clicks": 1,
+	"clicks_detail": [{"ts": 1365190001, "url": "http://www.example.com", "ip": "55.55.55.55", "location": "Georgia, US", "ua": "Linux/Ubuntu/Chrome/Chrome 28.0.1500.53"}],
+	"state": "sent",
+	"metadata": {"user_id": "123"},
+	"smtp_events": [{"ts": 1365190001, "type": "sent", "diag": "250 OK"}]
+}`
+
+func Test_MessagesInfo(t *testing.T) {
+	server, c, req := testRecorder(200, messageInfoJSON)
+	defer server.Close()
+
+	info, err := c.MessagesInfo("abc123abc123abc123abc123")
+	expect(t, err, nil)
+	expect(t, req.Path, "/messages/info.json")
+	expect(t, req.Payload["id"], "abc123abc123abc123abc123")
+	expect(t, info.Ts.Unix(), int64(1365190000))
+	expect(t, info.State, "sent")
+	expect(t, info.OpensDetail[0].Location, "Georgia, US")
+	expect(t, info.ClicksDetail[0].URL, "http://www.example.com")
+	expect(t, info.SMTPEvents[0].Diag, "250 OK")
+	expect(t, info.Metadata["user_id"], "123")
+}
+
+// WaitForDelivery //////////
+
+func Test_WaitForDelivery(t *testing.T) {
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&polls, 1) {
+		case 1:
+			w.WriteHeader(500)
+			fmt.Fprintln(w, `{"status":"error","code":11,"name":"Unknown_Message","message":"No message exists with the id 'abc'"}`)
+		case 2:
+			fmt.Fprintln(w, `{"_id":"abc","state":"deferred"}`)
+		default:
+			fmt.Fprintln(w, `{"_id":"abc","state":"bounced"}`)
+		}
+	}))
+	defer server.Close()
+
+	c := ClientWithKey("APIKEY")
+	c.BaseURL = server.URL + "/"
+
+	state, err := c.WaitForDelivery(context.Background(), "abc", time.Millisecond)
+	expect(t, err, nil)
+	expect(t, state, "bounced")
+	expect(t, atomic.LoadInt32(&polls), int32(3))
+}
+
+func Test_WaitForDelivery_Error(t *testing.T) {
+	server, c, _ := testRecorder(500, `{"status":"error","code":-1,"name":"Invalid_Key","message":"Invalid API key"}`)
+	defer server.Close()
+
+	_, err := c.WaitForDelivery(context.Background(), "abc", time.Millisecond)
+	expect(t, err.(*Error).Name, "Invalid_Key")
+}
+
+func Test_WaitForDelivery_Deadline(t *testing.T) {
+	server, c, _ := testRecorder(200, `{"_id":"abc","state":"deferred"}`)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	state, err := c.WaitForDelivery(ctx, "abc", time.Hour)
+	expect(t, err, context.DeadlineExceeded)
+	expect(t, state, "")
+}
+
+// Content //////////
+
+func Test_MessagesContent(t *testing.T) {
+	server, c, req := testRecorder(200, `{
+		"ts": 1365190000,
+		"_id": "abc123abc123abc123abc123",
+		"from_email": "sender@example.com",
+		"from_name": "Sender Name",
+		"subject": "example subject",
+		"to": {"email": "recipient.email@example.com", "name": "Recipient Name"},
+		"tags": ["password-reset"],
+		"headers": {"Reply-To": "replies@example.com", "Received": ["one", "two"]},
+		"text": "Some text content",
+		"html": "<p>Some HTML content</p>",
+		"attachments": [{"name": "example.txt", "type": "text/plain", "content": "ZXhhbXBsZSBmaWxl"}]
+	}`)
+	defer server.Close()
+
+	content, err := c.MessagesContent("abc123abc123abc123abc123")
+	expect(t, err, nil)
+	expect(t, req.Path, "/messages/content.json")
+	expect(t, req.Payload["id"], "abc123abc123abc123abc123")
+	expect(t, content.ID, "abc123abc123abc123abc123")
+	expect(t, content.To.Name, "Recipient Name")
+	expect(t, content.Headers["Reply-To"], "replies@example.com")
+	expect(t, content.Headers["Received"], "one")
+	expect(t, content.HTML, "<p>Some HTML content</p>")
+	expect(t, content.Attachments[0].Content, "ZXhhbXBsZSBmaWxl")
+}
+
+func Test_MessagesContent_MistypedField(t *testing.T) {
+	server, c, _ := testRecorder(200, `{
+		"_id": "abc123abc123abc123abc123",
+		"subject": 123,
+		"headers": {"Reply-To": "replies@example.com"},
+		"text": "Some text content"
+	}`)
+	defer server.Close()
+
+	content, err := c.MessagesContent("abc123abc123abc123abc123")
+	expect(t, err, nil)
+	expect(t, content.Subject, "")
+	expect(t, content.Text, "Some text content")
+	expect(t, content.Headers["Reply-To"], "replies@example.com")
+}
+
+// Scheduled //////////
+
+const scheduledMessageJSON = `{
+	"_id": "I_dtFt2ZNPW5QD9-FaDU1A",
+	"created_at": "2013-01-20 12:13:01",
+	"send_at": "2021-01-05 12:42:01",
+	"from_email": "sender@example.com",
+	"to": "test.recipient@example.com",
+	"subject": "This is a scheduled email"
+}`
+
+func Test_MessagesListScheduled(t *testing.T) {
+	server, c, req := testRecorder(200, "["+scheduledMessageJSON+"]")
+	defer server.Close()
+
+	messages, err := c.MessagesListScheduled("test.recipient@example.com")
+	expect(t, err, nil)
+	expect(t, req.Path, "/messages/list-scheduled.json")
+	expect(t, req.Payload["to"], "test.recipient@example.com")
+	expect(t, messages[0].ID, "I_dtFt2ZNPW5QD9-FaDU1A")
+	expect(t, messages[0].SendAt.Equal(time.Date(2021, 1, 5, 12, 42, 1, 0, time.UTC)), true)
+}
+
+func Test_MessagesListScheduled_All(t *testing.T) {
+	server, c, req := testRecorder(200, "[]")
+	defer server.Close()
+
+	_, err := c.MessagesListScheduled("")
+	expect(t, err, nil)
+	_, ok := req.Payload["to"]
+	expect(t, ok, false)
+}
+
+func Test_MessagesCancelScheduled(t *testing.T) {
+	server, c, req := testRecorder(200, scheduledMessageJSON)
+	defer server.Close()
+
+	message, err := c.MessagesCancelScheduled("I_dtFt2ZNPW5QD9-FaDU1A")
+	expect(t, err, nil)
+	expect(t, req.Path, "/messages/cancel-scheduled.json")
+	expect(t, req.Payload["id"], "I_dtFt2ZNPW5QD9-FaDU1A")
+	expect(t, message.To, "test.recipient@example.com")
+}
+
+func Test_MessagesCancelScheduled_Unknown(t *testing.T) {
+	server, c, _ := testRecorder(500, `{"status":"error","code":12,"name":"Unknown_Message","message":"No message exists with the id 'nope'"}`)
+	defer server.Close()
+
+	message, err := c.MessagesCancelScheduled("nope")
+	refute(t, err, nil)
+	expect(t, message, (*ScheduledMessage)(nil))
+	expect(t, err.(*Error).Name, "Unknown_Message")
+}
+
+func Test_MessagesReschedule(t *testing.T) {
+	server, c, req := testRecorder(200, scheduledMessageJSON)
+	defer server.Close()
+
+	sendAt := time.Date(2021, 1, 5, 7, 42, 1, 0, time.FixedZone("EST", -5*60*60))
+	message, err := c.MessagesReschedule("I_dtFt2ZNPW5QD9-FaDU1A", sendAt)
+	expect(t, err, nil)
+	expect(t, req.Path, "/messages/reschedule.json")
+	expect(t, req.Payload["id"], "I_dtFt2ZNPW5QD9-FaDU1A")
+	expect(t, req.Payload["send_at"], "2021-01-05 12:42:01")
+	expect(t, message.SendAt.Equal(sendAt), true)
+}
@@ -0,0 +1,78 @@
+package mandrill
+
+import (
+	"context"
+)
+
+// MetadataField is a custom metadata field indexed for the account
+type MetadataField struct {
+	// the unique identifier of the metadata field to update
+	Name string `json:"name"`
+	// the current state of the metadata field, one of "active", "delete", or "index"
+	State string `json:"state"`
+	// Mustache template to control how the metadata is rendered in your activity log
+	ViewTemplate string `json:"view_template"`
+}
+
+// MetadataList returns the custom metadata fields indexed for the account
+func (c *Client) MetadataList() ([]*MetadataField, error) {
+	return c.MetadataListContext(context.Background())
+}
+
+// MetadataListContext is like MetadataList but with a context
+func (c *Client) MetadataListContext(ctx context.Context) (fields []*MetadataField, err error) {
+	var data struct {
+		Key string `json:"key"`
+	}
+
+	data.Key = c.Key
+
+	err = c.call(ctx, "metadata/list.json", data, &fields)
+	return fields, err
+}
+
+// MetadataAdd adds a new custom metadata field to be indexed for the account
+func (c *Client) MetadataAdd(name string, viewTemplate string) (*MetadataField, error) {
+	return c.MetadataAddContext(context.Background(), name, viewTemplate)
+}
+
+// MetadataAddContext is like MetadataAdd but with a context
+func (c *Client) MetadataAddContext(ctx context.Context, name string, viewTemplate string) (*MetadataField, error) {
+	return c.metadataCall(ctx, "metadata/add.json", name, viewTemplate)
+}
+
+// MetadataUpdate updates an existing custom metadata field's view template
+func (c *Client) MetadataUpdate(name string, viewTemplate string) (*MetadataField, error) {
+	return c.MetadataUpdateContext(context.Background(), name, viewTemplate)
+}
+
+// MetadataUpdateContext is like MetadataUpdate but with a context
+func (c *Client) MetadataUpdateContext(ctx context.Context, name string, viewTemplate string) (*MetadataField, error) {
+	return c.metadataCall(ctx, "metadata/update.json", name, viewTemplate)
+}
+
+// MetadataDelete deletes an existing custom metadata field. Deletion isn't instantaneous,
+// and the field's state will be "delete" until it is removed.
+func (c *Client) MetadataDelete(name string) (*MetadataField, error) {
+	return c.MetadataDeleteContext(context.Background(), name)
+}
+
+// MetadataDeleteContext is like MetadataDelete but with a context
+func (c *Client) MetadataDeleteContext(ctx context.Context, name string) (*MetadataField, error) {
+	return c.metadataCall(ctx, "metadata/delete.json", name, "")
+}
+
+func (c *Client) metadataCall(ctx context.Context, path string, name string, viewTemplate string) (field *MetadataField, err error) {
+	var data struct {
+		Key          string `json:"key"`
+		Name         string `json:"name"`
+		ViewTemplate string `json:"view_template,omitempty"`
+	}
+
+	data.Key = c.Key
+	data.Name = name
+	data.ViewTemplate = viewTemplate
+
+	err = c.call(ctx, path, data, &field)
+	return field, err
+}
@@ -0,0 +1,49 @@
+package mandrill
+
+import (
+	"testing"
+)
+
+const metadataJSON = `{"name":"group_id","state":"active","view_template":"<a href=\"http://example.com/group/{{value}}\">{{value}}</a>"}`
+
+// Metadata //////////
+
+func Test_MetadataList(t *testing.T) {
+	server, c, req := testRecorder(200, "["+metadataJSON+"]")
+	defer server.Close()
+
+	fields, err := c.MetadataList()
+	expect(t, err, nil)
+	expect(t, req.Path, "/metadata/list.json")
+	expect(t, fields[0].Name, "group_id")
+	expect(t, fields[0].State, "active")
+}
+
+func Test_MetadataAddUpdate(t *testing.T) {
+	server, c, req := testRecorder(200, metadataJSON)
+	defer server.Close()
+
+	field, err := c.MetadataAdd("group_id", "{{value}}")
+	expect(t, err, nil)
+	expect(t, req.Path, "/metadata/add.json")
+	expect(t, req.Payload["name"], "group_id")
+	expect(t, req.Payload["view_template"], "{{value}}")
+	expect(t, field.ViewTemplate, `<a href="http://example.com/group/{{value}}">{{value}}</a>`)
+
+	_, err = c.MetadataUpdate("group_id", "<b>{{value}}</b>")
+	expect(t, err, nil)
+	expect(t, req.Path, "/metadata/update.json")
+	expect(t, req.Payload["view_template"], "<b>{{value}}</b>")
+}
+
+func Test_MetadataDelete(t *testing.T) {
+	server, c, req := testRecorder(200, `{"name":"group_id","state":"delete","view_template":""}`)
+	defer server.Close()
+
+	field, err := c.MetadataDelete("group_id")
+	expect(t, err, nil)
+	expect(t, req.Path, "/metadata/delete.json")
+	_, hasTemplate := req.Payload["view_template"]
+	expect(t, hasTemplate, false)
+	expect(t, field.State, "delete")
+}
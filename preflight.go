@@ -0,0 +1,187 @@
+package mandrill
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+)
+
+// overridden in tests
+var lookupTXT = net.DefaultResolver.LookupTXT
+
+// DNSRecord is a DNS record to publish for a sending domain
+type DNSRecord struct {
+	// the record type, e.g. "TXT" or "CNAME"
+	Type string
+	// the fully qualified record name, e.g. "_dmarc.example.com"
+	Name string
+	// the record value
+	Value string
+}
+
+// MandrillDKIMRecords are the records that delegate a domain's DKIM keys to Mandrill.
+// Names are relative to the sending domain. Check them against Mandrill's sending
+// domain settings if Mandrill changes its setup.
+var MandrillDKIMRecords = []DNSRecord{
+	{Type: "CNAME", Name: "mte1._domainkey", Value: "dkim1.mandrillapp.com"},
+	{Type: "CNAME", Name: "mte2._domainkey", Value: "dkim2.mandrillapp.com"},
+}
+
+// mandrillSPFInclude is the SPF mechanism that authorizes Mandrill's servers
+const mandrillSPFInclude = "include:spf.mandrillapp.com"
+
+// DomainCheck is the outcome of one of Preflight's checks
+type DomainCheck struct {
+	// whether the domain is set up correctly
+	OK bool
+	// what's wrong, if not OK
+	Problem string
+	// the records to add or replace, if not OK
+	Remediation []DNSRecord
+}
+
+// PreflightReport is the outcome of Preflight
+type PreflightReport struct {
+	// the domain that was checked
+	Domain string
+	// the result of Mandrill's senders/check-domain call
+	SenderDomain *SenderDomain
+	// whether the domain's SPF record authorizes Mandrill
+	SPF *DomainCheck
+	// whether Mandrill can sign the domain's mail with DKIM
+	DKIM *DomainCheck
+	// whether the domain publishes a DMARC policy that Mandrill's mail aligns with
+	DMARC *DomainCheck
+}
+
+// OK reports whether every check passed
+func (r *PreflightReport) OK() bool {
+	return r.SPF.OK && r.DKIM.OK && r.DMARC.OK
+}
+
+// Preflight checks that a from domain is ready to send through Mandrill, e.g.
+// before enabling a new customer's sending domain. It calls senders/check-domain,
+// which adds the domain to the account if needed, and looks up the domain's SPF
+// and DMARC records. Problems come with the records that fix them.
+func (c *Client) Preflight(ctx context.Context, domain string) (report *PreflightReport, err error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+
+	sender, err := c.SendersCheckDomainContext(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	if sender == nil {
+		sender = &SenderDomain{Domain: domain}
+	}
+
+	spf, err := lookupRecords(ctx, domain, "v=spf1")
+	if err != nil {
+		return nil, err
+	}
+	dmarc, err := lookupRecords(ctx, "_dmarc."+domain, "v=DMARC1")
+	if err != nil {
+		return nil, err
+	}
+
+	report = &PreflightReport{Domain: domain, SenderDomain: sender}
+	report.SPF = checkSPF(domain, sender.SPF, spf)
+	report.DKIM = checkDKIM(domain, sender.DKIM)
+	report.DMARC = checkDMARC(domain, report.DKIM.OK, dmarc)
+	return report, nil
+}
+
+// lookupRecords returns name's TXT records starting with prefix. A name without records isn't an error.
+func lookupRecords(ctx context.Context, name, prefix string) ([]string, error) {
+	txts, err := lookupTXT(ctx, name)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []string
+	for _, txt := range txts {
+		if strings.HasPrefix(strings.ToLower(txt), strings.ToLower(prefix)) {
+			records = append(records, txt)
+		}
+	}
+	return records, nil
+}
+
+func checkSPF(domain string, mandrill *DomainRecord, records []string) *DomainCheck {
+	fix := func(problem, value string) *DomainCheck {
+		return &DomainCheck{Problem: problem, Remediation: []DNSRecord{{Type: "TXT", Name: domain, Value: value}}}
+	}
+
+	switch {
+	case len(records) == 0:
+		return fix("no SPF record", "v=spf1 "+mandrillSPFInclude+" ~all")
+	case len(records) > 1:
+		return fix("more than one SPF record; receivers treat that as an error", mergeSPF(records))
+	case !strings.Contains(strings.ToLower(records[0]), mandrillSPFInclude):
+		return fix("SPF record doesn't include Mandrill", mergeSPF(records))
+	case mandrill != nil && !mandrill.Valid && mandrill.Error != "":
+		return &DomainCheck{Problem: mandrill.Error}
+	}
+	return &DomainCheck{OK: true}
+}
+
+// mergeSPF combines SPF records into one that also includes Mandrill, keeping the first record's all mechanism
+func mergeSPF(records []string) string {
+	terms := []string{"v=spf1"}
+	seen := map[string]bool{}
+	all := "~all"
+	for i, record := range records {
+		for _, term := range strings.Fields(record)[1:] {
+			lower := strings.ToLower(term)
+			if strings.HasSuffix(lower, "all") && len(lower) <= 4 {
+				if i == 0 {
+					all = term
+				}
+				continue
+			}
+			if !seen[lower] {
+				seen[lower] = true
+				terms = append(terms, term)
+			}
+		}
+	}
+	if !seen[mandrillSPFInclude] {
+		terms = append(terms, mandrillSPFInclude)
+	}
+	return strings.Join(append(terms, all), " ")
+}
+
+func checkDKIM(domain string, mandrill *DomainRecord) *DomainCheck {
+	if mandrill != nil && mandrill.Valid {
+		return &DomainCheck{OK: true}
+	}
+
+	check := &DomainCheck{Problem: "Mandrill can't sign the domain's mail with DKIM"}
+	if mandrill != nil && mandrill.Error != "" {
+		check.Problem = mandrill.Error
+	}
+	for _, record := range MandrillDKIMRecords {
+		record.Name += "." + domain
+		check.Remediation = append(check.Remediation, record)
+	}
+	return check
+}
+
+func checkDMARC(domain string, dkimOK bool, records []string) *DomainCheck {
+	record := DNSRecord{Type: "TXT", Name: "_dmarc." + domain, Value: "v=DMARC1; p=none; rua=mailto:dmarc-reports@" + domain}
+
+	switch {
+	case len(records) == 0:
+		return &DomainCheck{Problem: "no DMARC record", Remediation: []DNSRecord{record}}
+	case len(records) > 1:
+		return &DomainCheck{Problem: "more than one DMARC record; receivers ignore them all", Remediation: []DNSRecord{record}}
+	case !dkimOK:
+		// Mandrill's return path is its own domain, so only DKIM can align with the From domain
+		return &DomainCheck{Problem: "mail won't align with DMARC until DKIM is set up"}
+	}
+	return &DomainCheck{OK: true}
+}
@@ -0,0 +1,86 @@
+package mandrill
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func fakeDNS(t *testing.T, records map[string][]string) {
+	lookupTXT = func(ctx context.Context, name string) ([]string, error) {
+		txts, ok := records[name]
+		if !ok {
+			return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+		}
+		return txts, nil
+	}
+	t.Cleanup(func() { lookupTXT = net.DefaultResolver.LookupTXT })
+}
+
+// Preflight //////////
+
+func Test_Preflight(t *testing.T) {
+	fakeDNS(t, map[string][]string{
+		"example.com":        {"google-site-verification=abc", "v=spf1 include:_spf.google.com include:spf.mandrillapp.com -all"},
+		"_dmarc.example.com": {"v=DMARC1; p=quarantine"},
+	})
+	server, c, req := testRecorder(200, `{"domain":"example.com","spf":{"valid":true},"dkim":{"valid":true},"valid_signing":true}`)
+	defer server.Close()
+
+	report, err := c.Preflight(context.Background(), "Example.com.")
+	expect(t, err, nil)
+	expect(t, req.Path, "/senders/check-domain.json")
+	expect(t, req.Payload["domain"], "example.com")
+	expect(t, report.SenderDomain.ValidSigning, true)
+	expect(t, report.OK(), true)
+}
+
+func Test_Preflight_Remediation(t *testing.T) {
+	fakeDNS(t, map[string][]string{
+		"example.com": {"v=spf1 include:_spf.google.com -all", "v=spf1 mx ~all"},
+	})
+	server, c := testTools(200, `{"domain":"example.com","spf":{"valid":false,"error":"spf.mandrillapp.com not included"},"dkim":{"valid":false,"error":"no DKIM record found"}}`)
+	defer server.Close()
+
+	report, err := c.Preflight(context.Background(), "example.com")
+	expect(t, err, nil)
+	expect(t, report.OK(), false)
+
+	expect(t, report.SPF.Problem, "more than one SPF record; receivers treat that as an error")
+	expect(t, fmt.Sprint(report.SPF.Remediation), "[{TXT example.com v=spf1 include:_spf.google.com mx include:spf.mandrillapp.com -all}]")
+
+	expect(t, report.DKIM.Problem, "no DKIM record found")
+	expect(t, reflect.DeepEqual(report.DKIM.Remediation, []DNSRecord{
+		{Type: "CNAME", Name: "mte1._domainkey.example.com", Value: "dkim1.mandrillapp.com"},
+		{Type: "CNAME", Name: "mte2._domainkey.example.com", Value: "dkim2.mandrillapp.com"},
+	}), true)
+
+	expect(t, report.DMARC.Problem, "no DMARC record")
+	expect(t, fmt.Sprint(report.DMARC.Remediation), "[{TXT _dmarc.example.com v=DMARC1; p=none; rua=mailto:dmarc-reports@example.com}]")
+}
+
+func Test_Preflight_MissingSPF(t *testing.T) {
+	fakeDNS(t, map[string][]string{"_dmarc.example.com": {"v=DMARC1; p=none"}})
+	server, c := testTools(200, `{"domain":"example.com","spf":{"valid":false},"dkim":{"valid":false}}`)
+	defer server.Close()
+
+	report, err := c.Preflight(context.Background(), "example.com")
+	expect(t, err, nil)
+	expect(t, fmt.Sprint(report.SPF.Remediation), "[{TXT example.com v=spf1 include:spf.mandrillapp.com ~all}]")
+	expect(t, report.DMARC.Problem, "mail won't align with DMARC until DKIM is set up")
+}
+
+func Test_Preflight_DNSError(t *testing.T) {
+	lookupTXT = func(ctx context.Context, name string) ([]string, error) {
+		return nil, &net.DNSError{Err: "server misbehaving", Name: name}
+	}
+	t.Cleanup(func() { lookupTXT = net.DefaultResolver.LookupTXT })
+	server, c := testTools(200, `{"domain":"example.com"}`)
+	defer server.Close()
+
+	report, err := c.Preflight(context.Background(), "example.com")
+	expect(t, report, (*PreflightReport)(nil))
+	expect(t, err.Error(), "lookup example.com: server misbehaving")
+}
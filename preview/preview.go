@@ -0,0 +1,116 @@
+// Package preview serves Mandrill templates rendered with sample data, for
+// iterating on email templates in a browser during development.
+//
+//	client := mandrill.ClientWithKey(os.Getenv("MANDRILL_API_KEY"))
+//	http.ListenAndServe("localhost:8025", &preview.Handler{Client: client, SampleDir: "testdata/templates"})
+//
+// Browse to http://localhost:8025/ for the list of templates. Each page is rendered
+// through templates/render on every request, so saved template and sample
+// changes show up on reload. Query parameters override merge vars, e.g.
+// /welcome?first_name=Bob.
+package preview
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/keighl/mandrill"
+)
+
+// Sample is the data a template is rendered with
+type Sample struct {
+	// content for the template's mc:edit regions, by region name
+	TemplateContent map[string]string `json:"template_content"`
+	// merge vars, by name
+	MergeVars map[string]interface{} `json:"merge_vars"`
+}
+
+// Handler lists and renders the account's templates
+type Handler struct {
+	// the client templates are listed and rendered with
+	Client *mandrill.Client
+	// optional directory of samples, one <template slug>.json file per template holding a Sample.
+	// Files are read on every request.
+	SampleDir string
+	// optional label to filter the template list by
+	Label string
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html><head><title>Template preview</title></head>
+<body><h1>Templates</h1><ul>
+{{range .}}<li><a href="/{{.Slug}}">{{.Name}}</a></li>
+{{end}}</ul></body></html>
+`))
+
+// ServeHTTP serves the template list at / and a rendered template at /<slug>
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.Trim(r.URL.Path, "/")
+	if name == "" {
+		h.serveIndex(w, r)
+		return
+	}
+	if name == "favicon.ico" {
+		http.NotFound(w, r)
+		return
+	}
+
+	sample, err := h.sample(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for param, values := range r.URL.Query() {
+		sample.MergeVars[param] = values[0]
+	}
+
+	html, err := h.Client.TemplatesRenderContext(r.Context(), name, sample.TemplateContent, sample.MergeVars)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, html)
+}
+
+func (h *Handler) serveIndex(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.Client.TemplatesListContext(r.Context(), h.Label)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	indexTemplate.Execute(w, templates)
+}
+
+// sample reads the template's sample file, if there is one
+func (h *Handler) sample(name string) (*Sample, error) {
+	sample := &Sample{}
+	if h.SampleDir != "" && !strings.ContainsAny(name, `/\`) {
+		data, err := ioutil.ReadFile(filepath.Join(h.SampleDir, name+".json"))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err == nil {
+			if err := json.Unmarshal(data, sample); err != nil {
+				return nil, fmt.Errorf("preview: %s.json: %v", name, err)
+			}
+		}
+	}
+	if sample.MergeVars == nil {
+		sample.MergeVars = map[string]interface{}{}
+	}
+	return sample, nil
+}
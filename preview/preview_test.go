@@ -0,0 +1,112 @@
+package preview
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/keighl/mandrill"
+)
+
+func expect(t *testing.T, a interface{}, b interface{}) {
+	if a != b {
+		t.Errorf("Expected %v (type %[1]T) - Got %v (type %[2]T)", b, a)
+	}
+}
+
+// fakeMandrill lists one template and renders the payload it was given
+func fakeMandrill() (*httptest.Server, *mandrill.Client) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/templates/list.json":
+			fmt.Fprint(w, `[{"slug":"welcome","name":"Welcome <b>"}]`)
+		case "/templates/render.json":
+			var payload struct {
+				TemplateName    string               `json:"template_name"`
+				TemplateContent []*mandrill.Variable `json:"template_content"`
+				MergeVars       []*mandrill.Variable `json:"merge_vars"`
+			}
+			json.NewDecoder(r.Body).Decode(&payload)
+			if payload.TemplateName != "welcome" {
+				w.WriteHeader(500)
+				fmt.Fprint(w, `{"status":"error","name":"Unknown_Template","message":"No such template"}`)
+				return
+			}
+			html := payload.TemplateName
+			for _, v := range append(payload.TemplateContent, payload.MergeVars...) {
+				html += fmt.Sprintf(" %s=%v", v.Name, v.Content)
+			}
+			json.NewEncoder(w).Encode(map[string]string{"html": html})
+		}
+	}))
+	client := mandrill.ClientWithKey("APIKEY")
+	client.BaseURL = server.URL + "/"
+	return server, client
+}
+
+func get(h http.Handler, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	return w
+}
+
+// Handler //////////
+
+func Test_Handler_Index(t *testing.T) {
+	server, client := fakeMandrill()
+	defer server.Close()
+
+	w := get(&Handler{Client: client}, "/")
+	expect(t, w.Code, http.StatusOK)
+	expect(t, strings.Contains(w.Body.String(), `<a href="/welcome">Welcome &lt;b&gt;</a>`), true)
+}
+
+func Test_Handler_Render(t *testing.T) {
+	server, client := fakeMandrill()
+	defer server.Close()
+
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "welcome.json"), []byte(`{"template_content":{"header":"Hi"},"merge_vars":{"first_name":"Kim"}}`), 0644)
+	h := &Handler{Client: client, SampleDir: dir}
+
+	w := get(h, "/welcome")
+	expect(t, w.Code, http.StatusOK)
+	expect(t, w.Header().Get("Content-Type"), "text/html; charset=utf-8")
+	expect(t, w.Body.String(), "welcome header=Hi first_name=Kim")
+
+	w = get(h, "/welcome?first_name=Bob")
+	expect(t, w.Body.String(), "welcome header=Hi first_name=Bob")
+}
+
+func Test_Handler_NoSample(t *testing.T) {
+	server, client := fakeMandrill()
+	defer server.Close()
+
+	w := get(&Handler{Client: client, SampleDir: t.TempDir()}, "/welcome")
+	expect(t, w.Code, http.StatusOK)
+	expect(t, w.Body.String(), "welcome")
+}
+
+func Test_Handler_Errors(t *testing.T) {
+	server, client := fakeMandrill()
+	defer server.Close()
+
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "welcome.json"), []byte(`{nope`), 0644)
+
+	w := get(&Handler{Client: client, SampleDir: dir}, "/welcome")
+	expect(t, w.Code, http.StatusInternalServerError)
+
+	w = get(&Handler{Client: client}, "/missing")
+	expect(t, w.Code, http.StatusBadGateway)
+	expect(t, strings.TrimSpace(w.Body.String()), "No such template")
+
+	w = httptest.NewRecorder()
+	(&Handler{Client: client}).ServeHTTP(w, httptest.NewRequest("POST", "/welcome", nil))
+	expect(t, w.Code, http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,105 @@
+package mandrill
+
+import (
+	"context"
+)
+
+// RejectEntry is an address on the rejection denylist
+type RejectEntry struct {
+	// the email that is blocked
+	Email string `json:"email"`
+	// the type of event (hard-bounce, soft-bounce, spam, unsub, custom) that caused this rejection
+	Reason string `json:"reason"`
+	// extended details about the event, such as the SMTP diagnostic for bounces or the comment for manually-created rejections
+	Detail string `json:"detail"`
+	// when the email was added to the denylist
+	CreatedAt Time `json:"created_at"`
+	// the timestamp of the most recent event that either created or renewed this rejection
+	LastEventAt Time `json:"last_event_at"`
+	// when the denylist entry will expire (this may be in the past)
+	ExpiresAt Time `json:"expires_at"`
+	// whether the denylist entry has expired
+	Expired bool `json:"expired"`
+	// the subaccount that this denylist entry applies to, or empty if none
+	Subaccount string `json:"subaccount"`
+}
+
+// RejectResult is the outcome of adding an address to, or removing one from, the rejection denylist
+type RejectResult struct {
+	// the email address that was added or removed
+	Email string `json:"email"`
+	// whether the operation succeeded
+	Added bool `json:"added"`
+	// whether the address was deleted successfully
+	Deleted bool `json:"deleted"`
+	// the subaccount the entry belonged to, or empty if none
+	Subaccount string `json:"subaccount"`
+}
+
+// RejectsAdd adds an email to the rejection denylist, optionally for a single subaccount
+func (c *Client) RejectsAdd(email string, comment string, subaccount string) (*RejectResult, error) {
+	return c.RejectsAddContext(context.Background(), email, comment, subaccount)
+}
+
+// RejectsAddContext is like RejectsAdd but with a context
+func (c *Client) RejectsAddContext(ctx context.Context, email string, comment string, subaccount string) (result *RejectResult, err error) {
+	var data struct {
+		Key        string `json:"key"`
+		Email      string `json:"email"`
+		Comment    string `json:"comment,omitempty"`
+		Subaccount string `json:"subaccount,omitempty"`
+	}
+
+	data.Key = c.Key
+	data.Email = email
+	data.Comment = comment
+	data.Subaccount = subaccount
+
+	err = c.call(ctx, "rejects/add.json", data, &result)
+	return result, err
+}
+
+// RejectsList returns up to 1000 denylist entries, optionally filtered by email
+// prefix and subaccount. Expired entries are only included when includeExpired is true.
+func (c *Client) RejectsList(email string, includeExpired bool, subaccount string) ([]*RejectEntry, error) {
+	return c.RejectsListContext(context.Background(), email, includeExpired, subaccount)
+}
+
+// RejectsListContext is like RejectsList but with a context
+func (c *Client) RejectsListContext(ctx context.Context, email string, includeExpired bool, subaccount string) (entries []*RejectEntry, err error) {
+	var data struct {
+		Key            string `json:"key"`
+		Email          string `json:"email,omitempty"`
+		IncludeExpired bool   `json:"include_expired"`
+		Subaccount     string `json:"subaccount,omitempty"`
+	}
+
+	data.Key = c.Key
+	data.Email = email
+	data.IncludeExpired = includeExpired
+	data.Subaccount = subaccount
+
+	err = c.call(ctx, "rejects/list.json", data, &entries)
+	return entries, err
+}
+
+// RejectsDelete removes an email from the rejection denylist, optionally for a single subaccount
+func (c *Client) RejectsDelete(email string, subaccount string) (*RejectResult, error) {
+	return c.RejectsDeleteContext(context.Background(), email, subaccount)
+}
+
+// RejectsDeleteContext is like RejectsDelete but with a context
+func (c *Client) RejectsDeleteContext(ctx context.Context, email string, subaccount string) (result *RejectResult, err error) {
+	var data struct {
+		Key        string `json:"key"`
+		Email      string `json:"email"`
+		Subaccount string `json:"subaccount,omitempty"`
+	}
+
+	data.Key = c.Key
+	data.Email = email
+	data.Subaccount = subaccount
+
+	err = c.call(ctx, "rejects/delete.json", data, &result)
+	return result, err
+}
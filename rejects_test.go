@@ -0,0 +1,61 @@
+package mandrill
+
+import (
+	"testing"
+	"time"
+)
+
+// Rejects //////////
+
+func Test_RejectsAdd(t *testing.T) {
+	server, c, req := testRecorder(200, `{"email":"example@example.com","added":true}`)
+	defer server.Close()
+
+	result, err := c.RejectsAdd("example@example.com", "bounced", "cust-123")
+	expect(t, err, nil)
+	expect(t, req.Path, "/rejects/add.json")
+	expect(t, req.Payload["email"], "example@example.com")
+	expect(t, req.Payload["comment"], "bounced")
+	expect(t, req.Payload["subaccount"], "cust-123")
+	expect(t, result.Email, "example@example.com")
+	expect(t, result.Added, true)
+}
+
+func Test_RejectsList(t *testing.T) {
+	server, c, req := testRecorder(200, `[{
+		"email": "example@example.com",
+		"reason": "hard-bounce",
+		"detail": "Example detail",
+		"created_at": "2013-01-01 15:30:27",
+		"last_event_at": "2013-01-01 15:30:27",
+		"expires_at": "2013-01-01 15:30:49",
+		"expired": false,
+		"sender": {"address": "sender.example@mandrillapp.com"},
+		"subaccount": "example_subaccount"
+	}]`)
+	defer server.Close()
+
+	entries, err := c.RejectsList("example@", true, "")
+	expect(t, err, nil)
+	expect(t, req.Path, "/rejects/list.json")
+	expect(t, req.Payload["email"], "example@")
+	expect(t, req.Payload["include_expired"], true)
+	_, hasSubaccount := req.Payload["subaccount"]
+	expect(t, hasSubaccount, false)
+
+	expect(t, len(entries), 1)
+	expect(t, entries[0].Reason, "hard-bounce")
+	expect(t, entries[0].Subaccount, "example_subaccount")
+	expect(t, entries[0].ExpiresAt.Equal(time.Date(2013, 1, 1, 15, 30, 49, 0, time.UTC)), true)
+}
+
+func Test_RejectsDelete(t *testing.T) {
+	server, c, req := testRecorder(200, `{"email":"example@example.com","deleted":true,"subaccount":"cust-123"}`)
+	defer server.Close()
+
+	result, err := c.RejectsDelete("example@example.com", "cust-123")
+	expect(t, err, nil)
+	expect(t, req.Path, "/rejects/delete.json")
+	expect(t, result.Deleted, true)
+	expect(t, result.Subaccount, "cust-123")
+}
@@ -0,0 +1,181 @@
+package mandrill
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	mcEditPattern     = regexp.MustCompile(`mc:edit\s*=\s*["']([^"']+)["']`)
+	mergeTagPattern   = regexp.MustCompile(`\*\|([A-Za-z0-9_:]+)\|\*`)
+	handlebarsPattern = regexp.MustCompile(`\{\{\{?\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}?\}\}`)
+)
+
+// merge tags that Mandrill fills in itself
+var reservedMergeTags = map[string]bool{
+	"UNSUB":           true,
+	"CURRENT_YEAR":    true,
+	"EMAIL":           true,
+	"ARCHIVE":         true,
+	"MC_PREVIEW_TEXT": true,
+	"END":             true,
+	"ELSE":            true,
+}
+
+// TemplateSchema lists the editable regions and merge tags used by a template
+type TemplateSchema struct {
+	// the names of the template's mc:edit regions
+	Regions []string
+	// the upper-cased names of the template's merge tags, e.g. FNAME for *|FNAME|*
+	MergeTags []string
+}
+
+// SchemaError lists the template content and merge vars a message is missing
+type SchemaError struct {
+	// mc:edit regions without template content
+	Regions []string
+	// merge tags without a value, keyed by recipient email
+	MergeVars map[string][]string
+}
+
+// Error describes the missing regions and merge vars
+func (err *SchemaError) Error() string {
+	parts := []string{}
+	if len(err.Regions) > 0 {
+		parts = append(parts, "missing template content for "+strings.Join(err.Regions, ", "))
+	}
+
+	rcpts := make([]string, 0, len(err.MergeVars))
+	for rcpt := range err.MergeVars {
+		rcpts = append(rcpts, rcpt)
+	}
+	sort.Strings(rcpts)
+	for _, rcpt := range rcpts {
+		parts = append(parts, fmt.Sprintf("missing merge vars %s for %s", strings.Join(err.MergeVars[rcpt], ", "), rcpt))
+	}
+
+	return "mandrill: " + strings.Join(parts, "; ")
+}
+
+// ParseTemplateSchema extracts the mc:edit regions and merge tags from template code
+func ParseTemplateSchema(code string) *TemplateSchema {
+	schema := &TemplateSchema{Regions: []string{}, MergeTags: []string{}}
+
+	seen := map[string]bool{}
+	for _, match := range mcEditPattern.FindAllStringSubmatch(code, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			schema.Regions = append(schema.Regions, match[1])
+		}
+	}
+
+	seen = map[string]bool{}
+	addTag := func(name string) {
+		name = strings.ToUpper(name)
+		if name == "" || reservedMergeTags[name] || seen[name] {
+			return
+		}
+		seen[name] = true
+		schema.MergeTags = append(schema.MergeTags, name)
+	}
+
+	for _, match := range mergeTagPattern.FindAllStringSubmatch(code, -1) {
+		name := match[1]
+		if i := strings.Index(name, ":"); i >= 0 {
+			// *|IF:FNAME|*, *|ELSEIF:FNAME|* and *|HTML:FNAME|* reference a merge var,
+			// other prefixed tags (*|MC:SUBJECT|*, *|DATE:Y|*, ...) are provided by Mandrill
+			switch strings.ToUpper(name[:i]) {
+			case "IF", "IFNOT", "ELSEIF", "HTML":
+				name = name[i+1:]
+			default:
+				continue
+			}
+		}
+		addTag(name)
+	}
+
+	for _, match := range handlebarsPattern.FindAllStringSubmatch(code, -1) {
+		switch match[1] {
+		case "else", "this":
+			continue
+		}
+		addTag(match[1])
+	}
+
+	return schema
+}
+
+// TemplateSchema fetches the published code of a template and extracts its schema
+func (c *Client) TemplateSchema(templateName string) (schema *TemplateSchema, err error) {
+	return c.TemplateSchemaContext(context.Background(), templateName)
+}
+
+// TemplateSchemaContext is like TemplateSchema but with a context
+func (c *Client) TemplateSchemaContext(ctx context.Context, templateName string) (schema *TemplateSchema, err error) {
+	template, err := c.TemplatesInfoContext(ctx, templateName)
+	if err != nil {
+		return schema, err
+	}
+
+	code := template.PublishCode
+	if code == "" {
+		code = template.Code
+	}
+	return ParseTemplateSchema(code), nil
+}
+
+// Validate returns a *SchemaError if the message and template contents don't
+// provide every region and merge tag in the schema. contents takes the same
+// values as MessagesSendTemplate. A merge tag is satisfied by a global merge var
+// or by a merge var for every recipient.
+func (s *TemplateSchema) Validate(message *Message, contents interface{}) error {
+	schemaErr := &SchemaError{MergeVars: map[string][]string{}}
+
+	provided := map[string]bool{}
+	vars, _ := ConvertTemplateContent(contents)
+	for _, v := range vars {
+		provided[v.Name] = true
+	}
+	for _, region := range s.Regions {
+		if !provided[region] {
+			schemaErr.Regions = append(schemaErr.Regions, region)
+		}
+	}
+
+	global := map[string]bool{}
+	for _, v := range message.GlobalMergeVars {
+		global[strings.ToUpper(v.Name)] = true
+	}
+
+	rcptVars := map[string]map[string]bool{}
+	for _, rcpt := range message.MergeVars {
+		vars := map[string]bool{}
+		for _, v := range rcpt.Vars {
+			vars[strings.ToUpper(v.Name)] = true
+		}
+		rcptVars[strings.ToLower(rcpt.Rcpt)] = vars
+	}
+
+	for _, tag := range s.MergeTags {
+		if global[tag] {
+			continue
+		}
+		if len(message.To) == 0 {
+			schemaErr.MergeVars["*"] = append(schemaErr.MergeVars["*"], tag)
+			continue
+		}
+		for _, to := range message.To {
+			if !rcptVars[strings.ToLower(to.Email)][tag] {
+				schemaErr.MergeVars[to.Email] = append(schemaErr.MergeVars[to.Email], tag)
+			}
+		}
+	}
+
+	if len(schemaErr.Regions) > 0 || len(schemaErr.MergeVars) > 0 {
+		return schemaErr
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package mandrill
+
+import (
+	"reflect"
+	"testing"
+)
+
+const schemaTemplate = `<div mc:edit="header">Hi</div>
+<div mc:edit='main'></div>
+<p>Hello *|FNAME|*, *|IF:COMPANY|*from *|COMPANY|**|END:IF|*</p>
+<p>{{ plan }} *|MC:SUBJECT|* *|UNSUB|*</p>`
+
+func Test_ParseTemplateSchema(t *testing.T) {
+	schema := ParseTemplateSchema(schemaTemplate)
+	expect(t, reflect.DeepEqual(schema.Regions, []string{"header", "main"}), true)
+	expect(t, reflect.DeepEqual(schema.MergeTags, []string{"FNAME", "COMPANY", "PLAN"}), true)
+}
+
+func Test_TemplateSchema(t *testing.T) {
+	server, m := testTools(200, `{"code":"*|DRAFT|*","publish_code":"<div mc:edit=\"header\">*|FNAME|*</div>"}`)
+	defer server.Close()
+	schema, err := m.TemplateSchema("welcome")
+
+	expect(t, err, nil)
+	expect(t, reflect.DeepEqual(schema, &TemplateSchema{Regions: []string{"header"}, MergeTags: []string{"FNAME"}}), true)
+}
+
+func Test_TemplateSchema_Validate(t *testing.T) {
+	schema := ParseTemplateSchema(schemaTemplate)
+
+	message := &Message{}
+	message.AddRecipient("bob@example.com", "Bob", "to")
+	message.AddRecipient("jill@example.com", "Jill", "to")
+	message.GlobalMergeVars = MapToVars(map[string]interface{}{"company": "Acme", "plan": "pro"})
+	message.MergeVars = []*RcptMergeVars{MapToRecipientVars("bob@example.com", map[string]string{"FNAME": "Bob"})}
+
+	err := schema.Validate(message, map[string]string{"header": "Hi"})
+	schemaErr, ok := err.(*SchemaError)
+	expect(t, ok, true)
+	expect(t, reflect.DeepEqual(schemaErr.Regions, []string{"main"}), true)
+	expect(t, reflect.DeepEqual(schemaErr.MergeVars, map[string][]string{"jill@example.com": {"FNAME"}}), true)
+	expect(t, err.Error(), "mandrill: missing template content for main; missing merge vars FNAME for jill@example.com")
+
+	message.MergeVars = append(message.MergeVars, MapToRecipientVars("jill@example.com", map[string]string{"fname": "Jill"}))
+	expect(t, schema.Validate(message, map[string]string{"header": "Hi", "main": "Body"}), nil)
+}
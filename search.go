@@ -0,0 +1,159 @@
+package mandrill
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// searchDefaultDays is how far back Mandrill searches when date_from isn't given
+const searchDefaultDays = 7
+
+// SearchResult is a message matched by MessagesSearch
+type SearchResult = MessageInfo
+
+// SearchParams selects the messages returned by MessagesSearch
+type SearchParams struct {
+	// the search terms to find matching messages. See SearchQuery for building one.
+	// An empty query matches all messages.
+	Query string
+	// an optional range of days to search. Zero ends are unbounded.
+	Range DateRange
+	// an optional list of tags to filter by
+	Tags []string
+	// an optional list of senders to filter by
+	Senders []string
+	// an optional list of API keys to filter by
+	APIKeys []string
+	// the maximum number of results to return, defaults to 100, 1000 is the maximum
+	Limit int
+}
+
+// SearchQuery builds a search query string. Non-empty fields are combined with AND.
+type SearchQuery struct {
+	// matches the recipient's domain or address, e.g. "example.com"
+	Email string
+	// matches the recipient's full email address
+	FullEmail string
+	// matches the sender's email address
+	Sender string
+	// matches words in the subject line
+	Subject string
+	// matches any of the tags
+	Tags []string
+	// matches the message state, e.g. "sent", "bounced", "rejected"
+	State string
+	// matches indexed metadata fields, e.g. {"user_id": "123"}
+	Metadata map[string]string
+	// free search terms appended as-is
+	Terms string
+}
+
+// String returns the query in Mandrill's search syntax
+func (q SearchQuery) String() string {
+	var clauses []string
+	add := func(field, value string) {
+		if value != "" {
+			clauses = append(clauses, field+":"+quoteSearchValue(value))
+		}
+	}
+
+	add("email", q.Email)
+	add("full_email", q.FullEmail)
+	add("sender", q.Sender)
+	add("subject", q.Subject)
+	add("state", q.State)
+
+	if len(q.Tags) > 0 {
+		tags := make([]string, len(q.Tags))
+		for i, tag := range q.Tags {
+			tags[i] = "tags:" + quoteSearchValue(tag)
+		}
+		if len(tags) == 1 {
+			clauses = append(clauses, tags[0])
+		} else {
+			clauses = append(clauses, "("+strings.Join(tags, " OR ")+")")
+		}
+	}
+
+	names := make([]string, 0, len(q.Metadata))
+	for name := range q.Metadata {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add("u_"+name, q.Metadata[name])
+	}
+
+	if q.Terms != "" {
+		clauses = append(clauses, q.Terms)
+	}
+
+	return strings.Join(clauses, " AND ")
+}
+
+// quoteSearchValue wraps values containing whitespace or quotes in double quotes
+func quoteSearchValue(value string) string {
+	if !strings.ContainsAny(value, " \t\"():") {
+		return value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// MessagesSearch returns the recently sent messages matching params
+func (c *Client) MessagesSearch(params SearchParams) ([]*SearchResult, error) {
+	return c.MessagesSearchContext(context.Background(), params)
+}
+
+// MessagesSearchContext is like MessagesSearch but with a context
+func (c *Client) MessagesSearchContext(ctx context.Context, params SearchParams) (results []*SearchResult, err error) {
+	var data struct {
+		Key      string   `json:"key"`
+		Query    string   `json:"query,omitempty"`
+		DateFrom string   `json:"date_from,omitempty"`
+		DateTo   string   `json:"date_to,omitempty"`
+		Tags     []string `json:"tags,omitempty"`
+		Senders  []string `json:"senders,omitempty"`
+		APIKeys  []string `json:"api_keys,omitempty"`
+		Limit    int      `json:"limit,omitempty"`
+	}
+
+	data.Key = c.Key
+	data.Query = params.Query
+	if !params.Range.From.IsZero() {
+		data.DateFrom = params.Range.DateFrom()
+	}
+	if !params.Range.To.IsZero() {
+		data.DateTo = params.Range.DateTo()
+	}
+	data.Tags = params.Tags
+	data.Senders = params.Senders
+	data.APIKeys = params.APIKeys
+	data.Limit = params.Limit
+
+	err = c.call(ctx, "messages/search.json", data, &results)
+	return results, err
+}
+
+// dayStart returns the start of t's UTC day
+func dayStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// pageWait sleeps for PageInterval between paged requests, unless ctx is done first
+func (c *Client) pageWait(ctx context.Context) error {
+	if c.PageInterval <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(c.PageInterval)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
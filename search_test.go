@@ -0,0 +1,65 @@
+package mandrill
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Search //////////
+
+func Test_MessagesSearch(t *testing.T) {
+	server, c, req := testRecorder(200, "["+messageInfoJSON+"]")
+	defer server.Close()
+
+	results, err := c.MessagesSearch(SearchParams{
+		Query: SearchQuery{Email: "example.com"}.String(),
+		Range: DateRange{
+			From: time.Date(2013, 1, 1, 15, 30, 0, 0, time.UTC),
+			To:   time.Date(2013, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		Senders: []string{"sender@example.com"},
+		Limit:   10,
+	})
+	expect(t, err, nil)
+	expect(t, req.Path, "/messages/search.json")
+	expect(t, req.Payload["query"], "email:example.com")
+	expect(t, req.Payload["date_from"], "2013-01-01")
+	expect(t, req.Payload["date_to"], "2013-01-02")
+	expect(t, reflect.DeepEqual(req.Payload["senders"], []interface{}{"sender@example.com"}), true)
+	expect(t, req.Payload["limit"], float64(10))
+	expect(t, results[0].ID, "abc123abc123abc123abc123")
+}
+
+func Test_MessagesSearch_Defaults(t *testing.T) {
+	server, c, req := testRecorder(200, "[]")
+	defer server.Close()
+
+	results, err := c.MessagesSearch(SearchParams{})
+	expect(t, err, nil)
+	expect(t, len(results), 0)
+	for _, key := range []string{"query", "date_from", "date_to", "tags", "limit"} {
+		_, ok := req.Payload[key]
+		expect(t, ok, false)
+	}
+}
+
+// Query //////////
+
+func Test_SearchQuery_String(t *testing.T) {
+	q := SearchQuery{
+		Email:    "example.com",
+		Subject:  "password reset",
+		Tags:     []string{"welcome", "drip"},
+		State:    "bounced",
+		Metadata: map[string]string{"user_id": "123", "plan": "pro"},
+		Terms:    "NOT sender:noreply@example.com",
+	}
+	expect(t, q.String(), `email:example.com AND subject:"password reset" AND state:bounced AND (tags:welcome OR tags:drip) AND u_plan:pro AND u_user_id:123 AND NOT sender:noreply@example.com`)
+}
+
+func Test_SearchQuery_Quoting(t *testing.T) {
+	expect(t, SearchQuery{}.String(), "")
+	expect(t, SearchQuery{Tags: []string{"one"}}.String(), "tags:one")
+	expect(t, SearchQuery{Subject: `say "hi"`}.String(), `subject:"say \"hi\""`)
+}
@@ -0,0 +1,177 @@
+package mandrill
+
+import (
+	"context"
+)
+
+// Sender holds the stats of a sending address
+type Sender struct {
+	// the sender's email address
+	Address string `json:"address"`
+	// the date and time that the sender was first seen by Mandrill
+	CreatedAt Time `json:"created_at"`
+	// lifetime stats for the sender
+	Stats
+	// stats for recent periods (only returned by SendersInfo)
+	PeriodStats *PeriodStats `json:"stats,omitempty"`
+}
+
+// SenderDomain describes the SPF/DKIM configuration of a sending domain
+type SenderDomain struct {
+	// the sender domain name
+	Domain string `json:"domain"`
+	// the date and time that the sending domain was first seen
+	CreatedAt Time `json:"created_at"`
+	// when the domain's DNS settings were last tested
+	LastTestedAt Time `json:"last_tested_at"`
+	// details about the domain's SPF record
+	SPF *DomainRecord `json:"spf,omitempty"`
+	// details about the domain's DKIM record
+	DKIM *DomainRecord `json:"dkim,omitempty"`
+	// if the domain has been verified, this indicates when that verification occurred
+	VerifiedAt Time `json:"verified_at"`
+	// whether this domain can be used to authenticate mail, either for itself or as a custom signing domain
+	ValidSigning bool `json:"valid_signing"`
+}
+
+// DomainRecord is the validation state of a domain's SPF or DKIM record
+type DomainRecord struct {
+	// whether the domain's record is valid for use with Mandrill
+	Valid bool `json:"valid"`
+	// when the domain's record will be considered valid for use with Mandrill. If there is an error, this is zero.
+	ValidAfter Time `json:"valid_after"`
+	// an error describing the record, or empty if the record is correct
+	Error string `json:"error"`
+}
+
+// SenderDomainVerification is the result of sending a domain verification email
+type SenderDomainVerification struct {
+	// "sent" indicates that the verification has been sent, "already_verified" indicates that the domain has already been verified with your account
+	Status string `json:"status"`
+	// the domain name you provided
+	Domain string `json:"domain"`
+	// the email address the verification email was sent to
+	Email string `json:"email"`
+}
+
+// SendersList returns the senders that have tried to use this account
+func (c *Client) SendersList() ([]*Sender, error) {
+	return c.SendersListContext(context.Background())
+}
+
+// SendersListContext is like SendersList but with a context
+func (c *Client) SendersListContext(ctx context.Context) (senders []*Sender, err error) {
+	var data struct {
+		Key string `json:"key"`
+	}
+
+	data.Key = c.Key
+
+	err = c.call(ctx, "senders/list.json", data, &senders)
+	return senders, err
+}
+
+// SendersInfo returns more detailed information about a single sender, including aggregates of recent stats
+func (c *Client) SendersInfo(address string) (*Sender, error) {
+	return c.SendersInfoContext(context.Background(), address)
+}
+
+// SendersInfoContext is like SendersInfo but with a context
+func (c *Client) SendersInfoContext(ctx context.Context, address string) (sender *Sender, err error) {
+	err = c.call(ctx, "senders/info.json", c.senderPayload(address), &sender)
+	return sender, err
+}
+
+// SendersTimeSeries returns the recent history (hourly stats for the last 30 days) for a sender
+func (c *Client) SendersTimeSeries(address string) ([]*TimeSeries, error) {
+	return c.SendersTimeSeriesContext(context.Background(), address)
+}
+
+// SendersTimeSeriesContext is like SendersTimeSeries but with a context
+func (c *Client) SendersTimeSeriesContext(ctx context.Context, address string) (series []*TimeSeries, err error) {
+	err = c.call(ctx, "senders/time-series.json", c.senderPayload(address), &series)
+	return series, err
+}
+
+func (c *Client) senderPayload(address string) interface{} {
+	var data struct {
+		Key     string `json:"key"`
+		Address string `json:"address"`
+	}
+
+	data.Key = c.Key
+	data.Address = address
+
+	return data
+}
+
+// SendersDomains returns the sender domains that have been added to this account
+func (c *Client) SendersDomains() ([]*SenderDomain, error) {
+	return c.SendersDomainsContext(context.Background())
+}
+
+// SendersDomainsContext is like SendersDomains but with a context
+func (c *Client) SendersDomainsContext(ctx context.Context) (domains []*SenderDomain, err error) {
+	var data struct {
+		Key string `json:"key"`
+	}
+
+	data.Key = c.Key
+
+	err = c.call(ctx, "senders/domains.json", data, &domains)
+	return domains, err
+}
+
+// SendersAddDomain adds a sender domain to the account
+func (c *Client) SendersAddDomain(domain string) (*SenderDomain, error) {
+	return c.SendersAddDomainContext(context.Background(), domain)
+}
+
+// SendersAddDomainContext is like SendersAddDomain but with a context
+func (c *Client) SendersAddDomainContext(ctx context.Context, domain string) (*SenderDomain, error) {
+	return c.senderDomainCall(ctx, "senders/add-domain.json", domain)
+}
+
+// SendersCheckDomain checks the SPF and DKIM settings for a domain, adding it to the account if it hasn't been already
+func (c *Client) SendersCheckDomain(domain string) (*SenderDomain, error) {
+	return c.SendersCheckDomainContext(context.Background(), domain)
+}
+
+// SendersCheckDomainContext is like SendersCheckDomain but with a context
+func (c *Client) SendersCheckDomainContext(ctx context.Context, domain string) (*SenderDomain, error) {
+	return c.senderDomainCall(ctx, "senders/check-domain.json", domain)
+}
+
+func (c *Client) senderDomainCall(ctx context.Context, path string, domain string) (result *SenderDomain, err error) {
+	var data struct {
+		Key    string `json:"key"`
+		Domain string `json:"domain"`
+	}
+
+	data.Key = c.Key
+	data.Domain = domain
+
+	err = c.call(ctx, path, data, &result)
+	return result, err
+}
+
+// SendersVerifyDomain sends a verification email to mailbox@domain to prove ownership of the domain
+func (c *Client) SendersVerifyDomain(domain string, mailbox string) (*SenderDomainVerification, error) {
+	return c.SendersVerifyDomainContext(context.Background(), domain, mailbox)
+}
+
+// SendersVerifyDomainContext is like SendersVerifyDomain but with a context
+func (c *Client) SendersVerifyDomainContext(ctx context.Context, domain string, mailbox string) (result *SenderDomainVerification, err error) {
+	var data struct {
+		Key     string `json:"key"`
+		Domain  string `json:"domain"`
+		Mailbox string `json:"mailbox"`
+	}
+
+	data.Key = c.Key
+	data.Domain = domain
+	data.Mailbox = mailbox
+
+	err = c.call(ctx, "senders/verify-domain.json", data, &result)
+	return result, err
+}
@@ -0,0 +1,93 @@
+package mandrill
+
+import (
+	"testing"
+)
+
+const senderJSON = `{"address":"sender.example@mandrillapp.com","created_at":"2013-01-01 15:30:27",` + tagStatsJSON + `,"stats":{"last_7_days":{"sent":7}}}`
+
+const senderDomainJSON = `{
+	"domain": "example.com",
+	"created_at": "2013-01-01 15:30:27",
+	"last_tested_at": "2013-01-01 15:40:42",
+	"spf": {"valid": true, "valid_after": "2013-01-01 15:45:23", "error": ""},
+	"dkim": {"valid": false, "valid_after": null, "error": "No DKIM record found"},
+	"verified_at": "2013-01-01 15:50:19",
+	"valid_signing": false
+}`
+
+// Senders //////////
+
+func Test_SendersList(t *testing.T) {
+	server, c, req := testRecorder(200, "["+senderJSON+"]")
+	defer server.Close()
+
+	senders, err := c.SendersList()
+	expect(t, err, nil)
+	expect(t, req.Path, "/senders/list.json")
+	expect(t, senders[0].Address, "sender.example@mandrillapp.com")
+	expect(t, senders[0].Sent, 42)
+}
+
+func Test_SendersInfo(t *testing.T) {
+	server, c, req := testRecorder(200, senderJSON)
+	defer server.Close()
+
+	sender, err := c.SendersInfo("sender.example@mandrillapp.com")
+	expect(t, err, nil)
+	expect(t, req.Path, "/senders/info.json")
+	expect(t, req.Payload["address"], "sender.example@mandrillapp.com")
+	expect(t, sender.PeriodStats.Last7Days.Sent, 7)
+}
+
+func Test_SendersTimeSeries(t *testing.T) {
+	server, c, req := testRecorder(200, timeSeriesJSON)
+	defer server.Close()
+
+	series, err := c.SendersTimeSeries("sender.example@mandrillapp.com")
+	expect(t, err, nil)
+	expect(t, req.Path, "/senders/time-series.json")
+	expect(t, series[0].Sent, 42)
+}
+
+// Sender domains //////////
+
+func Test_SendersDomains(t *testing.T) {
+	server, c, req := testRecorder(200, "["+senderDomainJSON+"]")
+	defer server.Close()
+
+	domains, err := c.SendersDomains()
+	expect(t, err, nil)
+	expect(t, req.Path, "/senders/domains.json")
+	expect(t, domains[0].SPF.Valid, true)
+	expect(t, domains[0].DKIM.Valid, false)
+	expect(t, domains[0].DKIM.ValidAfter.IsZero(), true)
+	expect(t, domains[0].DKIM.Error, "No DKIM record found")
+}
+
+func Test_SendersAddCheckDomain(t *testing.T) {
+	server, c, req := testRecorder(200, senderDomainJSON)
+	defer server.Close()
+
+	domain, err := c.SendersAddDomain("example.com")
+	expect(t, err, nil)
+	expect(t, req.Path, "/senders/add-domain.json")
+	expect(t, req.Payload["domain"], "example.com")
+	expect(t, domain.Domain, "example.com")
+
+	domain, err = c.SendersCheckDomain("example.com")
+	expect(t, err, nil)
+	expect(t, req.Path, "/senders/check-domain.json")
+	expect(t, domain.VerifiedAt.IsZero(), false)
+}
+
+func Test_SendersVerifyDomain(t *testing.T) {
+	server, c, req := testRecorder(200, `{"status":"sent","domain":"example.com","email":"postmaster@example.com"}`)
+	defer server.Close()
+
+	result, err := c.SendersVerifyDomain("example.com", "postmaster")
+	expect(t, err, nil)
+	expect(t, req.Path, "/senders/verify-domain.json")
+	expect(t, req.Payload["mailbox"], "postmaster")
+	expect(t, result.Email, "postmaster@example.com")
+}
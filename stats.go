@@ -0,0 +1,115 @@
+package mandrill
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// Stats are aggregate sending stats for a period
+type Stats struct {
+	// the number of emails sent in the period
+	Sent int `json:"sent"`
+	// the number of emails that hard bounced in the period
+	HardBounces int `json:"hard_bounces"`
+	// the number of emails that soft bounced in the period
+	SoftBounces int `json:"soft_bounces"`
+	// the number of emails that were rejected for sending in the period
+	Rejects int `json:"rejects"`
+	// the number of spam complaints in the period
+	Complaints int `json:"complaints"`
+	// the number of unsubscribes in the period
+	Unsubs int `json:"unsubs"`
+	// the number of times emails were opened in the period
+	Opens int `json:"opens"`
+	// the number of unique opens in the period
+	UniqueOpens int `json:"unique_opens"`
+	// the number of URLs that were clicked in the period
+	Clicks int `json:"clicks"`
+	// the number of unique clicks in the period
+	UniqueClicks int `json:"unique_clicks"`
+}
+
+// add sums other into s
+func (s *Stats) add(other *Stats) {
+	s.Sent += other.Sent
+	s.HardBounces += other.HardBounces
+	s.SoftBounces += other.SoftBounces
+	s.Rejects += other.Rejects
+	s.Complaints += other.Complaints
+	s.Unsubs += other.Unsubs
+	s.Opens += other.Opens
+	s.UniqueOpens += other.UniqueOpens
+	s.Clicks += other.Clicks
+	s.UniqueClicks += other.UniqueClicks
+}
+
+// PeriodStats are aggregate sending stats for several recent periods
+type PeriodStats struct {
+	// stats for today
+	Today *Stats `json:"today,omitempty"`
+	// stats for the last 7 days
+	Last7Days *Stats `json:"last_7_days,omitempty"`
+	// stats for the last 30 days
+	Last30Days *Stats `json:"last_30_days,omitempty"`
+	// stats for the last 60 days
+	Last60Days *Stats `json:"last_60_days,omitempty"`
+	// stats for the last 90 days
+	Last90Days *Stats `json:"last_90_days,omitempty"`
+	// stats for the lifetime of the account (only returned by UsersInfo)
+	AllTime *Stats `json:"all_time,omitempty"`
+}
+
+// TimeSeries holds the stats for a single hour
+type TimeSeries struct {
+	// the hour as a UTC date string in YYYY-MM-DD HH:MM:SS format
+	Time Time `json:"time"`
+	Stats
+}
+
+// TimeSeriesColumns are the CSV columns written by WriteTimeSeriesCSV, in order
+var TimeSeriesColumns = []string{
+	"time", "sent", "hard_bounces", "soft_bounces", "rejects", "complaints",
+	"unsubs", "opens", "unique_opens", "clicks", "unique_clicks",
+}
+
+// WriteTimeSeriesCSV writes the series as CSV with a TimeSeriesColumns header row,
+// one row per hour. Times are UTC in TimestampFormat.
+func WriteTimeSeriesCSV(w io.Writer, series []*TimeSeries) error {
+	out := csv.NewWriter(w)
+	out.Write(TimeSeriesColumns)
+	for _, entry := range series {
+		if entry == nil {
+			continue
+		}
+		row := []string{""}
+		if !entry.Time.IsZero() {
+			row[0] = entry.Time.UTC().Format(TimestampFormat)
+		}
+		for _, n := range []int{
+			entry.Sent, entry.HardBounces, entry.SoftBounces, entry.Rejects, entry.Complaints,
+			entry.Unsubs, entry.Opens, entry.UniqueOpens, entry.Clicks, entry.UniqueClicks,
+		} {
+			row = append(row, strconv.Itoa(n))
+		}
+		out.Write(row)
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteTimeSeriesJSONLines writes the series as JSON lines, one object per hour
+// with the keys in TimeSeriesColumns order
+func WriteTimeSeriesJSONLines(w io.Writer, series []*TimeSeries) error {
+	enc := json.NewEncoder(w)
+	for _, entry := range series {
+		if entry == nil {
+			continue
+		}
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
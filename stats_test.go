@@ -0,0 +1,30 @@
+package mandrill
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+var testSeries = []*TimeSeries{
+	{Time: Time{time.Date(2013, 1, 1, 15, 0, 0, 0, time.UTC)}, Stats: Stats{Sent: 42, HardBounces: 1, Opens: 20, UniqueOpens: 15, Clicks: 5, UniqueClicks: 3}},
+	nil,
+	{Time: Time{time.Date(2013, 1, 1, 16, 0, 0, 0, time.UTC)}, Stats: Stats{Sent: 7, Rejects: 2}},
+}
+
+// Time-series encoding //////////
+
+func Test_WriteTimeSeriesCSV(t *testing.T) {
+	var buf bytes.Buffer
+	expect(t, WriteTimeSeriesCSV(&buf, testSeries), nil)
+	expect(t, buf.String(), "time,sent,hard_bounces,soft_bounces,rejects,complaints,unsubs,opens,unique_opens,clicks,unique_clicks\n"+
+		"2013-01-01 15:00:00,42,1,0,0,0,0,20,15,5,3\n"+
+		"2013-01-01 16:00:00,7,0,0,2,0,0,0,0,0,0\n")
+}
+
+func Test_WriteTimeSeriesJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	expect(t, WriteTimeSeriesJSONLines(&buf, testSeries), nil)
+	expect(t, buf.String(), `{"time":"2013-01-01 15:00:00","sent":42,"hard_bounces":1,"soft_bounces":0,"rejects":0,"complaints":0,"unsubs":0,"opens":20,"unique_opens":15,"clicks":5,"unique_clicks":3}`+"\n"+
+		`{"time":"2013-01-01 16:00:00","sent":7,"hard_bounces":0,"soft_bounces":0,"rejects":2,"complaints":0,"unsubs":0,"opens":0,"unique_opens":0,"clicks":0,"unique_clicks":0}`+"\n")
+}
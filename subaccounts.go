@@ -0,0 +1,202 @@
+package mandrill
+
+import (
+	"context"
+	"time"
+)
+
+// Subaccount holds the information and sending stats of a subaccount
+type Subaccount struct {
+	// a unique identifier for the subaccount
+	ID string `json:"id"`
+	// an optional display name for the subaccount
+	Name string `json:"name"`
+	// optional extra text to associate with the subaccount (only returned by SubaccountsInfo)
+	Notes string `json:"notes"`
+	// an optional manual hourly quota for the subaccount. If not specified, the hourly quota will be managed based on reputation
+	CustomQuota int `json:"custom_quota"`
+	// the current sending status of the subaccount, one of "active" or "paused"
+	Status string `json:"status"`
+	// the subaccount's current reputation on a scale from 0 to 100
+	Reputation int `json:"reputation"`
+	// the date and time that the subaccount was created
+	CreatedAt Time `json:"created_at"`
+	// the date and time that the subaccount first sent
+	FirstSentAt Time `json:"first_sent_at"`
+	// the number of emails the subaccount has sent so far this week (weeks start on midnight Monday, UTC)
+	SentWeekly int `json:"sent_weekly"`
+	// the number of emails the subaccount has sent so far this month (months start on midnight of the 1st, UTC)
+	SentMonthly int `json:"sent_monthly"`
+	// the number of emails the subaccount has sent since it was created
+	SentTotal int `json:"sent_total"`
+	// the number of emails the subaccount has sent in the last hour (only returned by SubaccountsInfo)
+	SentHourly int `json:"sent_hourly"`
+	// the current hourly quota for the subaccount, either manual or reputation-based (only returned by SubaccountsInfo)
+	HourlyQuota int `json:"hourly_quota"`
+	// stats for this subaccount in the last 30 days (only returned by SubaccountsInfo)
+	Last30Days *Stats `json:"last_30_days,omitempty"`
+}
+
+type subaccountPayload struct {
+	Key         string `json:"key"`
+	ID          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	Notes       string `json:"notes,omitempty"`
+	CustomQuota int    `json:"custom_quota,omitempty"`
+}
+
+// SubaccountsList returns the subaccounts, optionally filtered by an id or name prefix
+func (c *Client) SubaccountsList(q string) ([]*Subaccount, error) {
+	return c.SubaccountsListContext(context.Background(), q)
+}
+
+// SubaccountsListContext is like SubaccountsList but with a context
+func (c *Client) SubaccountsListContext(ctx context.Context, q string) (subaccounts []*Subaccount, err error) {
+	var data struct {
+		Key string `json:"key"`
+		Q   string `json:"q,omitempty"`
+	}
+
+	data.Key = c.Key
+	data.Q = q
+
+	err = c.call(ctx, "subaccounts/list.json", data, &subaccounts)
+	return subaccounts, err
+}
+
+// SubaccountsAdd adds a new subaccount. A customQuota of zero leaves the hourly quota reputation-based.
+func (c *Client) SubaccountsAdd(id string, name string, notes string, customQuota int) (*Subaccount, error) {
+	return c.SubaccountsAddContext(context.Background(), id, name, notes, customQuota)
+}
+
+// SubaccountsAddContext is like SubaccountsAdd but with a context
+func (c *Client) SubaccountsAddContext(ctx context.Context, id string, name string, notes string, customQuota int) (subaccount *Subaccount, err error) {
+	data := subaccountPayload{Key: c.Key, ID: id, Name: name, Notes: notes, CustomQuota: customQuota}
+	err = c.call(ctx, "subaccounts/add.json", data, &subaccount)
+	return subaccount, err
+}
+
+// SubaccountsInfo returns the details and last 30 days of stats for a subaccount
+func (c *Client) SubaccountsInfo(id string) (*Subaccount, error) {
+	return c.SubaccountsInfoContext(context.Background(), id)
+}
+
+// SubaccountsInfoContext is like SubaccountsInfo but with a context
+func (c *Client) SubaccountsInfoContext(ctx context.Context, id string) (subaccount *Subaccount, err error) {
+	return c.subaccountCall(ctx, "subaccounts/info.json", id)
+}
+
+// SubaccountsUpdate updates an existing subaccount. Empty fields are left unchanged.
+func (c *Client) SubaccountsUpdate(id string, name string, notes string, customQuota int) (*Subaccount, error) {
+	return c.SubaccountsUpdateContext(context.Background(), id, name, notes, customQuota)
+}
+
+// SubaccountsUpdateContext is like SubaccountsUpdate but with a context
+func (c *Client) SubaccountsUpdateContext(ctx context.Context, id string, name string, notes string, customQuota int) (subaccount *Subaccount, err error) {
+	data := subaccountPayload{Key: c.Key, ID: id, Name: name, Notes: notes, CustomQuota: customQuota}
+	err = c.call(ctx, "subaccounts/update.json", data, &subaccount)
+	return subaccount, err
+}
+
+// SubaccountsDelete deletes a subaccount. Any email related to it will be saved, but stats will be removed.
+func (c *Client) SubaccountsDelete(id string) (*Subaccount, error) {
+	return c.SubaccountsDeleteContext(context.Background(), id)
+}
+
+// SubaccountsDeleteContext is like SubaccountsDelete but with a context
+func (c *Client) SubaccountsDeleteContext(ctx context.Context, id string) (subaccount *Subaccount, err error) {
+	return c.subaccountCall(ctx, "subaccounts/delete.json", id)
+}
+
+// SubaccountsPause pauses a subaccount's sending. Messages sent while paused are queued until it is resumed.
+func (c *Client) SubaccountsPause(id string) (*Subaccount, error) {
+	return c.SubaccountsPauseContext(context.Background(), id)
+}
+
+// SubaccountsPauseContext is like SubaccountsPause but with a context
+func (c *Client) SubaccountsPauseContext(ctx context.Context, id string) (subaccount *Subaccount, err error) {
+	return c.subaccountCall(ctx, "subaccounts/pause.json", id)
+}
+
+// SubaccountsResume resumes a paused subaccount's sending
+func (c *Client) SubaccountsResume(id string) (*Subaccount, error) {
+	return c.SubaccountsResumeContext(context.Background(), id)
+}
+
+// SubaccountsResumeContext is like SubaccountsResume but with a context
+func (c *Client) SubaccountsResumeContext(ctx context.Context, id string) (subaccount *Subaccount, err error) {
+	return c.subaccountCall(ctx, "subaccounts/resume.json", id)
+}
+
+func (c *Client) subaccountCall(ctx context.Context, path string, id string) (subaccount *Subaccount, err error) {
+	var data struct {
+		Key string `json:"key"`
+		ID  string `json:"id"`
+	}
+
+	data.Key = c.Key
+	data.ID = id
+
+	err = c.call(ctx, path, data, &subaccount)
+	return subaccount, err
+}
+
+// SubaccountUsageReport is the combined usage of several subaccounts, e.g. for billing
+type SubaccountUsageReport struct {
+	// when the report was built
+	GeneratedAt time.Time
+	// each subaccount as returned by SubaccountsInfo, including its hourly quota and last 30 days' stats
+	Subaccounts []*Subaccount
+	// the sum of the subaccounts' SentWeekly
+	SentWeekly int
+	// the sum of the subaccounts' SentMonthly
+	SentMonthly int
+	// the sum of the subaccounts' SentTotal
+	SentTotal int
+	// the sum of the subaccounts' HourlyQuota
+	HourlyQuota int
+	// the sum of the subaccounts' Last30Days stats, including rejects
+	Last30Days Stats
+}
+
+// SubaccountUsage lists the subaccounts matching q, as SubaccountsList does, then
+// fetches each one's SubaccountsInfo and totals their usage. interval spaces the
+// info calls to stay under Mandrill's rate limits; zero makes them back to back.
+// Throttled calls are retried according to Client.ThrottleRetries.
+func (c *Client) SubaccountUsage(ctx context.Context, q string, interval time.Duration) (report *SubaccountUsageReport, err error) {
+	list, err := c.SubaccountsListContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	report = &SubaccountUsageReport{GeneratedAt: now().UTC()}
+	for i, listed := range list {
+		if i > 0 && interval > 0 {
+			timer := time.NewTimer(interval)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			}
+		}
+
+		subaccount, err := c.SubaccountsInfoContext(ctx, listed.ID)
+		if err != nil {
+			return nil, err
+		}
+		report.add(subaccount)
+	}
+	return report, nil
+}
+
+func (r *SubaccountUsageReport) add(subaccount *Subaccount) {
+	r.Subaccounts = append(r.Subaccounts, subaccount)
+	r.SentWeekly += subaccount.SentWeekly
+	r.SentMonthly += subaccount.SentMonthly
+	r.SentTotal += subaccount.SentTotal
+	r.HourlyQuota += subaccount.HourlyQuota
+	if subaccount.Last30Days != nil {
+		r.Last30Days.add(subaccount.Last30Days)
+	}
+}
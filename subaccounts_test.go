@@ -0,0 +1,140 @@
+package mandrill
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const subaccountJSON = `{
+	"id": "cust-123",
+	"name": "ABC Widgets, Inc.",
+	"notes": "Free plan user, signed up on 2013-01-01 12:00:00",
+	"custom_quota": 42,
+	"status": "active",
+	"reputation": 42,
+	"created_at": "2013-01-01 15:30:27",
+	"first_sent_at": "2013-01-01 15:30:29",
+	"sent_weekly": 42,
+	"sent_monthly": 42,
+	"sent_total": 42,
+	"sent_hourly": 42,
+	"hourly_quota": 42,
+	"last_30_days": {"sent": 42, "hard_bounces": 1, "soft_bounces": 2, "rejects": 3, "complaints": 4, "unsubs": 5, "opens": 6, "unique_opens": 7, "clicks": 8, "unique_clicks": 9}
+}`
+
+// Subaccounts //////////
+
+func Test_SubaccountsList(t *testing.T) {
+	server, c, req := testRecorder(200, "["+subaccountJSON+"]")
+	defer server.Close()
+
+	subaccounts, err := c.SubaccountsList("cust-1")
+	expect(t, err, nil)
+	expect(t, req.Path, "/subaccounts/list.json")
+	expect(t, req.Payload["q"], "cust-1")
+	expect(t, len(subaccounts), 1)
+	expect(t, subaccounts[0].ID, "cust-123")
+}
+
+func Test_SubaccountsAdd(t *testing.T) {
+	server, c, req := testRecorder(200, subaccountJSON)
+	defer server.Close()
+
+	subaccount, err := c.SubaccountsAdd("cust-123", "ABC Widgets, Inc.", "Free plan", 42)
+	expect(t, err, nil)
+	expect(t, req.Path, "/subaccounts/add.json")
+	expect(t, req.Payload["id"], "cust-123")
+	expect(t, req.Payload["name"], "ABC Widgets, Inc.")
+	expect(t, req.Payload["notes"], "Free plan")
+	expect(t, req.Payload["custom_quota"], float64(42))
+	expect(t, subaccount.Status, "active")
+}
+
+func Test_SubaccountsInfo(t *testing.T) {
+	server, c, req := testRecorder(200, subaccountJSON)
+	defer server.Close()
+
+	subaccount, err := c.SubaccountsInfo("cust-123")
+	expect(t, err, nil)
+	expect(t, req.Path, "/subaccounts/info.json")
+	expect(t, req.Payload["id"], "cust-123")
+	expect(t, subaccount.HourlyQuota, 42)
+	expect(t, subaccount.Last30Days.UniqueClicks, 9)
+	expect(t, subaccount.FirstSentAt.Second(), 29)
+}
+
+func Test_SubaccountsUpdate(t *testing.T) {
+	server, c, req := testRecorder(200, subaccountJSON)
+	defer server.Close()
+
+	_, err := c.SubaccountsUpdate("cust-123", "New Name", "", 0)
+	expect(t, err, nil)
+	expect(t, req.Path, "/subaccounts/update.json")
+	expect(t, req.Payload["name"], "New Name")
+	_, hasQuota := req.Payload["custom_quota"]
+	expect(t, hasQuota, false)
+}
+
+func Test_SubaccountsDeletePauseResume(t *testing.T) {
+	server, c, req := testRecorder(200, subaccountJSON)
+	defer server.Close()
+
+	_, err := c.SubaccountsDelete("cust-123")
+	expect(t, err, nil)
+	expect(t, req.Path, "/subaccounts/delete.json")
+
+	_, err = c.SubaccountsPause("cust-123")
+	expect(t, err, nil)
+	expect(t, req.Path, "/subaccounts/pause.json")
+
+	subaccount, err := c.SubaccountsResume("cust-123")
+	expect(t, err, nil)
+	expect(t, req.Path, "/subaccounts/resume.json")
+	expect(t, req.Payload["id"], "cust-123")
+	expect(t, subaccount.ID, "cust-123")
+}
+
+// SubaccountUsage //////////
+
+func Test_SubaccountUsage(t *testing.T) {
+	var infos []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		json.NewDecoder(r.Body).Decode(&payload)
+		switch r.URL.Path {
+		case "/subaccounts/list.json":
+			fmt.Fprint(w, `[{"id":"cust-1"},{"id":"cust-2"}]`)
+		case "/subaccounts/info.json":
+			infos = append(infos, payload["id"])
+			fmt.Fprintf(w, `{"id":%q,"sent_weekly":1,"sent_monthly":10,"sent_total":100,"hourly_quota":50,"last_30_days":{"sent":10,"rejects":2}}`, payload["id"])
+		}
+	}))
+	defer server.Close()
+	c := &Client{Key: "APIKEY", BaseURL: server.URL + "/", HTTPClient: &http.Client{}}
+
+	report, err := c.SubaccountUsage(context.Background(), "cust-", time.Millisecond)
+	expect(t, err, nil)
+	expect(t, fmt.Sprint(infos), "[cust-1 cust-2]")
+	expect(t, len(report.Subaccounts), 2)
+	expect(t, report.Subaccounts[1].ID, "cust-2")
+	expect(t, report.SentWeekly, 2)
+	expect(t, report.SentMonthly, 20)
+	expect(t, report.SentTotal, 200)
+	expect(t, report.HourlyQuota, 100)
+	expect(t, report.Last30Days.Sent, 20)
+	expect(t, report.Last30Days.Rejects, 4)
+}
+
+func Test_SubaccountUsage_Error(t *testing.T) {
+	server, c := testTools(500, `{"status":"error","code":-1,"name":"Invalid_Key","message":"Invalid API key"}`)
+	defer server.Close()
+
+	report, err := c.SubaccountUsage(context.Background(), "", 0)
+	expect(t, report, (*SubaccountUsageReport)(nil))
+	expect(t, err.Error(), "Invalid API key")
+}
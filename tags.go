@@ -0,0 +1,97 @@
+package mandrill
+
+import (
+	"context"
+)
+
+// Tag holds the stats of a user-defined tag
+type Tag struct {
+	// the actual tag as a string
+	Tag string `json:"tag"`
+	// the tag's current reputation on a scale from 0 to 100
+	Reputation int `json:"reputation"`
+	// lifetime stats for the tag
+	Stats
+	// stats for recent periods (only returned by TagsInfo)
+	PeriodStats *PeriodStats `json:"stats,omitempty"`
+}
+
+// TagsList returns all of the user-defined tag information
+func (c *Client) TagsList() ([]*Tag, error) {
+	return c.TagsListContext(context.Background())
+}
+
+// TagsListContext is like TagsList but with a context
+func (c *Client) TagsListContext(ctx context.Context) (tags []*Tag, err error) {
+	var data struct {
+		Key string `json:"key"`
+	}
+
+	data.Key = c.Key
+
+	err = c.call(ctx, "tags/list.json", data, &tags)
+	return tags, err
+}
+
+// TagsInfo returns more detailed information about a single tag, including aggregates of recent stats
+func (c *Client) TagsInfo(tag string) (*Tag, error) {
+	return c.TagsInfoContext(context.Background(), tag)
+}
+
+// TagsInfoContext is like TagsInfo but with a context
+func (c *Client) TagsInfoContext(ctx context.Context, tag string) (result *Tag, err error) {
+	err = c.call(ctx, "tags/info.json", c.tagPayload(tag), &result)
+	return result, err
+}
+
+// TagsDelete deletes a tag permanently, removing its stats. Messages that were
+// sent with the tag keep it in their history.
+func (c *Client) TagsDelete(tag string) (*Tag, error) {
+	return c.TagsDeleteContext(context.Background(), tag)
+}
+
+// TagsDeleteContext is like TagsDelete but with a context
+func (c *Client) TagsDeleteContext(ctx context.Context, tag string) (result *Tag, err error) {
+	err = c.call(ctx, "tags/delete.json", c.tagPayload(tag), &result)
+	return result, err
+}
+
+// TagsTimeSeries returns the recent history (hourly stats for the last 30 days) for a tag
+func (c *Client) TagsTimeSeries(tag string) ([]*TimeSeries, error) {
+	return c.TagsTimeSeriesContext(context.Background(), tag)
+}
+
+// TagsTimeSeriesContext is like TagsTimeSeries but with a context
+func (c *Client) TagsTimeSeriesContext(ctx context.Context, tag string) (series []*TimeSeries, err error) {
+	err = c.call(ctx, "tags/time-series.json", c.tagPayload(tag), &series)
+	return series, err
+}
+
+// TagsAllTimeSeries returns the recent history (hourly stats for the last 30 days) for all tags
+func (c *Client) TagsAllTimeSeries() ([]*TimeSeries, error) {
+	return c.TagsAllTimeSeriesContext(context.Background())
+}
+
+// TagsAllTimeSeriesContext is like TagsAllTimeSeries but with a context
+func (c *Client) TagsAllTimeSeriesContext(ctx context.Context) (series []*TimeSeries, err error) {
+	var data struct {
+		Key string `json:"key"`
+	}
+
+	data.Key = c.Key
+
+	err = c.call(ctx, "tags/all-time-series.json", data, &series)
+	return series, err
+}
+
+func (c *Client) tagPayload(tag string) interface{} {
+	var data struct {
+		Key string `json:"key"`
+		Tag string `json:"tag"`
+	}
+
+	data.Key = c.Key
+	data.Tag = tag
+
+	return data
+}
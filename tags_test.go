@@ -0,0 +1,67 @@
+package mandrill
+
+import (
+	"testing"
+	"time"
+)
+
+const tagStatsJSON = `"sent":42,"hard_bounces":1,"soft_bounces":2,"rejects":3,"complaints":4,"unsubs":5,"opens":6,"clicks":7,"unique_opens":8,"unique_clicks":9`
+
+const tagJSON = `{"tag":"example-tag","reputation":42,` + tagStatsJSON + `,"stats":{"today":{` + tagStatsJSON + `},"last_7_days":{` + tagStatsJSON + `},"last_30_days":{"sent":30},"last_60_days":{` + tagStatsJSON + `},"last_90_days":{` + tagStatsJSON + `}}}`
+
+const timeSeriesJSON = `[{"time":"2013-01-01 15:00:00",` + tagStatsJSON + `}]`
+
+// Tags //////////
+
+func Test_TagsList(t *testing.T) {
+	server, c, req := testRecorder(200, `[{"tag":"example-tag","reputation":42,`+tagStatsJSON+`}]`)
+	defer server.Close()
+
+	tags, err := c.TagsList()
+	expect(t, err, nil)
+	expect(t, req.Path, "/tags/list.json")
+	expect(t, tags[0].Tag, "example-tag")
+	expect(t, tags[0].Sent, 42)
+	expect(t, tags[0].UniqueClicks, 9)
+	expect(t, tags[0].PeriodStats, (*PeriodStats)(nil))
+}
+
+func Test_TagsInfo(t *testing.T) {
+	server, c, req := testRecorder(200, tagJSON)
+	defer server.Close()
+
+	tag, err := c.TagsInfo("example-tag")
+	expect(t, err, nil)
+	expect(t, req.Path, "/tags/info.json")
+	expect(t, req.Payload["tag"], "example-tag")
+	expect(t, tag.Reputation, 42)
+	expect(t, tag.PeriodStats.Last30Days.Sent, 30)
+	expect(t, tag.PeriodStats.Today.Opens, 6)
+}
+
+func Test_TagsDelete(t *testing.T) {
+	server, c, req := testRecorder(200, tagJSON)
+	defer server.Close()
+
+	tag, err := c.TagsDelete("example-tag")
+	expect(t, err, nil)
+	expect(t, req.Path, "/tags/delete.json")
+	expect(t, tag.Tag, "example-tag")
+}
+
+func Test_TagsTimeSeries(t *testing.T) {
+	server, c, req := testRecorder(200, timeSeriesJSON)
+	defer server.Close()
+
+	series, err := c.TagsTimeSeries("example-tag")
+	expect(t, err, nil)
+	expect(t, req.Path, "/tags/time-series.json")
+	expect(t, req.Payload["tag"], "example-tag")
+	expect(t, series[0].Time.Equal(time.Date(2013, 1, 1, 15, 0, 0, 0, time.UTC)), true)
+	expect(t, series[0].HardBounces, 1)
+
+	series, err = c.TagsAllTimeSeries()
+	expect(t, err, nil)
+	expect(t, req.Path, "/tags/all-time-series.json")
+	expect(t, series[0].Clicks, 7)
+}
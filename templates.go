@@ -0,0 +1,236 @@
+package mandrill
+
+import (
+	"context"
+	"fmt"
+)
+
+// TestTemplatePrefix is prepended to the subject of messages sent by TestSendTemplate
+const TestTemplatePrefix = "[TEST] "
+
+// TestTemplateTag is added to messages sent by TestSendTemplate
+const TestTemplateTag = "template-test"
+
+// Template is a Mandrill template, with both its draft and published versions
+type Template struct {
+	// the immutable unique code name of the template
+	Slug string `json:"slug,omitempty"`
+	// the name of the template
+	Name string `json:"name"`
+	// the list of labels applied to the template
+	Labels []string `json:"labels,omitempty"`
+	// the full HTML code of the template, with mc:edit attributes marking the editable elements - draft version
+	Code string `json:"code,omitempty"`
+	// the subject line of the template, if provided - draft version
+	Subject string `json:"subject,omitempty"`
+	// the default sender address for the template, if provided - draft version
+	FromEmail string `json:"from_email,omitempty"`
+	// the default sender from name for the template, if provided - draft version
+	FromName string `json:"from_name,omitempty"`
+	// the default text part of messages sent with the template, if provided - draft version
+	Text string `json:"text,omitempty"`
+	// the same as the template name - kept as a separate field for backwards compatibility
+	PublishName string `json:"publish_name,omitempty"`
+	// the full HTML code of the template, with mc:edit attributes marking the editable elements that are available as published, if it has been published
+	PublishCode string `json:"publish_code,omitempty"`
+	// the subject line of the template, if provided
+	PublishSubject string `json:"publish_subject,omitempty"`
+	// the default sender address for the template, if provided
+	PublishFromEmail string `json:"publish_from_email,omitempty"`
+	// the default sender from name for the template, if provided
+	PublishFromName string `json:"publish_from_name,omitempty"`
+	// the default text part of messages sent with the template, if provided
+	PublishText string `json:"publish_text,omitempty"`
+	// the date and time the template was last published, or zero if it has not been published
+	PublishedAt Time `json:"published_at"`
+	// the date and time the template was first created
+	CreatedAt Time `json:"created_at"`
+	// the date and time the template was last modified
+	UpdatedAt Time `json:"updated_at"`
+}
+
+type templatePayload struct {
+	Key       string   `json:"key"`
+	Name      string   `json:"name"`
+	FromEmail string   `json:"from_email,omitempty"`
+	FromName  string   `json:"from_name,omitempty"`
+	Subject   string   `json:"subject,omitempty"`
+	Code      string   `json:"code,omitempty"`
+	Text      string   `json:"text,omitempty"`
+	Publish   bool     `json:"publish"`
+	Labels    []string `json:"labels,omitempty"`
+}
+
+func (c *Client) newTemplatePayload(template *Template, publish bool) *templatePayload {
+	return &templatePayload{
+		Key:       c.Key,
+		Name:      template.Name,
+		FromEmail: template.FromEmail,
+		FromName:  template.FromName,
+		Subject:   template.Subject,
+		Code:      template.Code,
+		Text:      template.Text,
+		Publish:   publish,
+		Labels:    template.Labels,
+	}
+}
+
+// TemplatesAdd adds a new template from the draft fields of template (Name, Code,
+// Subject, FromEmail, FromName, Text and Labels), optionally publishing it
+func (c *Client) TemplatesAdd(template *Template, publish bool) (*Template, error) {
+	return c.TemplatesAddContext(context.Background(), template, publish)
+}
+
+// TemplatesAddContext is like TemplatesAdd but with a context
+func (c *Client) TemplatesAddContext(ctx context.Context, template *Template, publish bool) (result *Template, err error) {
+	err = c.call(ctx, "templates/add.json", c.newTemplatePayload(template, publish), &result)
+	return result, err
+}
+
+// TemplatesInfo gets the information for an existing template
+func (c *Client) TemplatesInfo(name string) (*Template, error) {
+	return c.TemplatesInfoContext(context.Background(), name)
+}
+
+// TemplatesInfoContext is like TemplatesInfo but with a context
+func (c *Client) TemplatesInfoContext(ctx context.Context, name string) (template *Template, err error) {
+	var data struct {
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	}
+
+	data.Key = c.Key
+	data.Name = name
+
+	err = c.call(ctx, "templates/info.json", data, &template)
+	return template, err
+}
+
+// TemplatesUpdate updates the draft fields of an existing template, optionally
+// publishing it. Empty fields are left unchanged.
+func (c *Client) TemplatesUpdate(template *Template, publish bool) (*Template, error) {
+	return c.TemplatesUpdateContext(context.Background(), template, publish)
+}
+
+// TemplatesUpdateContext is like TemplatesUpdate but with a context
+func (c *Client) TemplatesUpdateContext(ctx context.Context, template *Template, publish bool) (result *Template, err error) {
+	err = c.call(ctx, "templates/update.json", c.newTemplatePayload(template, publish), &result)
+	return result, err
+}
+
+// TemplatesPublish publishes the current draft of a template
+func (c *Client) TemplatesPublish(name string) (*Template, error) {
+	return c.TemplatesPublishContext(context.Background(), name)
+}
+
+// TemplatesPublishContext is like TemplatesPublish but with a context
+func (c *Client) TemplatesPublishContext(ctx context.Context, name string) (template *Template, err error) {
+	var data struct {
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	}
+
+	data.Key = c.Key
+	data.Name = name
+
+	err = c.call(ctx, "templates/publish.json", data, &template)
+	return template, err
+}
+
+// TemplatesDelete deletes a template and returns it
+func (c *Client) TemplatesDelete(name string) (*Template, error) {
+	return c.TemplatesDeleteContext(context.Background(), name)
+}
+
+// TemplatesDeleteContext is like TemplatesDelete but with a context
+func (c *Client) TemplatesDeleteContext(ctx context.Context, name string) (template *Template, err error) {
+	var data struct {
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	}
+
+	data.Key = c.Key
+	data.Name = name
+
+	err = c.call(ctx, "templates/delete.json", data, &template)
+	return template, err
+}
+
+// TemplatesList returns the templates, optionally filtered to those with label
+func (c *Client) TemplatesList(label string) ([]*Template, error) {
+	return c.TemplatesListContext(context.Background(), label)
+}
+
+// TemplatesListContext is like TemplatesList but with a context
+func (c *Client) TemplatesListContext(ctx context.Context, label string) (templates []*Template, err error) {
+	var data struct {
+		Key   string `json:"key"`
+		Label string `json:"label,omitempty"`
+	}
+
+	data.Key = c.Key
+	data.Label = label
+
+	templates = make([]*Template, 0)
+	err = c.call(ctx, "templates/list.json", data, &templates)
+	return templates, err
+}
+
+// TemplatesRender injects content and optionally merge fields into a template,
+// returning the HTML that results. templateContent and mergeVars take the same
+// values as MessagesSendTemplate's contents.
+func (c *Client) TemplatesRender(templateName string, templateContent interface{}, mergeVars interface{}) (string, error) {
+	return c.TemplatesRenderContext(context.Background(), templateName, templateContent, mergeVars)
+}
+
+// TemplatesRenderContext is like TemplatesRender but with a context
+func (c *Client) TemplatesRenderContext(ctx context.Context, templateName string, templateContent interface{}, mergeVars interface{}) (html string, err error) {
+	var data struct {
+		Key             string      `json:"key"`
+		TemplateName    string      `json:"template_name"`
+		TemplateContent []*Variable `json:"template_content"`
+		MergeVars       []*Variable `json:"merge_vars,omitempty"`
+	}
+
+	data.Key = c.Key
+	data.TemplateName = templateName
+	if data.TemplateContent, err = ConvertTemplateContent(templateContent); err != nil {
+		return html, err
+	}
+	if data.MergeVars, err = ConvertTemplateContent(mergeVars); err != nil {
+		return html, err
+	}
+
+	var result struct {
+		HTML string `json:"html"`
+	}
+	err = c.call(ctx, "templates/render.json", data, &result)
+	return result.HTML, err
+}
+
+// TestSendTemplate sends the published template to testAddress with sampleVars
+// as global merge vars, marking the subject with TestTemplatePrefix and tagging
+// the message with TestTemplateTag
+func (c *Client) TestSendTemplate(ctx context.Context, templateName string, sampleVars map[string]interface{}, testAddress string) (responses []*Response, err error) {
+	template, err := c.TemplatesInfoContext(ctx, templateName)
+	if err != nil {
+		return responses, err
+	}
+	if template == nil {
+		return responses, fmt.Errorf("mandrill: template %q not found in response", templateName)
+	}
+
+	subject := template.PublishSubject
+	if subject == "" {
+		subject = templateName
+	}
+
+	message := &Message{
+		Subject:         TestTemplatePrefix + subject,
+		Tags:            []string{TestTemplateTag},
+		GlobalMergeVars: ConvertMapToVariables(sampleVars),
+	}
+	message.AddRecipient(testAddress, "", "to")
+
+	return c.MessagesSendTemplateContext(ctx, message, templateName, nil)
+}
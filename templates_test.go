@@ -0,0 +1,183 @@
+package mandrill
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const templateJSON = `{
+	"slug": "example-template",
+	"name": "Example Template",
+	"labels": ["example-label"],
+	"code": "editable content",
+	"subject": "example subject",
+	"from_email": "from.email@example.com",
+	"from_name": "Example Name",
+	"text": "Example text",
+	"publish_name": "Example Template",
+	"publish_code": "different than draft content",
+	"publish_subject": "example publish_subject",
+	"publish_from_email": "from.email.published@example.com",
+	"publish_from_name": "Example Published Name",
+	"publish_text": "Example published text",
+	"published_at": "2013-01-01 15:30:40",
+	"created_at": "2013-01-01 15:30:27",
+	"updated_at": "2013-01-01 15:30:49"
+}`
+
+// Templates //////////
+
+func Test_TemplatesAdd(t *testing.T) {
+	server, c, req := testRecorder(200, templateJSON)
+	defer server.Close()
+
+	template, err := c.TemplatesAdd(&Template{Name: "Example Template", Code: "editable content", Labels: []string{"example-label"}}, true)
+	expect(t, err, nil)
+	expect(t, req.Path, "/templates/add.json")
+	expect(t, req.Payload["key"], "APIKEY")
+	expect(t, req.Payload["name"], "Example Template")
+	expect(t, req.Payload["code"], "editable content")
+	expect(t, req.Payload["publish"], true)
+	expect(t, reflect.DeepEqual(req.Payload["labels"], []interface{}{"example-label"}), true)
+
+	expect(t, template.Slug, "example-template")
+	expect(t, template.PublishCode, "different than draft content")
+	expect(t, template.CreatedAt.Equal(time.Date(2013, 1, 1, 15, 30, 27, 0, time.UTC)), true)
+	expect(t, template.PublishedAt.Equal(time.Date(2013, 1, 1, 15, 30, 40, 0, time.UTC)), true)
+}
+
+func Test_TemplatesInfo(t *testing.T) {
+	server, c, req := testRecorder(200, templateJSON)
+	defer server.Close()
+
+	template, err := c.TemplatesInfo("Example Template")
+	expect(t, err, nil)
+	expect(t, req.Path, "/templates/info.json")
+	expect(t, req.Payload["name"], "Example Template")
+	expect(t, template.Name, "Example Template")
+}
+
+func Test_TemplatesInfo_Fail(t *testing.T) {
+	server, c, _ := testRecorder(500, `{"status":"error","code":5,"name":"Unknown_Template","message":"No such template \"nope\""}`)
+	defer server.Close()
+
+	template, err := c.TemplatesInfo("nope")
+	expect(t, template, (*Template)(nil))
+	expect(t, err.(*Error).Name, "Unknown_Template")
+}
+
+func Test_TemplatesUpdate(t *testing.T) {
+	server, c, req := testRecorder(200, templateJSON)
+	defer server.Close()
+
+	_, err := c.TemplatesUpdate(&Template{Name: "Example Template", Subject: "New"}, false)
+	expect(t, err, nil)
+	expect(t, req.Path, "/templates/update.json")
+	expect(t, req.Payload["subject"], "New")
+	expect(t, req.Payload["publish"], false)
+	_, hasCode := req.Payload["code"]
+	expect(t, hasCode, false)
+}
+
+func Test_TemplatesPublish(t *testing.T) {
+	server, c, req := testRecorder(200, templateJSON)
+	defer server.Close()
+
+	template, err := c.TemplatesPublish("Example Template")
+	expect(t, err, nil)
+	expect(t, req.Path, "/templates/publish.json")
+	expect(t, template.PublishName, "Example Template")
+}
+
+func Test_TemplatesDelete(t *testing.T) {
+	server, c, req := testRecorder(200, templateJSON)
+	defer server.Close()
+
+	template, err := c.TemplatesDelete("Example Template")
+	expect(t, err, nil)
+	expect(t, req.Path, "/templates/delete.json")
+	expect(t, template.Slug, "example-template")
+}
+
+func Test_TemplatesList(t *testing.T) {
+	server, c, req := testRecorder(200, "["+templateJSON+"]")
+	defer server.Close()
+
+	templates, err := c.TemplatesList("example-label")
+	expect(t, err, nil)
+	expect(t, req.Path, "/templates/list.json")
+	expect(t, req.Payload["label"], "example-label")
+	expect(t, len(templates), 1)
+	expect(t, templates[0].Slug, "example-template")
+}
+
+func Test_TemplatesRender(t *testing.T) {
+	server, c, req := testRecorder(200, `{"html":"<h1>Hi Bob</h1>"}`)
+	defer server.Close()
+
+	html, err := c.TemplatesRender("welcome", map[string]string{"header": "Hi"}, []*Variable{{"FNAME", "Bob"}})
+	expect(t, err, nil)
+	expect(t, html, "<h1>Hi Bob</h1>")
+	expect(t, req.Path, "/templates/render.json")
+	expect(t, req.Payload["template_name"], "welcome")
+	expect(t, reflect.DeepEqual(req.Payload["template_content"], []interface{}{map[string]interface{}{"name": "header", "content": "Hi"}}), true)
+	expect(t, reflect.DeepEqual(req.Payload["merge_vars"], []interface{}{map[string]interface{}{"name": "FNAME", "content": "Bob"}}), true)
+
+	_, err = c.TemplatesRender("welcome", nil, "nope")
+	refute(t, err, nil)
+}
+
+// TestSendTemplate //////////
+
+func Test_TestSendTemplate(t *testing.T) {
+	var sent struct {
+		TemplateName string   `json:"template_name"`
+		Message      *Message `json:"message"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "templates/info.json") {
+			fmt.Fprintln(w, `{"name":"welcome","subject":"Draft","publish_subject":"Welcome *|FNAME|*"}`)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&sent)
+		fmt.Fprintln(w, `[{"email":"qa@example.com","status":"sent","_id":"1"}]`)
+	}))
+	defer server.Close()
+
+	c := ClientWithKey("APIKEY")
+	c.BaseURL = server.URL + "/"
+	responses, err := c.TestSendTemplate(context.Background(), "welcome", map[string]interface{}{"FNAME": "Bob"}, "qa@example.com")
+
+	expect(t, err, nil)
+	expect(t, responses[0].Email, "qa@example.com")
+	expect(t, sent.TemplateName, "welcome")
+	expect(t, sent.Message.Subject, "[TEST] Welcome *|FNAME|*")
+	expect(t, sent.Message.Tags[0], TestTemplateTag)
+	expect(t, sent.Message.To[0].Email, "qa@example.com")
+	expect(t, sent.Message.GlobalMergeVars[0].Name, "FNAME")
+}
+
+func Test_TestSendTemplate_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ClientWithKey("SANDBOX_SUCCESS").TestSendTemplate(ctx, "welcome", nil, "qa@example.com")
+	expect(t, errors.Is(err, context.Canceled), true)
+}
+
+func Test_TestSendTemplate_BadResponse(t *testing.T) {
+	for _, body := range []string{`null`, `"welcome"`} {
+		server, c, _ := testRecorder(200, body)
+		_, err := c.TestSendTemplate(context.Background(), "welcome", nil, "qa@example.com")
+		refute(t, err, nil)
+		server.Close()
+	}
+}
@@ -0,0 +1,59 @@
+package mandrill
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	hiddenBlockPattern = regexp.MustCompile(`(?is)<!--.*?-->|<(head|script|style|title)\b[^>]*>.*?</(head|script|style|title)\s*>`)
+	whitespacePattern  = regexp.MustCompile(`\s+`)
+	linkPattern        = regexp.MustCompile(`(?is)<a\b[^>]*?\bhref\s*=\s*(?:"([^"]*)"|'([^']*)')[^>]*>(.*?)</a\s*>`)
+	lineBreakPattern   = regexp.MustCompile(`(?i)<br\s*/?>|</?(div|tr|ul|ol|table|blockquote)\b[^>]*>`)
+	paragraphPattern   = regexp.MustCompile(`(?i)</?(p|h[1-6])\b[^>]*>`)
+	listItemPattern    = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+	tagPattern         = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLinesPattern  = regexp.MustCompile(`\n{3,}`)
+)
+
+// GenerateTextFromHTML fills the message Text with a plain-text version of its
+// HTML, as an alternative to AutoText that can be reviewed before sending.
+// Tags are stripped, links are kept as "label (url)", and line breaks,
+// paragraphs and list items become new lines. A preheader set with
+// SetPreheader is left out.
+func (m *Message) GenerateTextFromHTML() {
+	m.Text = htmlToText(m.HTML)
+}
+
+func htmlToText(s string) string {
+	s = preheaderPattern.ReplaceAllString(s, "")
+	s = hiddenBlockPattern.ReplaceAllString(s, "")
+	s = whitespacePattern.ReplaceAllString(s, " ")
+
+	s = linkPattern.ReplaceAllStringFunc(s, func(match string) string {
+		parts := linkPattern.FindStringSubmatch(match)
+		href := html.UnescapeString(parts[1] + parts[2])
+		label := strings.TrimSpace(tagPattern.ReplaceAllString(parts[3], ""))
+		if href == "" || strings.HasPrefix(href, "#") || html.UnescapeString(label) == href {
+			return label
+		}
+		if label == "" {
+			return href
+		}
+		return label + " (" + href + ")"
+	})
+
+	s = lineBreakPattern.ReplaceAllString(s, "\n")
+	s = paragraphPattern.ReplaceAllString(s, "\n\n")
+	s = listItemPattern.ReplaceAllString(s, "\n- ")
+	s = tagPattern.ReplaceAllString(s, "")
+	s = strings.ReplaceAll(html.UnescapeString(s), "\u00a0", " ")
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(whitespacePattern.ReplaceAllString(line, " "))
+	}
+	s = blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(s)
+}
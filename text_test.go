@@ -0,0 +1,35 @@
+package mandrill
+
+import (
+	"testing"
+)
+
+// GenerateTextFromHTML //////////
+
+func Test_GenerateTextFromHTML(t *testing.T) {
+	m := &Message{HTML: `<html>
+<head><title>Welcome</title><style>p { color: red; }</style></head>
+<body>
+  <h1>Hi   Bob</h1>
+  <p>Thanks for signing up.<br>Your account is ready.</p>
+  <!-- footer -->
+  <ul><li>Read the <a href="https://example.com/docs?a=1&amp;b=2">docs</a></li><li><a href="https://example.com">https://example.com</a></li></ul>
+  <p>Fish &amp; chips&nbsp;today</p>
+</body>
+</html>`}
+	m.GenerateTextFromHTML()
+	expect(t, m.Text, "Hi Bob\n\nThanks for signing up.\nYour account is ready.\n\n- Read the docs (https://example.com/docs?a=1&b=2)\n- https://example.com\n\nFish & chips today")
+}
+
+func Test_GenerateTextFromHTML_Preheader(t *testing.T) {
+	m := &Message{HTML: `<body><p>Hello</p></body>`}
+	m.SetPreheader("Preview text")
+	m.GenerateTextFromHTML()
+	expect(t, m.Text, "Hello")
+}
+
+func Test_GenerateTextFromHTML_Empty(t *testing.T) {
+	m := &Message{Text: "stale"}
+	m.GenerateTextFromHTML()
+	expect(t, m.Text, "")
+}
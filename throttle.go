@@ -0,0 +1,122 @@
+package mandrill
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultThrottleWait is how long a throttled request waits before retrying when the response has no Retry-After
+const DefaultThrottleWait = time.Second
+
+// ThrottledError is returned when Mandrill responds with HTTP 429 Too Many Requests
+type ThrottledError struct {
+	// how long Mandrill asked the client to wait before retrying, or DefaultThrottleWait if the response didn't say
+	RetryAfter time.Duration
+	// the API error in the response
+	Err *Error
+}
+
+// Error describes the throttling
+func (err *ThrottledError) Error() string {
+	if err.RetryAfter > 0 {
+		return fmt.Sprintf("mandrill: throttled, retry after %s", err.RetryAfter)
+	}
+	return "mandrill: throttled"
+}
+
+// Unwrap returns the API error
+func (err *ThrottledError) Unwrap() error {
+	return err.Err
+}
+
+// wait sleeps for RetryAfter unless ctx is done first
+func (err *ThrottledError) wait(ctx context.Context) error {
+	timer := time.NewTimer(err.RetryAfter)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RetryBudgetError is returned instead of retrying a throttled request when the client's RetryBudget is spent
+type RetryBudgetError struct {
+	// the throttling that would have been retried
+	Throttled *ThrottledError
+}
+
+// Error describes the exhausted budget
+func (err *RetryBudgetError) Error() string {
+	return "mandrill: retry budget exhausted: " + err.Throttled.Error()
+}
+
+// Unwrap returns the throttling error
+func (err *RetryBudgetError) Unwrap() error {
+	return err.Throttled
+}
+
+// RetryBudget caps the retries made across all of a client's calls, so per-call
+// ThrottleRetries can't multiply traffic while Mandrill is struggling. It's a
+// token bucket: each retry spends a token, and tokens refill at Rate per second
+// up to Burst, starting full. Once it's empty, throttled calls fail fast with a
+// *RetryBudgetError. Give several clients the same budget to cap them together.
+type RetryBudget struct {
+	// tokens added per second
+	Rate float64
+	// the most tokens the budget holds
+	Burst int
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// take spends a token if one is left
+func (b *RetryBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	current := now()
+	if b.last.IsZero() {
+		b.tokens = float64(b.Burst)
+	} else {
+		b.tokens += current.Sub(b.last).Seconds() * b.Rate
+		if b.tokens > float64(b.Burst) {
+			b.tokens = float64(b.Burst)
+		}
+	}
+	b.last = current
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date.
+// A missing or unreadable header gives DefaultThrottleWait.
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := at.Sub(now()); d > 0 {
+			return d
+		}
+		return 0
+	}
+	return DefaultThrottleWait
+}
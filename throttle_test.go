@@ -0,0 +1,147 @@
+package mandrill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func throttlingServer(throttled int32, retryAfter string) (*httptest.Server, *Client, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= throttled {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"status":"error","code":-1,"name":"Too_Many_Requests","message":"Slow down"}`)
+			return
+		}
+		fmt.Fprint(w, `"PONG!"`)
+	}))
+	client := &Client{Key: "APIKEY", BaseURL: server.URL + "/", HTTPClient: &http.Client{}}
+	return server, client, &calls
+}
+
+// Throttling //////////
+
+func Test_Throttled_Error(t *testing.T) {
+	server, c, calls := throttlingServer(1, "30")
+	defer server.Close()
+
+	_, err := c.Ping()
+	throttled, ok := err.(*ThrottledError)
+	expect(t, ok, true)
+	expect(t, throttled.RetryAfter, 30*time.Second)
+	expect(t, throttled.Err.Name, "Too_Many_Requests")
+	expect(t, throttled.Err.StatusCode, http.StatusTooManyRequests)
+	expect(t, err.Error(), "mandrill: throttled, retry after 30s")
+	expect(t, *calls, int32(1))
+
+	var apiErr *Error
+	expect(t, errors.As(err, &apiErr), true)
+}
+
+func Test_Throttled_Retry(t *testing.T) {
+	server, c, calls := throttlingServer(2, "0")
+	defer server.Close()
+	c.ThrottleRetries = 2
+
+	pong, err := c.Ping()
+	expect(t, err, nil)
+	expect(t, pong, "PONG!")
+	expect(t, *calls, int32(3))
+}
+
+func Test_Throttled_NoRetryAfter(t *testing.T) {
+	server, c, _ := throttlingServer(1, "")
+	defer server.Close()
+
+	_, err := c.Ping()
+	expect(t, err.(*ThrottledError).RetryAfter, DefaultThrottleWait)
+}
+
+func Test_Throttled_RetriesExhausted(t *testing.T) {
+	server, c, calls := throttlingServer(5, "0")
+	defer server.Close()
+	c.ThrottleRetries = 1
+
+	_, err := c.Ping()
+	_, ok := err.(*ThrottledError)
+	expect(t, ok, true)
+	expect(t, *calls, int32(2))
+}
+
+func Test_Throttled_RetryCanceled(t *testing.T) {
+	server, c, calls := throttlingServer(5, "60")
+	defer server.Close()
+	c.ThrottleRetries = 3
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := c.PingContext(ctx)
+	expect(t, err, context.DeadlineExceeded)
+	expect(t, *calls, int32(1))
+}
+
+func Test_RetryBudget(t *testing.T) {
+	current := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	b := &RetryBudget{Rate: 0.5, Burst: 2}
+	expect(t, b.take(), true)
+	expect(t, b.take(), true)
+	expect(t, b.take(), false)
+
+	current = current.Add(time.Second)
+	expect(t, b.take(), false)
+	current = current.Add(time.Second)
+	expect(t, b.take(), true)
+
+	current = current.Add(time.Hour)
+	expect(t, b.take(), true)
+	expect(t, b.take(), true)
+	expect(t, b.take(), false)
+}
+
+func Test_Throttled_RetryBudget(t *testing.T) {
+	server, c, calls := throttlingServer(10, "0")
+	defer server.Close()
+	c.ThrottleRetries = 5
+	c.RetryBudget = &RetryBudget{Burst: 2}
+
+	_, err := c.Ping()
+	budgetErr, ok := err.(*RetryBudgetError)
+	expect(t, ok, true)
+	expect(t, budgetErr.Throttled.Err.Name, "Too_Many_Requests")
+	expect(t, err.Error(), "mandrill: retry budget exhausted: mandrill: throttled")
+	expect(t, *calls, int32(3))
+
+	var throttled *ThrottledError
+	expect(t, errors.As(err, &throttled), true)
+
+	// the next call fails on its first throttling
+	_, err = c.Ping()
+	_, ok = err.(*RetryBudgetError)
+	expect(t, ok, true)
+	expect(t, *calls, int32(4))
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	freezeNow(t, time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC))
+
+	expect(t, parseRetryAfter(""), DefaultThrottleWait)
+	expect(t, parseRetryAfter("120"), 2*time.Minute)
+	expect(t, parseRetryAfter("-1"), time.Duration(0))
+	expect(t, parseRetryAfter("Wed, 21 Oct 2015 07:28:30 GMT"), 30*time.Second)
+	expect(t, parseRetryAfter("Wed, 21 Oct 2015 07:27:00 GMT"), time.Duration(0))
+	expect(t, parseRetryAfter("0"), time.Duration(0))
+	expect(t, parseRetryAfter("soon"), DefaultThrottleWait)
+}
@@ -0,0 +1,23 @@
+package mandrill
+
+import (
+	"context"
+	"time"
+)
+
+type timeoutKey struct{}
+
+// WithTimeout returns a context that limits each API request made with it to d,
+// overriding Client.Timeout. Unlike context.WithTimeout the limit applies to every
+// request separately, e.g. to each retry of a throttled request.
+func WithTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, d)
+}
+
+// requestTimeout returns the time limit for a request made with ctx
+func (c *Client) requestTimeout(ctx context.Context) time.Duration {
+	if d, ok := ctx.Value(timeoutKey{}).(time.Duration); ok {
+		return d
+	}
+	return c.Timeout
+}
@@ -0,0 +1,87 @@
+package mandrill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func slowServer(delay time.Duration) (*httptest.Server, *Client) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+		}
+		fmt.Fprint(w, `"PONG!"`)
+	}))
+	client := &Client{Key: "APIKEY", BaseURL: server.URL + "/", HTTPClient: &http.Client{}}
+	return server, client
+}
+
+// Timeouts //////////
+
+func Test_Timeout_Client(t *testing.T) {
+	server, c := slowServer(200 * time.Millisecond)
+	defer server.Close()
+	c.Timeout = 20 * time.Millisecond
+
+	_, err := c.Ping()
+	expect(t, errors.Is(err, context.DeadlineExceeded), true)
+}
+
+func Test_Timeout_WithTimeout(t *testing.T) {
+	server, c := slowServer(200 * time.Millisecond)
+	defer server.Close()
+
+	_, err := c.PingContext(WithTimeout(context.Background(), 20*time.Millisecond))
+	expect(t, errors.Is(err, context.DeadlineExceeded), true)
+}
+
+func Test_Timeout_WithTimeoutOverridesClient(t *testing.T) {
+	server, c := slowServer(50 * time.Millisecond)
+	defer server.Close()
+	c.Timeout = 10 * time.Millisecond
+
+	pong, err := c.PingContext(WithTimeout(context.Background(), time.Second))
+	expect(t, err, nil)
+	expect(t, pong, "PONG!")
+}
+
+func Test_Timeout_Unset(t *testing.T) {
+	server, c := slowServer(20 * time.Millisecond)
+	defer server.Close()
+
+	pong, err := c.Ping()
+	expect(t, err, nil)
+	expect(t, pong, "PONG!")
+}
+
+func Test_Timeout_PerAttempt(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(40 * time.Millisecond)
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"status":"error","name":"Too_Many_Requests"}`)
+			return
+		}
+		fmt.Fprint(w, `"PONG!"`)
+	}))
+	defer server.Close()
+	c := &Client{Key: "APIKEY", BaseURL: server.URL + "/", HTTPClient: &http.Client{}, ThrottleRetries: 2}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// three 40ms attempts outlast the 100ms limit together but not one by one
+	pong, err := c.PingContext(WithTimeout(ctx, 100*time.Millisecond))
+	expect(t, err, nil)
+	expect(t, pong, "PONG!")
+	expect(t, atomic.LoadInt32(&attempts), int32(3))
+}
@@ -0,0 +1,137 @@
+package mandrill
+
+import (
+	"context"
+)
+
+// URL holds the click stats of a tracked URL
+type URL struct {
+	// the URL to be tracked
+	URL string `json:"url"`
+	// the number of emails that contained the URL
+	Sent int `json:"sent"`
+	// the number of times the URL has been clicked from a tracked email
+	Clicks int `json:"clicks"`
+	// the number of unique emails that have generated clicks for this URL
+	UniqueClicks int `json:"unique_clicks"`
+}
+
+// TrackingDomain is a custom domain used for click and open tracking
+type TrackingDomain struct {
+	// the tracking domain name
+	Domain string `json:"domain"`
+	// the date and time that the tracking domain was added as a UTC string in YYYY-MM-DD HH:MM:SS format
+	CreatedAt Time `json:"created_at"`
+	// when the domain's DNS settings were last tested
+	LastTestedAt Time `json:"last_tested_at"`
+	// details about the domain's CNAME record
+	CNAME *DomainRecord `json:"cname,omitempty"`
+	// whether this domain can be used as a tracking domain for email
+	ValidTracking bool `json:"valid_tracking"`
+}
+
+// URLsList returns the 100 most clicked URLs
+func (c *Client) URLsList() ([]*URL, error) {
+	return c.URLsListContext(context.Background())
+}
+
+// URLsListContext is like URLsList but with a context
+func (c *Client) URLsListContext(ctx context.Context) (urls []*URL, err error) {
+	var data struct {
+		Key string `json:"key"`
+	}
+
+	data.Key = c.Key
+
+	err = c.call(ctx, "urls/list.json", data, &urls)
+	return urls, err
+}
+
+// URLsSearch returns the 100 most clicked URLs that match the search query given
+func (c *Client) URLsSearch(q string) ([]*URL, error) {
+	return c.URLsSearchContext(context.Background(), q)
+}
+
+// URLsSearchContext is like URLsSearch but with a context
+func (c *Client) URLsSearchContext(ctx context.Context, q string) (urls []*URL, err error) {
+	var data struct {
+		Key string `json:"key"`
+		Q   string `json:"q"`
+	}
+
+	data.Key = c.Key
+	data.Q = q
+
+	err = c.call(ctx, "urls/search.json", data, &urls)
+	return urls, err
+}
+
+// URLsTimeSeries returns the recent history (hourly stats for the last 30 days) for a url.
+// Only Sent, Clicks and UniqueClicks are set on each entry.
+func (c *Client) URLsTimeSeries(url string) ([]*TimeSeries, error) {
+	return c.URLsTimeSeriesContext(context.Background(), url)
+}
+
+// URLsTimeSeriesContext is like URLsTimeSeries but with a context
+func (c *Client) URLsTimeSeriesContext(ctx context.Context, url string) (series []*TimeSeries, err error) {
+	var data struct {
+		Key string `json:"key"`
+		URL string `json:"url"`
+	}
+
+	data.Key = c.Key
+	data.URL = url
+
+	err = c.call(ctx, "urls/time-series.json", data, &series)
+	return series, err
+}
+
+// URLsTrackingDomains returns the tracking domains that have been added to the account
+func (c *Client) URLsTrackingDomains() ([]*TrackingDomain, error) {
+	return c.URLsTrackingDomainsContext(context.Background())
+}
+
+// URLsTrackingDomainsContext is like URLsTrackingDomains but with a context
+func (c *Client) URLsTrackingDomainsContext(ctx context.Context) (domains []*TrackingDomain, err error) {
+	var data struct {
+		Key string `json:"key"`
+	}
+
+	data.Key = c.Key
+
+	err = c.call(ctx, "urls/tracking-domains.json", data, &domains)
+	return domains, err
+}
+
+// URLsAddTrackingDomain adds a tracking domain to the account
+func (c *Client) URLsAddTrackingDomain(domain string) (*TrackingDomain, error) {
+	return c.URLsAddTrackingDomainContext(context.Background(), domain)
+}
+
+// URLsAddTrackingDomainContext is like URLsAddTrackingDomain but with a context
+func (c *Client) URLsAddTrackingDomainContext(ctx context.Context, domain string) (*TrackingDomain, error) {
+	return c.trackingDomainCall(ctx, "urls/add-tracking-domain.json", domain)
+}
+
+// URLsCheckTrackingDomain checks the CNAME settings for a tracking domain. The domain must have been added already.
+func (c *Client) URLsCheckTrackingDomain(domain string) (*TrackingDomain, error) {
+	return c.URLsCheckTrackingDomainContext(context.Background(), domain)
+}
+
+// URLsCheckTrackingDomainContext is like URLsCheckTrackingDomain but with a context
+func (c *Client) URLsCheckTrackingDomainContext(ctx context.Context, domain string) (*TrackingDomain, error) {
+	return c.trackingDomainCall(ctx, "urls/check-tracking-domain.json", domain)
+}
+
+func (c *Client) trackingDomainCall(ctx context.Context, path string, domain string) (result *TrackingDomain, err error) {
+	var data struct {
+		Key    string `json:"key"`
+		Domain string `json:"domain"`
+	}
+
+	data.Key = c.Key
+	data.Domain = domain
+
+	err = c.call(ctx, path, data, &result)
+	return result, err
+}
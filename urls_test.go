@@ -0,0 +1,79 @@
+package mandrill
+
+import (
+	"testing"
+)
+
+const urlJSON = `{"url":"http://example.com/example-page","sent":42,"clicks":42,"unique_clicks":42}`
+
+const trackingDomainJSON = `{
+	"domain": "track.example.com",
+	"created_at": "2013-01-01 15:30:27",
+	"last_tested_at": "2013-01-01 15:40:42",
+	"cname": {"valid": true, "valid_after": "2013-01-01 15:45:23", "error": ""},
+	"valid_tracking": true
+}`
+
+// URLs //////////
+
+func Test_URLsList(t *testing.T) {
+	server, c, req := testRecorder(200, "["+urlJSON+"]")
+	defer server.Close()
+
+	urls, err := c.URLsList()
+	expect(t, err, nil)
+	expect(t, req.Path, "/urls/list.json")
+	expect(t, urls[0].URL, "http://example.com/example-page")
+	expect(t, urls[0].UniqueClicks, 42)
+}
+
+func Test_URLsSearch(t *testing.T) {
+	server, c, req := testRecorder(200, "["+urlJSON+"]")
+	defer server.Close()
+
+	urls, err := c.URLsSearch("example.com")
+	expect(t, err, nil)
+	expect(t, req.Path, "/urls/search.json")
+	expect(t, req.Payload["q"], "example.com")
+	expect(t, len(urls), 1)
+}
+
+func Test_URLsTimeSeries(t *testing.T) {
+	server, c, req := testRecorder(200, `[{"time":"2013-01-01 15:00:00","sent":42,"clicks":41,"unique_clicks":40}]`)
+	defer server.Close()
+
+	series, err := c.URLsTimeSeries("http://example.com/example-page")
+	expect(t, err, nil)
+	expect(t, req.Path, "/urls/time-series.json")
+	expect(t, req.Payload["url"], "http://example.com/example-page")
+	expect(t, series[0].Clicks, 41)
+	expect(t, series[0].UniqueClicks, 40)
+}
+
+// Tracking domains //////////
+
+func Test_URLsTrackingDomains(t *testing.T) {
+	server, c, req := testRecorder(200, "["+trackingDomainJSON+"]")
+	defer server.Close()
+
+	domains, err := c.URLsTrackingDomains()
+	expect(t, err, nil)
+	expect(t, req.Path, "/urls/tracking-domains.json")
+	expect(t, domains[0].CNAME.Valid, true)
+	expect(t, domains[0].ValidTracking, true)
+}
+
+func Test_URLsAddCheckTrackingDomain(t *testing.T) {
+	server, c, req := testRecorder(200, trackingDomainJSON)
+	defer server.Close()
+
+	domain, err := c.URLsAddTrackingDomain("track.example.com")
+	expect(t, err, nil)
+	expect(t, req.Path, "/urls/add-tracking-domain.json")
+	expect(t, req.Payload["domain"], "track.example.com")
+	expect(t, domain.Domain, "track.example.com")
+
+	_, err = c.URLsCheckTrackingDomain("track.example.com")
+	expect(t, err, nil)
+	expect(t, req.Path, "/urls/check-tracking-domain.json")
+}
@@ -0,0 +1,40 @@
+package mandrill
+
+import (
+	"context"
+)
+
+// User is the account the API key belongs to
+type User struct {
+	// the username of the user (used for SMTP authentication)
+	Username string `json:"username"`
+	// the date and time that the user's Mandrill account was created as a UTC string in YYYY-MM-DD HH:MM:SS format
+	CreatedAt Time `json:"created_at"`
+	// a unique, permanent identifier for this user
+	PublicID string `json:"public_id"`
+	// the reputation of the user on a scale from 0 to 100, with 75 generally being a "good" reputation
+	Reputation int `json:"reputation"`
+	// the maximum number of emails Mandrill will deliver for this user each hour
+	HourlyQuota int `json:"hourly_quota"`
+	// the number of emails that are queued for delivery due to exceeding your monthly or hourly quotas
+	Backlog int `json:"backlog"`
+	// an aggregate summary of the account's sending stats
+	Stats PeriodStats `json:"stats"`
+}
+
+// UsersInfo returns information about the API-connected user
+func (c *Client) UsersInfo() (*User, error) {
+	return c.UsersInfoContext(context.Background())
+}
+
+// UsersInfoContext is like UsersInfo but with a context
+func (c *Client) UsersInfoContext(ctx context.Context) (user *User, err error) {
+	var data struct {
+		Key string `json:"key"`
+	}
+
+	data.Key = c.Key
+
+	err = c.call(ctx, "users/info.json", data, &user)
+	return user, err
+}
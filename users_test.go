@@ -0,0 +1,49 @@
+package mandrill
+
+import (
+	"testing"
+)
+
+// Info //////////
+
+func Test_UsersInfo(t *testing.T) {
+	server, c, req := testRecorder(200, `{
+		"username": "myusername",
+		"created_at": "2013-01-01 15:30:27",
+		"public_id": "aaabbbccc112233",
+		"reputation": 42,
+		"hourly_quota": 4200,
+		"backlog": 7,
+		"stats": {
+			"today": {`+tagStatsJSON+`},
+			"last_7_days": {`+tagStatsJSON+`},
+			"last_30_days": {"sent": 30},
+			"last_60_days": {`+tagStatsJSON+`},
+			"last_90_days": {`+tagStatsJSON+`},
+			"all_time": {"sent": 1000, "hard_bounces": 12}
+		}
+	}`)
+	defer server.Close()
+
+	user, err := c.UsersInfo()
+	expect(t, err, nil)
+	expect(t, req.Path, "/users/info.json")
+	expect(t, req.Payload["key"], "APIKEY")
+	expect(t, user.Username, "myusername")
+	expect(t, user.CreatedAt.Year(), 2013)
+	expect(t, user.Reputation, 42)
+	expect(t, user.HourlyQuota, 4200)
+	expect(t, user.Backlog, 7)
+	expect(t, user.Stats.Today.UniqueClicks, 9)
+	expect(t, user.Stats.Last30Days.Sent, 30)
+	expect(t, user.Stats.AllTime.HardBounces, 12)
+}
+
+func Test_UsersInfo_InvalidKey(t *testing.T) {
+	server, c, _ := testRecorder(500, `{"status":"error","code":-1,"name":"Invalid_Key","message":"Invalid API key"}`)
+	defer server.Close()
+
+	user, err := c.UsersInfo()
+	refute(t, err, nil)
+	expect(t, user, (*User)(nil))
+}
@@ -0,0 +1,194 @@
+package mandrill
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// DefaultWarmupWindow is the stretch of recent sending a Warmup's Check judges when Window is zero
+const DefaultWarmupWindow = 24 * time.Hour
+
+// WarmupStep is a stage of a Warmup ramp
+type WarmupStep struct {
+	// how long after the ramp's Start the step begins
+	After time.Duration
+	// the share of messages routed to the warmup pool during the step, from 0 to 1
+	Share float64
+}
+
+// Warmup ramps traffic onto the pool of a newly provisioned dedicated IP. Add it
+// to Client.Middleware: it routes a growing share of sends to Pool, following
+// Schedule, and tags them with Tag. Call Check periodically to watch the bounce,
+// complaint and deferral rates of the tagged sends, pausing the ramp at its
+// current share when one exceeds its threshold. Messages that already set an
+// IPPool are left alone.
+type Warmup struct {
+	// the dedicated IP pool being warmed up, e.g. one created with IPsCreatePool
+	Pool string
+	// the tag added to messages routed to Pool, whose stats Check reads
+	Tag string
+	// when the ramp started. Nothing is routed before it, or while it's zero.
+	Start time.Time
+	// the ramp, in order of After, e.g. 5% from day 0, 10% from day 2, ... 100% from day 30
+	Schedule []WarmupStep
+	// the share of the window's sends that may bounce, hard or soft, e.g. 0.05. Zero disables the check.
+	MaxBounceRate float64
+	// the share of the window's sends that may be marked as spam, e.g. 0.001. Zero disables the check.
+	MaxComplaintRate float64
+	// the share of the window's sends that may be deferred, counted with RecordDeferral. Zero disables the check.
+	MaxDeferralRate float64
+	// the fewest sends in the window before rates are judged
+	MinSent int
+	// how much recent sending Check judges. Defaults to DefaultWarmupWindow.
+	Window time.Duration
+	// optional callback invoked when Check pauses the ramp
+	OnPause func(reason string)
+
+	mu        sync.Mutex
+	paused    string
+	heldShare float64
+	routed    uint64
+	deferrals []time.Time
+}
+
+var _ Middleware = (*Warmup)(nil)
+
+// Share returns the share of messages currently routed to Pool
+func (w *Warmup) Share() float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.share()
+}
+
+func (w *Warmup) share() float64 {
+	if w.paused != "" {
+		return w.heldShare
+	}
+	if w.Start.IsZero() {
+		return 0
+	}
+	elapsed := now().Sub(w.Start)
+	share := 0.0
+	for _, step := range w.Schedule {
+		if elapsed >= step.After {
+			share = step.Share
+		}
+	}
+	return math.Max(0, math.Min(1, share))
+}
+
+// Paused returns why Check paused the ramp, or "" if it's running
+func (w *Warmup) Paused() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.paused
+}
+
+// Resume continues a paused ramp from the step its schedule has reached
+func (w *Warmup) Resume() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.paused = ""
+}
+
+// RecordDeferral counts a deferral of a message sent through the pool, e.g. from a
+// webhook handler's OnDeferral. Mandrill's stats don't report deferrals.
+func (w *Warmup) RecordDeferral(at time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.deferrals = append(w.deferrals, at)
+}
+
+// BeforeSend routes the message to Pool if it falls within the current share.
+// Routing is spread evenly, so a share of 0.25 routes every fourth message.
+func (w *Warmup) BeforeSend(message *Message) error {
+	if message.IPPool != "" {
+		return nil
+	}
+
+	w.mu.Lock()
+	share := w.share()
+	n := w.routed
+	w.routed++
+	w.mu.Unlock()
+
+	if math.Floor(float64(n+1)*share) > math.Floor(float64(n)*share) {
+		message.IPPool = w.Pool
+		if w.Tag != "" {
+			message.Tags = append(message.Tags, w.Tag)
+		}
+	}
+	return nil
+}
+
+// AfterSend does nothing
+func (w *Warmup) AfterSend(message *Message, responses []*Response, err error) {}
+
+// Check reads the hourly stats of Tag for the last Window and pauses the ramp if
+// a rate exceeds its threshold. It returns the reason the ramp is paused, or "".
+func (w *Warmup) Check(ctx context.Context, c *Client) (paused string, err error) {
+	series, err := c.TagsTimeSeriesContext(ctx, w.Tag)
+	if err != nil {
+		return w.Paused(), err
+	}
+
+	window := w.Window
+	if window <= 0 {
+		window = DefaultWarmupWindow
+	}
+	since := now().Add(-window)
+
+	var stats Stats
+	for _, entry := range series {
+		if entry != nil && !entry.Time.Before(since) {
+			stats.add(&entry.Stats)
+		}
+	}
+
+	w.mu.Lock()
+	paused = w.judge(stats, since)
+	w.mu.Unlock()
+
+	if paused != "" && w.OnPause != nil {
+		w.OnPause(paused)
+	}
+	return w.Paused(), nil
+}
+
+// judge pauses the ramp if the window's stats exceed a threshold, returning the reason for a new pause
+func (w *Warmup) judge(stats Stats, since time.Time) (paused string) {
+	kept := w.deferrals[:0]
+	for _, at := range w.deferrals {
+		if !at.Before(since) {
+			kept = append(kept, at)
+		}
+	}
+	w.deferrals = kept
+
+	if w.paused != "" || stats.Sent == 0 || stats.Sent < w.MinSent {
+		return ""
+	}
+
+	sent := float64(stats.Sent)
+	switch {
+	case w.MaxBounceRate > 0 && float64(stats.HardBounces+stats.SoftBounces)/sent > w.MaxBounceRate:
+		paused = fmt.Sprintf("bounce rate %.2f%% exceeds %.2f%%", 100*float64(stats.HardBounces+stats.SoftBounces)/sent, 100*w.MaxBounceRate)
+	case w.MaxComplaintRate > 0 && float64(stats.Complaints)/sent > w.MaxComplaintRate:
+		paused = fmt.Sprintf("complaint rate %.2f%% exceeds %.2f%%", 100*float64(stats.Complaints)/sent, 100*w.MaxComplaintRate)
+	case w.MaxDeferralRate > 0 && float64(len(w.deferrals))/sent > w.MaxDeferralRate:
+		paused = fmt.Sprintf("deferral rate %.2f%% exceeds %.2f%%", 100*float64(len(w.deferrals))/sent, 100*w.MaxDeferralRate)
+	default:
+		return ""
+	}
+
+	w.heldShare = w.share()
+	w.paused = paused
+	return paused
+}
@@ -0,0 +1,132 @@
+package mandrill
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var warmupStart = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func testWarmup() *Warmup {
+	return &Warmup{
+		Pool:  "warmup",
+		Tag:   "warmup-pool",
+		Start: warmupStart,
+		Schedule: []WarmupStep{
+			{After: 0, Share: 0.25},
+			{After: 48 * time.Hour, Share: 0.5},
+			{After: 30 * 24 * time.Hour, Share: 1},
+		},
+		MaxBounceRate:    0.05,
+		MaxComplaintRate: 0.001,
+		MaxDeferralRate:  0.1,
+		MinSent:          100,
+	}
+}
+
+// Warmup //////////
+
+func Test_Warmup_Share(t *testing.T) {
+	w := testWarmup()
+
+	freezeNow(t, warmupStart.Add(-time.Hour))
+	expect(t, w.Share(), 0.0)
+	freezeNow(t, warmupStart.Add(time.Hour))
+	expect(t, w.Share(), 0.25)
+	freezeNow(t, warmupStart.Add(72*time.Hour))
+	expect(t, w.Share(), 0.5)
+	freezeNow(t, warmupStart.Add(40*24*time.Hour))
+	expect(t, w.Share(), 1.0)
+
+	expect(t, (&Warmup{Schedule: w.Schedule}).Share(), 0.0)
+}
+
+func Test_Warmup_BeforeSend(t *testing.T) {
+	freezeNow(t, warmupStart.Add(time.Hour))
+	w := testWarmup()
+
+	routed := 0
+	for i := 0; i < 8; i++ {
+		m := &Message{}
+		w.BeforeSend(m)
+		if m.IPPool == "warmup" {
+			routed++
+			expect(t, m.Tags[0], "warmup-pool")
+		}
+	}
+	expect(t, routed, 2)
+
+	m := &Message{IPPool: "main"}
+	for i := 0; i < 4; i++ {
+		w.BeforeSend(m)
+	}
+	expect(t, m.IPPool, "main")
+	expect(t, len(m.Tags), 0)
+}
+
+func Test_Warmup_Check(t *testing.T) {
+	freezeNow(t, time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC))
+	// the first hour is outside the 24 hour window
+	server, c, req := testRecorder(200, `[
+		{"time": "2024-03-03 10:00:00", "sent": 1000, "hard_bounces": 500},
+		{"time": "2024-03-04 10:00:00", "sent": 100, "hard_bounces": 4, "soft_bounces": 2},
+		{"time": "2024-03-04 11:00:00", "sent": 100}
+	]`)
+	defer server.Close()
+
+	w := testWarmup()
+	var reasons []string
+	w.OnPause = func(reason string) { reasons = append(reasons, reason) }
+
+	paused, err := w.Check(context.Background(), c)
+	expect(t, err, nil)
+	expect(t, paused, "")
+	expect(t, req.Path, "/tags/time-series.json")
+	expect(t, req.Payload["tag"], "warmup-pool")
+
+	w.MaxBounceRate = 0.02
+	paused, err = w.Check(context.Background(), c)
+	expect(t, err, nil)
+	expect(t, paused, "bounce rate 3.00% exceeds 2.00%")
+	expect(t, w.Paused(), paused)
+	expect(t, len(reasons), 1)
+
+	// the share holds while paused, even as the schedule moves on
+	freezeNow(t, warmupStart.Add(10*24*time.Hour))
+	expect(t, w.Share(), 0.5)
+	w.Check(context.Background(), c)
+	expect(t, len(reasons), 1)
+
+	w.Resume()
+	expect(t, w.Paused(), "")
+}
+
+func Test_Warmup_CheckDeferrals(t *testing.T) {
+	current := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	freezeNow(t, current)
+	server, c := testTools(200, `[{"time": "2024-03-04 11:00:00", "sent": 100}]`)
+	defer server.Close()
+
+	w := testWarmup()
+	w.RecordDeferral(current.Add(-48 * time.Hour))
+	for i := 0; i < 10; i++ {
+		w.RecordDeferral(current.Add(-time.Hour))
+	}
+	paused, _ := w.Check(context.Background(), c)
+	expect(t, paused, "")
+
+	w.RecordDeferral(current)
+	paused, _ = w.Check(context.Background(), c)
+	expect(t, paused, "deferral rate 11.00% exceeds 10.00%")
+}
+
+func Test_Warmup_CheckMinSent(t *testing.T) {
+	freezeNow(t, time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC))
+	server, c := testTools(200, `[{"time": "2024-03-04 11:00:00", "sent": 10, "complaints": 5}]`)
+	defer server.Close()
+
+	paused, err := testWarmup().Check(context.Background(), c)
+	expect(t, err, nil)
+	expect(t, paused, "")
+}
@@ -0,0 +1,162 @@
+package webhooks
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BounceCategory is the cause of a bounce, as classified by ClassifyBounce
+type BounceCategory string
+
+// Bounce categories
+const (
+	// the recipient's mailbox doesn't exist or has been disabled
+	BounceNoSuchUser BounceCategory = "no_such_user"
+	// the recipient's mailbox is over quota
+	BounceMailboxFull BounceCategory = "mailbox_full"
+	// the recipient's domain doesn't exist or has no mail servers
+	BounceDNSFailure BounceCategory = "dns_failure"
+	// the receiving server refused the message for spam, reputation or policy reasons
+	BouncePolicyBlock BounceCategory = "policy_block"
+	// the message exceeds the receiving server's size limit
+	BounceMessageTooLarge BounceCategory = "message_too_large"
+	// a transient failure such as greylisting, a timeout or an unavailable server
+	BounceTemporary BounceCategory = "temporary"
+	// the diagnostic didn't match any known cause
+	BounceUnknown BounceCategory = "unknown"
+)
+
+// BounceAction is the suggested response to a bounce
+type BounceAction string
+
+// Bounce actions
+const (
+	// stop sending to the address
+	ActionSuppress BounceAction = "suppress"
+	// keep the address; later sends may succeed
+	ActionRetryLater BounceAction = "retry_later"
+	// check the content, sending reputation and SPF/DKIM setup before sending more
+	ActionReviewSending BounceAction = "review_sending"
+	// send a smaller message, e.g. with attachments replaced by links
+	ActionReduceSize BounceAction = "reduce_size"
+	// look at the diagnostic by hand
+	ActionInvestigate BounceAction = "investigate"
+)
+
+var bounceActions = map[BounceCategory]BounceAction{
+	BounceNoSuchUser:      ActionSuppress,
+	BounceMailboxFull:     ActionRetryLater,
+	BounceDNSFailure:      ActionSuppress,
+	BouncePolicyBlock:     ActionReviewSending,
+	BounceMessageTooLarge: ActionReduceSize,
+	BounceTemporary:       ActionRetryLater,
+	BounceUnknown:         ActionInvestigate,
+}
+
+// BounceDiagnosis is a parsed and classified bounce diagnostic
+type BounceDiagnosis struct {
+	// the SMTP reply code, e.g. 550, or 0 if the diagnostic had none
+	Code int
+	// the enhanced status code, e.g. "5.1.1", if the diagnostic had one
+	Status string
+	// the rest of the diagnostic message
+	Text string
+	// the classified cause of the bounce
+	Category BounceCategory
+	// the suggested response
+	Action BounceAction
+}
+
+var diagPattern = regexp.MustCompile(`^(\d{3})(?:[ -]+(\d\.\d{1,3}\.\d{1,3}))?[ -]*(.*)$`)
+
+// descriptionCategories maps Mandrill's bounce_description values
+var descriptionCategories = map[string]BounceCategory{
+	"bad_mailbox":    BounceNoSuchUser,
+	"invalid_domain": BounceDNSFailure,
+	"mailbox_full":   BounceMailboxFull,
+	"spam_related":   BouncePolicyBlock,
+	"policy_related": BouncePolicyBlock,
+}
+
+// statusCategories maps enhanced status codes, without their class digit
+var statusCategories = map[string]BounceCategory{
+	"1.1":  BounceNoSuchUser,
+	"2.1":  BounceNoSuchUser,
+	"1.2":  BounceDNSFailure,
+	"1.10": BounceDNSFailure,
+	"4.3":  BounceDNSFailure,
+	"4.4":  BounceDNSFailure,
+	"2.2":  BounceMailboxFull,
+	"2.3":  BounceMessageTooLarge,
+	"3.4":  BounceMessageTooLarge,
+}
+
+// textCategories are checked in order against the lowercased diagnostic text
+var textCategories = []struct {
+	fragments []string
+	category  BounceCategory
+}{
+	{[]string{"user unknown", "unknown user", "no such user", "does not exist", "mailbox unavailable", "address rejected", "invalid recipient", "recipient not found"}, BounceNoSuchUser},
+	{[]string{"mailbox full", "mailbox is full", "over quota", "quota exceeded", "insufficient storage"}, BounceMailboxFull},
+	{[]string{"host not found", "domain not found", "no mx", "name or service not known", "dns"}, BounceDNSFailure},
+	{[]string{"too large", "size limit", "message size"}, BounceMessageTooLarge},
+	{[]string{"spam", "blocked", "blacklist", "blocklist", "reputation", "policy", "dmarc", "not authorized"}, BouncePolicyBlock},
+	{[]string{"greylist", "graylist", "try again later", "timed out", "timeout", "temporarily"}, BounceTemporary},
+}
+
+// Classify parses and classifies the bounce's diagnostic. See ClassifyBounce.
+func (e *BounceEvent) Classify() *BounceDiagnosis {
+	return ClassifyBounce(e.Diag, e.Description)
+}
+
+// ClassifyBounce parses an SMTP diagnostic such as "smtp;550 5.1.1 User unknown"
+// and classifies it, using Mandrill's bounce description (e.g. "bad_mailbox") when
+// it's specific, then the enhanced status code, then the diagnostic text. 4xx
+// replies that match nothing else are BounceTemporary.
+func ClassifyBounce(diag, description string) *BounceDiagnosis {
+	d := &BounceDiagnosis{Category: BounceUnknown}
+
+	text := strings.TrimSpace(diag)
+	if i := strings.Index(text, ";"); i >= 0 && !strings.ContainsAny(text[:i], " ") {
+		text = strings.TrimSpace(text[i+1:])
+	}
+	if m := diagPattern.FindStringSubmatch(text); m != nil {
+		d.Code, _ = strconv.Atoi(m[1])
+		d.Status = m[2]
+		text = m[3]
+	}
+	d.Text = text
+
+	// the enhanced status without its class digit, e.g. "1.1"
+	subject := ""
+	if d.Status != "" {
+		subject = d.Status[2:]
+	}
+
+	switch {
+	case descriptionCategories[description] != "":
+		d.Category = descriptionCategories[description]
+	case strings.HasPrefix(subject, "7."):
+		d.Category = BouncePolicyBlock
+	case statusCategories[subject] != "":
+		d.Category = statusCategories[subject]
+	default:
+		lower := strings.ToLower(text)
+	texts:
+		for _, tc := range textCategories {
+			for _, fragment := range tc.fragments {
+				if strings.Contains(lower, fragment) {
+					d.Category = tc.category
+					break texts
+				}
+			}
+		}
+		if d.Category == BounceUnknown && (d.Code/100 == 4 || strings.HasPrefix(d.Status, "4.")) {
+			d.Category = BounceTemporary
+		}
+	}
+
+	d.Action = bounceActions[d.Category]
+	return d
+}
@@ -0,0 +1,56 @@
+package webhooks
+
+import (
+	"testing"
+)
+
+// Bounce classification //////////
+
+func Test_ClassifyBounce(t *testing.T) {
+	cases := []struct {
+		diag        string
+		description string
+		category    BounceCategory
+		action      BounceAction
+	}{
+		{"smtp;550 5.1.1 The email account that you tried to reach does not exist.", "bad_mailbox", BounceNoSuchUser, ActionSuppress},
+		{"smtp;550 5.1.1 <bob@example.com>: Recipient address rejected", "general", BounceNoSuchUser, ActionSuppress},
+		{"smtp;552 5.2.2 Mailbox full", "general", BounceMailboxFull, ActionRetryLater},
+		{"smtp;452 4.2.2 The email account that you tried to reach is over quota", "", BounceMailboxFull, ActionRetryLater},
+		{"smtp;550 5.7.1 Message rejected due to local policy", "general", BouncePolicyBlock, ActionReviewSending},
+		{"smtp;554 Message blocked: sender IP listed on a blocklist", "", BouncePolicyBlock, ActionReviewSending},
+		{"smtp;550 5.4.4 Unable to route: host not found", "", BounceDNSFailure, ActionSuppress},
+		{"Host or domain name not found", "invalid_domain", BounceDNSFailure, ActionSuppress},
+		{"smtp;552 5.3.4 Message size exceeds fixed maximum message size", "", BounceMessageTooLarge, ActionReduceSize},
+		{"smtp;451 Greylisted, please try again later", "", BounceTemporary, ActionRetryLater},
+		{"smtp;421 Service not available", "", BounceTemporary, ActionRetryLater},
+		{"smtp;550 Something odd happened", "general", BounceUnknown, ActionInvestigate},
+		{"", "", BounceUnknown, ActionInvestigate},
+	}
+
+	for _, c := range cases {
+		d := ClassifyBounce(c.diag, c.description)
+		if d.Category != c.category || d.Action != c.action {
+			t.Errorf("ClassifyBounce(%q, %q) = %s/%s, want %s/%s", c.diag, c.description, d.Category, d.Action, c.category, c.action)
+		}
+	}
+}
+
+func Test_ClassifyBounce_Parse(t *testing.T) {
+	d := ClassifyBounce("smtp;550 5.1.1 The email account that you tried to reach does not exist.", "")
+	expect(t, d.Code, 550)
+	expect(t, d.Status, "5.1.1")
+	expect(t, d.Text, "The email account that you tried to reach does not exist.")
+
+	d = ClassifyBounce("421-Service not available", "")
+	expect(t, d.Code, 421)
+	expect(t, d.Status, "")
+	expect(t, d.Text, "Service not available")
+}
+
+func Test_BounceEvent_Classify(t *testing.T) {
+	bounce, _ := decodeEvent(t, bounceEventJSON).Bounce()
+	d := bounce.Classify()
+	expect(t, d.Category, BounceNoSuchUser)
+	expect(t, d.Action, ActionSuppress)
+}
@@ -0,0 +1,191 @@
+package webhooks
+
+import (
+	"strings"
+
+	"github.com/keighl/mandrill"
+)
+
+// Location is the approximate location of the IP address that opened or clicked a message
+type Location struct {
+	// the two-letter country code
+	CountryShort string `json:"country_short"`
+	// the full country name
+	Country string `json:"country"`
+	// the region, e.g. a state or province
+	Region string `json:"region"`
+	// the city
+	City string `json:"city"`
+	// the postal code
+	PostalCode string `json:"postal_code"`
+	// the timezone as an offset from UTC, e.g. "-05:00"
+	Timezone string `json:"timezone"`
+	// the latitude
+	Latitude float64 `json:"latitude"`
+	// the longitude
+	Longitude float64 `json:"longitude"`
+}
+
+// UserAgent is the parsed user agent of the client that opened or clicked a message
+type UserAgent struct {
+	// the unparsed user agent string
+	Raw string `json:"-"`
+	// the client type, e.g. "Browser" or "Email Client"
+	Type string `json:"type"`
+	// the client family, e.g. "Firefox"
+	Family string `json:"ua_family"`
+	// the client name and version, e.g. "Firefox 3.6"
+	Name string `json:"ua_name"`
+	// the client version
+	Version string `json:"ua_version"`
+	// the operating system family, e.g. "OS X"
+	OSFamily string `json:"os_family"`
+	// the operating system name and version
+	OSName string `json:"os_name"`
+	// whether the client is a mobile device
+	Mobile bool `json:"mobile"`
+}
+
+// botUserAgents are fragments of raw user agents sent by crawlers, link scanners and image
+// proxies, which open and click messages without a person involved
+var botUserAgents = []string{
+	"bot", "crawler", "spider", "preview", "googleimageproxy", "yahoomailproxy",
+	"barracuda", "proofpoint", "mimecast", "symantec", "python-requests", "curl/", "go-http-client",
+}
+
+// IsMobile reports whether the client is a mobile device. It's false for a nil UserAgent.
+func (ua *UserAgent) IsMobile() bool {
+	return ua != nil && (ua.Mobile || strings.Contains(strings.ToLower(ua.Type), "mobile"))
+}
+
+// IsBot reports whether the client looks automated: Mandrill parsed it as a robot
+// or library, or the raw user agent names a known crawler, link scanner or image
+// proxy. It's false for a nil UserAgent.
+func (ua *UserAgent) IsBot() bool {
+	if ua == nil {
+		return false
+	}
+	switch strings.ToLower(ua.Type) {
+	case "robot", "library":
+		return true
+	}
+	raw := strings.ToLower(ua.Raw)
+	for _, fragment := range botUserAgents {
+		if strings.Contains(raw, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// BounceEvent is a hard_bounce or soft_bounce event
+type BounceEvent struct {
+	// the Unix timestamp when the bounce occurred
+	Ts mandrill.Time
+	// the message's unique id
+	ID string
+	// the bounced message
+	Msg *Message
+	// whether the bounce was a hard_bounce
+	Hard bool
+	// a short description of the bounce, e.g. "bad_mailbox"
+	Description string
+	// the SMTP diagnostic message of the bounce
+	Diag string
+}
+
+// OpenEvent is an open event
+type OpenEvent struct {
+	// the Unix timestamp when the message was opened
+	Ts mandrill.Time
+	// the message's unique id
+	ID string
+	// the opened message
+	Msg *Message
+	// the IP address that opened the message
+	IP string
+	// the approximate location of the IP address, if known
+	Location *Location
+	// the client that opened the message, if known
+	UserAgent *UserAgent
+}
+
+// ClickEvent is a click event
+type ClickEvent struct {
+	// the Unix timestamp when the link was clicked
+	Ts mandrill.Time
+	// the message's unique id
+	ID string
+	// the message the link was in
+	Msg *Message
+	// the URL that was clicked
+	URL string
+	// the IP address that clicked the link
+	IP string
+	// the approximate location of the IP address, if known
+	Location *Location
+	// the client that clicked the link, if known
+	UserAgent *UserAgent
+}
+
+// IsMobile reports whether the message was opened on a mobile device
+func (e *OpenEvent) IsMobile() bool {
+	return e.UserAgent.IsMobile()
+}
+
+// IsBot reports whether the open looks automated, e.g. by an image proxy. See UserAgent.IsBot.
+func (e *OpenEvent) IsBot() bool {
+	return e.UserAgent.IsBot()
+}
+
+// IsMobile reports whether the link was clicked on a mobile device
+func (e *ClickEvent) IsMobile() bool {
+	return e.UserAgent.IsMobile()
+}
+
+// IsBot reports whether the click looks automated, e.g. by a link scanner. See UserAgent.IsBot.
+func (e *ClickEvent) IsBot() bool {
+	return e.UserAgent.IsBot()
+}
+
+// Bounce returns the event as a BounceEvent if it's a hard_bounce or soft_bounce
+func (e *Event) Bounce() (*BounceEvent, bool) {
+	if e.Type != EventHardBounce && e.Type != EventSoftBounce {
+		return nil, false
+	}
+	bounce := &BounceEvent{Ts: e.Ts, ID: e.ID, Msg: e.Msg, Hard: e.Type == EventHardBounce}
+	if e.Msg != nil {
+		bounce.Description = e.Msg.BounceDescription
+		bounce.Diag = e.Msg.Diag
+	}
+	return bounce, true
+}
+
+// Open returns the event as an OpenEvent if it's an open
+func (e *Event) Open() (*OpenEvent, bool) {
+	if e.Type != EventOpen {
+		return nil, false
+	}
+	return &OpenEvent{Ts: e.Ts, ID: e.ID, Msg: e.Msg, IP: e.IP, Location: e.Location, UserAgent: e.userAgent()}, true
+}
+
+// Click returns the event as a ClickEvent if it's a click
+func (e *Event) Click() (*ClickEvent, bool) {
+	if e.Type != EventClick {
+		return nil, false
+	}
+	return &ClickEvent{Ts: e.Ts, ID: e.ID, Msg: e.Msg, URL: e.URL, IP: e.IP, Location: e.Location, UserAgent: e.userAgent()}, true
+}
+
+// userAgent combines the raw and parsed user agent
+func (e *Event) userAgent() *UserAgent {
+	if e.UserAgentParsed == nil && e.UserAgent == "" {
+		return nil
+	}
+	ua := &UserAgent{}
+	if e.UserAgentParsed != nil {
+		*ua = *e.UserAgentParsed
+	}
+	ua.Raw = e.UserAgent
+	return ua
+}
@@ -0,0 +1,123 @@
+package webhooks
+
+import (
+	"testing"
+)
+
+const openEventJSON = `{
+	"event": "open",
+	"ts": 1365111111,
+	"_id": "exampleaaaaaaaaaaaaaaaaaaaaaaaaa",
+	"ip": "127.0.0.1",
+	"user_agent": "Mozilla/5.0 (Macintosh; U; Intel Mac OS X 10.6; en-US; rv:1.9.1.8) Gecko/20100317 Postbox/1.1.3",
+	"user_agent_parsed": {
+		"type": "Email Client",
+		"ua_family": "Postbox",
+		"ua_name": "Postbox 1.1.3",
+		"ua_version": "1.1.3",
+		"os_family": "OS X",
+		"os_name": "OS X 10.6 Snow Leopard",
+		"mobile": false
+	},
+	"location": {
+		"country_short": "US",
+		"country": "United States",
+		"region": "Oklahoma",
+		"city": "Oklahoma City",
+		"postal_code": "73101",
+		"timezone": "-05:00",
+		"latitude": 35.4675598145,
+		"longitude": -97.5164337158
+	},
+	"msg": {"_id": "exampleaaaaaaaaaaaaaaaaaaaaaaaaa", "email": "example.webhook@mandrillapp.com"}
+}`
+
+func decodeEvent(t *testing.T, data string) *Event {
+	events, err := DecodeEvents([]byte("[" + data + "]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events[0]
+}
+
+// Typed events //////////
+
+func Test_Event_Bounce(t *testing.T) {
+	event := decodeEvent(t, bounceEventJSON)
+
+	bounce, ok := event.Bounce()
+	expect(t, ok, true)
+	expect(t, bounce.Hard, true)
+	expect(t, bounce.Description, "bad_mailbox")
+	expect(t, bounce.Diag, "smtp;550 5.1.1 The email account that you tried to reach does not exist.")
+	expect(t, bounce.Msg.Email, "example.webhook@mandrillapp.com")
+
+	event.Type = EventSoftBounce
+	bounce, ok = event.Bounce()
+	expect(t, ok, true)
+	expect(t, bounce.Hard, false)
+
+	_, ok = event.Open()
+	expect(t, ok, false)
+}
+
+func Test_Event_Open(t *testing.T) {
+	event := decodeEvent(t, openEventJSON)
+
+	open, ok := event.Open()
+	expect(t, ok, true)
+	expect(t, open.IP, "127.0.0.1")
+	expect(t, open.Location.CountryShort, "US")
+	expect(t, open.Location.City, "Oklahoma City")
+	expect(t, open.Location.Longitude, -97.5164337158)
+	expect(t, open.UserAgent.Family, "Postbox")
+	expect(t, open.UserAgent.OSName, "OS X 10.6 Snow Leopard")
+	expect(t, open.UserAgent.Raw, event.UserAgent)
+
+	_, ok = event.Click()
+	expect(t, ok, false)
+}
+
+func Test_Event_Click(t *testing.T) {
+	event := decodeEvent(t, clickEventJSON)
+
+	click, ok := event.Click()
+	expect(t, ok, true)
+	expect(t, click.URL, "http://mandrill.com")
+	expect(t, click.Location.Country, "United States")
+	expect(t, click.UserAgent.Family, "")
+	expect(t, click.UserAgent.Raw, "Mozilla/5.0 (Macintosh; U; Intel Mac OS X 10.6; en-US; rv:1.9.1.8) Gecko/20100317 Postbox/1.1.3")
+
+	_, ok = event.Bounce()
+	expect(t, ok, false)
+}
+
+func Test_Event_NoUserAgent(t *testing.T) {
+	click, _ := (&Event{Type: EventClick}).Click()
+	expect(t, click.UserAgent, (*UserAgent)(nil))
+	expect(t, click.Location, (*Location)(nil))
+}
+
+func Test_UserAgent_IsMobile(t *testing.T) {
+	expect(t, (&UserAgent{Mobile: true}).IsMobile(), true)
+	expect(t, (&UserAgent{Type: "Mobile Browser"}).IsMobile(), true)
+	expect(t, (&UserAgent{Type: "Email Client"}).IsMobile(), false)
+	expect(t, (*UserAgent)(nil).IsMobile(), false)
+
+	open, _ := decodeEvent(t, openEventJSON).Open()
+	expect(t, open.IsMobile(), false)
+}
+
+func Test_UserAgent_IsBot(t *testing.T) {
+	expect(t, (&UserAgent{Type: "Robot"}).IsBot(), true)
+	expect(t, (&UserAgent{Type: "Library"}).IsBot(), true)
+	expect(t, (&UserAgent{Raw: "Mozilla/5.0 (Windows NT 5.1; rv:11.0) Gecko Firefox/11.0 (via ggpht.com GoogleImageProxy)"}).IsBot(), true)
+	expect(t, (&UserAgent{Raw: "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"}).IsBot(), true)
+	expect(t, (*UserAgent)(nil).IsBot(), false)
+
+	open, _ := decodeEvent(t, openEventJSON).Open()
+	expect(t, open.IsBot(), false)
+	click, _ := (&Event{Type: EventClick}).Click()
+	expect(t, click.IsBot(), false)
+	expect(t, click.IsMobile(), false)
+}
@@ -0,0 +1,268 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// ErrInvalidSignature is returned when a request's X-Mandrill-Signature doesn't match any webhook key
+var ErrInvalidSignature = errors.New("webhooks: invalid signature")
+
+// ErrNoKeys is returned when a Handler has no webhook keys to verify requests with
+var ErrNoKeys = errors.New("webhooks: no webhook keys configured")
+
+// Handler verifies, parses and dispatches webhook requests. It answers the
+// HEAD request Mandrill makes when the webhook is added.
+//
+// If a callback returns an error the handler stops and responds 500, and
+// Mandrill retries the whole batch later, including the events that were
+// already dispatched. Callbacks must therefore be idempotent, e.g. by
+// remembering the Type, ID and Ts of events they've handled.
+//
+// With a Store, events are saved before they're dispatched and the request is
+// acknowledged once they're saved. Events whose callbacks fail stay pending in
+// the store instead, for ProcessPending to retry.
+type Handler struct {
+	// webhook keys used to verify X-Mandrill-Signature. Give both the old and new
+	// key while rotating. Empty keys are ignored, and requests are refused when
+	// there are none unless InsecureSkipVerify is set.
+	Keys []string
+	// accept requests without verifying their signature. Only for local development.
+	InsecureSkipVerify bool
+	// the webhook URL exactly as registered with Mandrill. Defaults to the URL the
+	// request was made to, which may differ behind a proxy.
+	URL string
+	// optional store events are saved to before they're dispatched
+	Store EventStore
+	// optional metrics the accepted events are counted in
+	Metrics *Metrics
+
+	// called for every event, before the event type's callback
+	OnEvent func(event *Event) error
+	// called for send events
+	OnSend func(event *Event) error
+	// called for deferral events
+	OnDeferral func(event *Event) error
+	// called for hard_bounce and soft_bounce events
+	OnBounce func(event *BounceEvent) error
+	// called for open events
+	OnOpen func(event *OpenEvent) error
+	// called for click events
+	OnClick func(event *ClickEvent) error
+	// called for spam events
+	OnSpam func(event *Event) error
+	// called for unsub events
+	OnUnsub func(event *Event) error
+	// called for reject events
+	OnReject func(event *Event) error
+}
+
+// ServeHTTP handles a webhook request
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "HEAD":
+		w.WriteHeader(http.StatusOK)
+		return
+	case "POST":
+	default:
+		w.Header().Set("Allow", "HEAD, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !h.InsecureSkipVerify {
+		if !hasKey(h.Keys) {
+			http.Error(w, ErrNoKeys.Error(), http.StatusForbidden)
+			return
+		}
+		if !VerifySignature(r, h.url(r), h.Keys...) {
+			http.Error(w, ErrInvalidSignature.Error(), http.StatusForbidden)
+			return
+		}
+	}
+
+	events, err := ParseEvents(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if h.Store != nil {
+		if err := h.Store.Save(events); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		h.observe(events)
+		h.process(events)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	for _, event := range events {
+		if err := h.dispatch(event); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	h.observe(events)
+	w.WriteHeader(http.StatusOK)
+}
+
+// observe counts accepted events in Metrics
+func (h *Handler) observe(events []*Event) {
+	if h.Metrics == nil {
+		return
+	}
+	for _, event := range events {
+		h.Metrics.Observe(event)
+	}
+}
+
+// ProcessPending dispatches the events still pending in the Store, e.g. after a
+// callback failed or the process restarted, marking each done once its callbacks
+// succeed. Run it periodically. It returns the first error, after trying every event.
+func (h *Handler) ProcessPending() error {
+	if h.Store == nil {
+		return nil
+	}
+	events, err := h.Store.Pending()
+	if err != nil {
+		return err
+	}
+	return h.process(events)
+}
+
+// process dispatches stored events, marking the successful ones done
+func (h *Handler) process(events []*Event) error {
+	var first error
+	for _, event := range events {
+		err := h.dispatch(event)
+		if err == nil {
+			err = h.Store.Done(event.Key())
+		}
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (h *Handler) dispatch(event *Event) error {
+	if h.OnEvent != nil {
+		if err := h.OnEvent(event); err != nil {
+			return err
+		}
+	}
+
+	switch event.Type {
+	case EventSend:
+		return call(h.OnSend, event)
+	case EventDeferral:
+		return call(h.OnDeferral, event)
+	case EventHardBounce, EventSoftBounce:
+		if h.OnBounce != nil {
+			bounce, _ := event.Bounce()
+			return h.OnBounce(bounce)
+		}
+	case EventOpen:
+		if h.OnOpen != nil {
+			open, _ := event.Open()
+			return h.OnOpen(open)
+		}
+	case EventClick:
+		if h.OnClick != nil {
+			click, _ := event.Click()
+			return h.OnClick(click)
+		}
+	case EventSpam:
+		return call(h.OnSpam, event)
+	case EventUnsub:
+		return call(h.OnUnsub, event)
+	case EventReject:
+		return call(h.OnReject, event)
+	}
+	return nil
+}
+
+func hasKey(keys []string) bool {
+	for _, key := range keys {
+		if key != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func call(callback func(*Event) error, event *Event) error {
+	if callback == nil {
+		return nil
+	}
+	return callback(event)
+}
+
+func (h *Handler) url(r *http.Request) string {
+	if h.URL != "" {
+		return h.URL
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+// Signature returns the X-Mandrill-Signature of a webhook request with the POST params to the webhook URL
+func Signature(key, webhookURL string, params url.Values) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	signed := webhookURL
+	for _, name := range names {
+		for _, value := range params[name] {
+			signed += name + value
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(key))
+	mac.Write([]byte(signed))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether the request's X-Mandrill-Signature was made with
+// any of the keys. webhookURL must be the URL as registered with Mandrill. Empty
+// keys never match.
+func VerifySignature(r *http.Request, webhookURL string, keys ...string) bool {
+	if err := r.ParseForm(); err != nil {
+		return false
+	}
+
+	given, err := base64.StdEncoding.DecodeString(r.Header.Get("X-Mandrill-Signature"))
+	if err != nil || len(given) == 0 {
+		return false
+	}
+
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		expected, _ := base64.StdEncoding.DecodeString(Signature(key, webhookURL, r.PostForm))
+		if hmac.Equal(given, expected) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package webhooks
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const webhookURL = "https://example.com/webhook"
+
+func signedRequest(key, events string) *http.Request {
+	r := webhookRequest(events)
+	r.Header.Set("X-Mandrill-Signature", Signature(key, webhookURL, url.Values{"mandrill_events": {events}}))
+	return r
+}
+
+// Signature //////////
+
+func Test_Signature(t *testing.T) {
+	params := url.Values{"mandrill_events": {"[]"}, "a": {"1"}}
+	// base64(hmac-sha1("secret", "https://example.com/webhooka1mandrill_events[]"))
+	expect(t, Signature("secret", webhookURL, params), "w/opwXvb3olN8mqcTqnYTkoUqPs=")
+}
+
+func Test_VerifySignature(t *testing.T) {
+	expect(t, VerifySignature(signedRequest("new-key", "[]"), webhookURL, "old-key", "new-key"), true)
+	expect(t, VerifySignature(signedRequest("other-key", "[]"), webhookURL, "old-key", "new-key"), false)
+	expect(t, VerifySignature(signedRequest("new-key", "[]"), "https://example.com/other", "new-key"), false)
+	expect(t, VerifySignature(webhookRequest("[]"), webhookURL, "new-key"), false)
+	expect(t, VerifySignature(signedRequest("", "[]"), webhookURL, ""), false)
+}
+
+// Handler //////////
+
+func Test_Handler_Head(t *testing.T) {
+	h := &Handler{Keys: []string{"key"}}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("HEAD", webhookURL, nil))
+	expect(t, w.Code, http.StatusOK)
+}
+
+func Test_Handler_MethodNotAllowed(t *testing.T) {
+	w := httptest.NewRecorder()
+	(&Handler{}).ServeHTTP(w, httptest.NewRequest("GET", webhookURL, nil))
+	expect(t, w.Code, http.StatusMethodNotAllowed)
+	expect(t, w.Header().Get("Allow"), "HEAD, POST")
+}
+
+func Test_Handler_Dispatch(t *testing.T) {
+	var calls []string
+	h := &Handler{
+		Keys: []string{"key"},
+		URL:  webhookURL,
+		OnEvent: func(event *Event) error {
+			calls = append(calls, "event:"+event.Type)
+			return nil
+		},
+		OnBounce: func(event *BounceEvent) error {
+			calls = append(calls, "bounce:"+event.Description)
+			return nil
+		},
+		OnClick: func(event *ClickEvent) error {
+			calls = append(calls, "click:"+event.URL)
+			return nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, signedRequest("key", "["+bounceEventJSON+","+clickEventJSON+"]"))
+	expect(t, w.Code, http.StatusOK)
+	expect(t, strings.Join(calls, " "), "event:hard_bounce bounce:bad_mailbox event:click click:http://mandrill.com")
+}
+
+func Test_Handler_InvalidSignature(t *testing.T) {
+	called := false
+	h := &Handler{Keys: []string{"key"}, URL: webhookURL, OnEvent: func(*Event) error {
+		called = true
+		return nil
+	}}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, signedRequest("wrong", "["+bounceEventJSON+"]"))
+	expect(t, w.Code, http.StatusForbidden)
+	expect(t, called, false)
+}
+
+func Test_Handler_DefaultURL(t *testing.T) {
+	h := &Handler{Keys: []string{"key"}}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, signedRequest("key", "[]"))
+	expect(t, w.Code, http.StatusForbidden)
+
+	r := signedRequest("key", "[]")
+	r.Header.Set("X-Mandrill-Signature", Signature("key", "http://example.com/webhook", url.Values{"mandrill_events": {"[]"}}))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	expect(t, w.Code, http.StatusOK)
+}
+
+func Test_Handler_NoKeys(t *testing.T) {
+	called := false
+	onEvent := func(*Event) error {
+		called = true
+		return nil
+	}
+
+	for _, keys := range [][]string{nil, {""}} {
+		w := httptest.NewRecorder()
+		(&Handler{Keys: keys, URL: webhookURL, OnEvent: onEvent}).ServeHTTP(w, signedRequest("", "["+bounceEventJSON+"]"))
+		expect(t, w.Code, http.StatusForbidden)
+		expect(t, strings.TrimSpace(w.Body.String()), ErrNoKeys.Error())
+	}
+	expect(t, called, false)
+
+	w := httptest.NewRecorder()
+	(&Handler{InsecureSkipVerify: true, OnEvent: onEvent}).ServeHTTP(w, webhookRequest("["+bounceEventJSON+"]"))
+	expect(t, w.Code, http.StatusOK)
+	expect(t, called, true)
+}
+
+func Test_Handler_CallbackError(t *testing.T) {
+	h := &Handler{InsecureSkipVerify: true, OnBounce: func(*BounceEvent) error { return errors.New("database down") }}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, webhookRequest("["+bounceEventJSON+"]"))
+	expect(t, w.Code, http.StatusInternalServerError)
+}
+
+func Test_Handler_BadPayload(t *testing.T) {
+	w := httptest.NewRecorder()
+	(&Handler{InsecureSkipVerify: true}).ServeHTTP(w, webhookRequest("{nope"))
+	expect(t, w.Code, http.StatusBadRequest)
+}
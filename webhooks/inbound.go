@@ -0,0 +1,210 @@
+package webhooks
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/keighl/mandrill"
+)
+
+// EventInbound is the type of inbound email events
+const EventInbound = "inbound"
+
+// InboundEvent is an email received by an inbound route
+type InboundEvent struct {
+	// the event type, EventInbound
+	Type string `json:"event"`
+	// the Unix timestamp when the email was received
+	Ts mandrill.Time `json:"ts"`
+	// the received email
+	Msg *InboundMessage `json:"msg"`
+}
+
+// InboundMessage is the content of a received email
+type InboundMessage struct {
+	// the full content of the received message, including headers and attachments
+	RawMsg string `json:"raw_msg"`
+	// the parsed headers of the message
+	Headers Headers `json:"headers"`
+	// the text body of the message
+	Text string `json:"text"`
+	// the HTML body of the message
+	HTML string `json:"html"`
+	// the email address the message was sent from
+	FromEmail string `json:"from_email"`
+	// the display name the message was sent from
+	FromName string `json:"from_name"`
+	// the recipients in the message's To header
+	To []*Recipient `json:"to"`
+	// the recipients in the message's Cc header
+	CC []*Recipient `json:"cc"`
+	// the address the message was delivered to, matching the inbound route
+	Email string `json:"email"`
+	// the subject line of the message
+	Subject string `json:"subject"`
+	// the tags applied to the message, if any
+	Tags []string `json:"tags"`
+	// the sender of the message
+	Sender string `json:"sender"`
+	// the SpamAssassin report of the message
+	SpamReport *SpamReport `json:"spam_report"`
+	// the DKIM result of the message
+	DKIM *DKIM `json:"dkim"`
+	// the SPF result of the message
+	SPF *SPF `json:"spf"`
+	// the message's attachments, keyed by file name
+	Attachments map[string]*InboundAttachment `json:"attachments"`
+	// the message's inline images, keyed by content ID
+	Images map[string]*InboundAttachment `json:"images"`
+}
+
+// Recipient is an address and optional display name
+type Recipient struct {
+	Email string
+	Name  string
+}
+
+// UnmarshalJSON reads the [email, name] pairs Mandrill sends
+func (r *Recipient) UnmarshalJSON(b []byte) error {
+	var pair []*string
+	if err := json.Unmarshal(b, &pair); err != nil {
+		return err
+	}
+	if len(pair) > 0 && pair[0] != nil {
+		r.Email = *pair[0]
+	}
+	if len(pair) > 1 && pair[1] != nil {
+		r.Name = *pair[1]
+	}
+	return nil
+}
+
+// Headers are the headers of a received email. Headers that appear more than once have several values.
+type Headers map[string][]string
+
+// UnmarshalJSON reads header values given as a string or as an array of strings
+func (h *Headers) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*h = make(Headers, len(raw))
+	for name, value := range raw {
+		var values []string
+		if err := json.Unmarshal(value, &values); err != nil {
+			var single string
+			if err := json.Unmarshal(value, &single); err != nil {
+				return err
+			}
+			values = []string{single}
+		}
+		(*h)[name] = values
+	}
+	return nil
+}
+
+// Get returns the first value of the named header, ignoring case
+func (h Headers) Get(name string) string {
+	if values := h[name]; len(values) > 0 {
+		return values[0]
+	}
+	for key, values := range h {
+		if strings.EqualFold(key, name) && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// SpamReport is the SpamAssassin result of a received email
+type SpamReport struct {
+	// the SpamAssassin score of the message
+	Score float64 `json:"score"`
+	// the spam rules the message matched
+	MatchedRules []*SpamRule `json:"matched_rules"`
+}
+
+// SpamRule is a matched SpamAssassin rule
+type SpamRule struct {
+	// the rule's name
+	Name string `json:"name"`
+	// the score the rule added
+	Score float64 `json:"score"`
+	// the rule's description
+	Description string `json:"description"`
+}
+
+// DKIM is the DKIM result of a received email
+type DKIM struct {
+	// whether the message was signed with DKIM
+	Signed bool `json:"signed"`
+	// whether the DKIM signature was valid
+	Valid bool `json:"valid"`
+}
+
+// SPF is the SPF result of a received email
+type SPF struct {
+	// the SPF result, e.g. "pass", "neutral", "fail"
+	Result string `json:"result"`
+	// a description of the result
+	Detail string `json:"detail"`
+}
+
+// InboundAttachment is a file attached to a received email
+type InboundAttachment struct {
+	// the file name of the attachment
+	Name string
+	// the MIME type of the attachment
+	Type string
+	// the decoded content of the attachment
+	Content []byte
+}
+
+// UnmarshalJSON decodes base64 encoded content
+func (a *InboundAttachment) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Name    string `json:"name"`
+		Type    string `json:"type"`
+		Content string `json:"content"`
+		Base64  *bool  `json:"base64"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	a.Name = raw.Name
+	a.Type = raw.Type
+	// images omit the base64 flag, their content is always encoded
+	if raw.Base64 != nil && !*raw.Base64 {
+		a.Content = []byte(raw.Content)
+		return nil
+	}
+	content, err := base64.StdEncoding.DecodeString(raw.Content)
+	if err != nil {
+		return err
+	}
+	a.Content = content
+	return nil
+}
+
+// ParseInboundEvents decodes the mandrill_events field of an inbound route's webhook request
+func ParseInboundEvents(r *http.Request) ([]*InboundEvent, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	if _, ok := r.PostForm["mandrill_events"]; !ok {
+		return nil, ErrNoEvents
+	}
+	return DecodeInboundEvents([]byte(r.PostForm.Get("mandrill_events")))
+}
+
+// DecodeInboundEvents decodes a JSON array of inbound events
+func DecodeInboundEvents(data []byte) ([]*InboundEvent, error) {
+	events := []*InboundEvent{}
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,86 @@
+package webhooks
+
+import (
+	"net/http"
+	"testing"
+)
+
+const inboundEventJSON = `{
+	"event": "inbound",
+	"ts": 1365109999,
+	"msg": {
+		"raw_msg": "Received: from mail115.us4.mandrillapp.com ...",
+		"headers": {
+			"Received": ["from mail115.us4.mandrillapp.com", "from localhost"],
+			"Message-Id": "<999.20130510192820.aaaaaaaaaaaaaa.aaaaaaaa@mail115.us4.mandrillapp.com>"
+		},
+		"text": "This is an example inbound message.",
+		"html": "<p>This is an example inbound message.</p>",
+		"from_email": "example.sender@mandrillapp.com",
+		"from_name": "Example Sender",
+		"to": [["example@example.com", null], ["other@example.com", "Other"]],
+		"cc": [["cc@example.com", "Carbon"]],
+		"email": "example@example.com",
+		"subject": "This is an example webhook message",
+		"tags": [],
+		"sender": null,
+		"spam_report": {"score": 1.2, "matched_rules": [{"name": "HTML_MESSAGE", "score": 0.001, "description": "BODY: HTML included in message"}]},
+		"dkim": {"signed": true, "valid": true},
+		"spf": {"result": "pass", "detail": "sender SPF authorized"},
+		"attachments": {
+			"notes.txt": {"name": "notes.txt", "type": "text/plain", "content": "plain notes", "base64": false},
+			"logo.gif": {"name": "logo.gif", "type": "image/gif", "content": "R0lGODlh", "base64": true}
+		},
+		"images": {
+			"image1@example.com": {"name": "image1@example.com", "type": "image/png", "content": "iVBORw0K"}
+		}
+	}
+}`
+
+// Inbound //////////
+
+func Test_ParseInboundEvents(t *testing.T) {
+	events, err := ParseInboundEvents(webhookRequest("[" + inboundEventJSON + "]"))
+	expect(t, err, nil)
+	expect(t, len(events), 1)
+
+	event := events[0]
+	expect(t, event.Type, EventInbound)
+	expect(t, event.Ts.Unix(), int64(1365109999))
+
+	msg := event.Msg
+	expect(t, msg.FromName, "Example Sender")
+	expect(t, msg.Subject, "This is an example webhook message")
+	expect(t, msg.To[0].Email, "example@example.com")
+	expect(t, msg.To[0].Name, "")
+	expect(t, msg.To[1].Name, "Other")
+	expect(t, msg.CC[0].Email, "cc@example.com")
+	expect(t, msg.Headers.Get("received"), "from mail115.us4.mandrillapp.com")
+	expect(t, len(msg.Headers["Received"]), 2)
+	expect(t, msg.Headers.Get("Message-Id"), "<999.20130510192820.aaaaaaaaaaaaaa.aaaaaaaa@mail115.us4.mandrillapp.com>")
+	expect(t, msg.Headers.Get("X-Missing"), "")
+	expect(t, msg.SpamReport.Score, 1.2)
+	expect(t, msg.SpamReport.MatchedRules[0].Name, "HTML_MESSAGE")
+	expect(t, msg.DKIM.Valid, true)
+	expect(t, msg.SPF.Result, "pass")
+
+	expect(t, string(msg.Attachments["notes.txt"].Content), "plain notes")
+	expect(t, msg.Attachments["logo.gif"].Type, "image/gif")
+	expect(t, string(msg.Attachments["logo.gif"].Content), "GIF89a")
+	expect(t, string(msg.Images["image1@example.com"].Content[1:4]), "PNG")
+}
+
+func Test_ParseInboundEvents_Missing(t *testing.T) {
+	r := webhookRequest("[]")
+	r.Body = http.NoBody
+	r.ContentLength = 0
+
+	events, err := ParseInboundEvents(r)
+	expect(t, err, ErrNoEvents)
+	expect(t, len(events), 0)
+}
+
+func Test_DecodeInboundEvents_BadAttachment(t *testing.T) {
+	_, err := DecodeInboundEvents([]byte(`[{"event":"inbound","msg":{"attachments":{"a":{"content":"%%%","base64":true}}}}]`))
+	refute(t, err, nil)
+}
@@ -0,0 +1,177 @@
+package webhooks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultMetricsNamespace prefixes the names of a Metrics' series when Namespace is empty
+const DefaultMetricsNamespace = "mandrill_webhook"
+
+// OtherTag is the tag label of events whose tags aren't in Metrics.Tags
+const OtherTag = "other"
+
+// Metrics counts webhook events by type, tag and subaccount and serves them in the
+// Prometheus text exposition format, for scraping into Prometheus or Grafana. Set
+// it as a Handler's Metrics to count the events the handler accepts, and mount it
+// on the metrics path:
+//
+//	metrics := &webhooks.Metrics{Tags: []string{"welcome", "digest"}}
+//	handler.Metrics = metrics
+//	http.Handle("/metrics", metrics)
+//
+// It serves two series:
+//
+//	mandrill_webhook_events_total{event="hard_bounce",tag="welcome",subaccount="cust-1"}
+//	mandrill_webhook_last_event_timestamp_seconds{event="hard_bounce"}
+//
+// An event is counted once for each of its message's tags, so sums across tags
+// overcount messages with several. Batches Mandrill redelivers are counted again.
+// Call Observe directly to count events the Handler doesn't see, e.g. from an
+// inbound route. The zero value is usable.
+type Metrics struct {
+	// the prefix of the series names. Defaults to DefaultMetricsNamespace.
+	Namespace string
+	// the tags to label events with, limiting the series to a known set. Other tags
+	// are labelled OtherTag. Every tag is labelled when empty.
+	Tags []string
+
+	mu     sync.Mutex
+	counts map[metricKey]float64
+	last   map[string]float64
+}
+
+type metricKey struct {
+	event, tag, subaccount string
+}
+
+// Observe counts an event
+func (m *Metrics) Observe(event *Event) {
+	var tags []string
+	subaccount := ""
+	if event.Msg != nil {
+		tags = m.labelTags(event.Msg.Tags)
+		subaccount = event.Msg.Subaccount
+	}
+	if len(tags) == 0 {
+		tags = []string{""}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.counts == nil {
+		m.counts = map[metricKey]float64{}
+		m.last = map[string]float64{}
+	}
+	for _, tag := range tags {
+		m.counts[metricKey{event.Type, tag, subaccount}]++
+	}
+	if ts := float64(event.Ts.Unix()); !event.Ts.IsZero() && ts > m.last[event.Type] {
+		m.last[event.Type] = ts
+	}
+}
+
+// labelTags maps a message's tags to their labels
+func (m *Metrics) labelTags(tags []string) []string {
+	if len(m.Tags) == 0 {
+		return tags
+	}
+
+	var labels []string
+	other := false
+	for _, tag := range tags {
+		known := false
+		for _, t := range m.Tags {
+			if t == tag {
+				known = true
+				break
+			}
+		}
+		if known {
+			labels = append(labels, tag)
+		} else {
+			other = true
+		}
+	}
+	if other {
+		labels = append(labels, OtherTag)
+	}
+	return labels
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes the metrics to w in the Prometheus text format, in a stable order
+func (m *Metrics) WriteTo(w io.Writer) (n int64, err error) {
+	namespace := m.Namespace
+	if namespace == "" {
+		namespace = DefaultMetricsNamespace
+	}
+
+	m.mu.Lock()
+	keys := make([]metricKey, 0, len(m.counts))
+	for key := range m.counts {
+		keys = append(keys, key)
+	}
+	counts := make([]float64, len(keys))
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.event != b.event {
+			return a.event < b.event
+		}
+		if a.tag != b.tag {
+			return a.tag < b.tag
+		}
+		return a.subaccount < b.subaccount
+	})
+	for i, key := range keys {
+		counts[i] = m.counts[key]
+	}
+	types := make([]string, 0, len(m.last))
+	for event := range m.last {
+		types = append(types, event)
+	}
+	sort.Strings(types)
+	last := make([]float64, len(types))
+	for i, event := range types {
+		last[i] = m.last[event]
+	}
+	m.mu.Unlock()
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# HELP %s_events_total Webhook events received, by event type, message tag and subaccount.\n", namespace)
+	fmt.Fprintf(&buf, "# TYPE %s_events_total counter\n", namespace)
+	for i, key := range keys {
+		fmt.Fprintf(&buf, "%s_events_total{event=\"%s\",tag=\"%s\",subaccount=\"%s\"} %s\n",
+			namespace, escapeLabel(key.event), escapeLabel(key.tag), escapeLabel(key.subaccount), formatValue(counts[i]))
+	}
+
+	fmt.Fprintf(&buf, "# HELP %s_last_event_timestamp_seconds When the latest webhook event of each type occurred.\n", namespace)
+	fmt.Fprintf(&buf, "# TYPE %s_last_event_timestamp_seconds gauge\n", namespace)
+	for i, event := range types {
+		fmt.Fprintf(&buf, "%s_last_event_timestamp_seconds{event=\"%s\"} %s\n", namespace, escapeLabel(event), formatValue(last[i]))
+	}
+
+	return buf.WriteTo(w)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
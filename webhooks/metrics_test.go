@@ -0,0 +1,60 @@
+package webhooks
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Metrics //////////
+
+func Test_Metrics(t *testing.T) {
+	m := &Metrics{}
+	h := &Handler{InsecureSkipVerify: true, Metrics: m}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, webhookRequest("["+bounceEventJSON+","+clickEventJSON+","+clickEventJSON+"]"))
+	expect(t, w.Code, http.StatusOK)
+
+	var buf bytes.Buffer
+	_, err := m.WriteTo(&buf)
+	expect(t, err, nil)
+	expect(t, buf.String(), `# HELP mandrill_webhook_events_total Webhook events received, by event type, message tag and subaccount.
+# TYPE mandrill_webhook_events_total counter
+mandrill_webhook_events_total{event="click",tag="",subaccount=""} 2
+mandrill_webhook_events_total{event="hard_bounce",tag="webhook-example",subaccount=""} 1
+# HELP mandrill_webhook_last_event_timestamp_seconds When the latest webhook event of each type occurred.
+# TYPE mandrill_webhook_last_event_timestamp_seconds gauge
+mandrill_webhook_last_event_timestamp_seconds{event="click"} 1365111111
+mandrill_webhook_last_event_timestamp_seconds{event="hard_bounce"} 1365109999
+`)
+}
+
+func Test_Metrics_Tags(t *testing.T) {
+	m := &Metrics{Namespace: "app_mail", Tags: []string{"welcome"}}
+	m.Observe(&Event{Type: EventOpen, Msg: &Message{Tags: []string{"welcome", "user-123"}, Subaccount: "cust \"1\""}})
+	m.Observe(&Event{Type: EventOpen, Msg: &Message{Tags: []string{"user-456"}}})
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	expect(t, w.Header().Get("Content-Type"), "text/plain; version=0.0.4; charset=utf-8")
+	expect(t, w.Body.String(), `# HELP app_mail_events_total Webhook events received, by event type, message tag and subaccount.
+# TYPE app_mail_events_total counter
+app_mail_events_total{event="open",tag="other",subaccount=""} 1
+app_mail_events_total{event="open",tag="other",subaccount="cust \"1\""} 1
+app_mail_events_total{event="open",tag="welcome",subaccount="cust \"1\""} 1
+# HELP app_mail_last_event_timestamp_seconds When the latest webhook event of each type occurred.
+# TYPE app_mail_last_event_timestamp_seconds gauge
+`)
+}
+
+func Test_Metrics_RefusedRequest(t *testing.T) {
+	m := &Metrics{}
+	h := &Handler{Keys: []string{"key"}, Metrics: m}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, webhookRequest("["+bounceEventJSON+"]"))
+	expect(t, w.Code, http.StatusForbidden)
+	expect(t, len(m.counts), 0)
+}
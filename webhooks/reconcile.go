@@ -0,0 +1,216 @@
+package webhooks
+
+import (
+	"sync"
+	"time"
+
+	"github.com/keighl/mandrill"
+)
+
+// Message lifecycle states, from least to most final
+const (
+	// Mandrill accepted the message for sending
+	StateAccepted = "accepted"
+	// the receiving server deferred the message; Mandrill will retry
+	StateDeferred = "deferred"
+	// the message soft bounced
+	StateSoftBounced = "soft_bounced"
+	// the receiving server accepted the message
+	StateDelivered = "delivered"
+	// the recipient opened the message
+	StateOpened = "opened"
+	// the recipient clicked a link in the message
+	StateClicked = "clicked"
+	// the recipient unsubscribed
+	StateUnsubscribed = "unsubscribed"
+	// the recipient marked the message as spam
+	StateComplained = "complained"
+	// the message hard bounced
+	StateBounced = "bounced"
+	// Mandrill rejected the message, or the address was invalid
+	StateRejected = "rejected"
+)
+
+var stateRanks = map[string]int{
+	StateAccepted:     1,
+	StateDeferred:     2,
+	StateSoftBounced:  3,
+	StateDelivered:    4,
+	StateOpened:       5,
+	StateClicked:      6,
+	StateUnsubscribed: 7,
+	StateComplained:   8,
+	StateBounced:      9,
+	StateRejected:     9,
+}
+
+var eventStates = map[string]string{
+	EventSend:       StateDelivered,
+	EventDeferral:   StateDeferred,
+	EventSoftBounce: StateSoftBounced,
+	EventHardBounce: StateBounced,
+	EventOpen:       StateOpened,
+	EventClick:      StateClicked,
+	EventUnsub:      StateUnsubscribed,
+	EventSpam:       StateComplained,
+	EventReject:     StateRejected,
+}
+
+// MessageStatus is the lifecycle state of a sent message
+type MessageStatus struct {
+	// the message's unique id
+	ID string
+	// the recipient email address
+	Email string
+	// the message's most final state so far, e.g. StateDelivered. Events arriving
+	// out of order never move it back, e.g. a deferral after delivery.
+	State string
+	// why the message was rejected or bounced, e.g. "hard-bounce" or "bad_mailbox"
+	Reason string
+	// the number of open events received
+	Opens int
+	// the number of click events received
+	Clicks int
+	// when the message was sent, or first seen in an event
+	SentAt time.Time
+	// when the last send result or event was recorded
+	UpdatedAt time.Time
+}
+
+// StatusStore holds message statuses for a Reconciler. Implementations shared
+// between processes must apply Update atomically, e.g. in a transaction.
+type StatusStore interface {
+	// Get returns the message's status, or nil if it's unknown
+	Get(id string) (*MessageStatus, error)
+	// Update applies fn to the message's status, which is new with only ID set if it's unknown, and stores the result
+	Update(id string, fn func(status *MessageStatus)) error
+}
+
+// Reconciler correlates send results with the webhook events that follow them,
+// keeping the current state of each message in Store, e.g. for a message status
+// page. Record send results with RecordSend, and pass webhook events to
+// RecordEvent, e.g. as a Handler's OnEvent:
+//
+//	handler.OnEvent = reconciler.RecordEvent
+//
+// Redelivered events are counted again, so opens and clicks may overcount when
+// Mandrill retries a batch.
+type Reconciler struct {
+	Store StatusStore
+}
+
+// RecordSend records the responses of a send, e.g. from Client.MessagesSend
+func (r *Reconciler) RecordSend(responses []*mandrill.Response) error {
+	sentAt := time.Now().UTC()
+	for _, res := range responses {
+		if res == nil || res.Id == "" {
+			continue
+		}
+		state := StateAccepted
+		if res.Status == "rejected" || res.Status == "invalid" {
+			state = StateRejected
+		}
+		err := r.Store.Update(res.Id, func(status *MessageStatus) {
+			status.Email = res.Email
+			if status.SentAt.IsZero() {
+				status.SentAt = sentAt
+			}
+			if sentAt.After(status.UpdatedAt) {
+				status.UpdatedAt = sentAt
+			}
+			if status.advance(state) && res.RejectionReason != "" {
+				status.Reason = res.RejectionReason
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordEvent records a webhook event against its message. Events without a
+// message id, or of unknown types, are ignored.
+func (r *Reconciler) RecordEvent(event *Event) error {
+	state, ok := eventStates[event.Type]
+	if !ok || event.ID == "" {
+		return nil
+	}
+	at := event.Ts.UTC()
+
+	return r.Store.Update(event.ID, func(status *MessageStatus) {
+		if event.Msg != nil {
+			if status.Email == "" {
+				status.Email = event.Msg.Email
+			}
+			if status.SentAt.IsZero() && !event.Msg.Ts.IsZero() {
+				status.SentAt = event.Msg.Ts.UTC()
+			}
+		}
+		if at.After(status.UpdatedAt) {
+			status.UpdatedAt = at
+		}
+
+		switch event.Type {
+		case EventOpen:
+			status.Opens++
+		case EventClick:
+			status.Clicks++
+		}
+
+		if status.advance(state) && event.Msg != nil && event.Msg.BounceDescription != "" {
+			status.Reason = event.Msg.BounceDescription
+		}
+	})
+}
+
+// Status returns the message's current status, or nil if nothing was recorded for it
+func (r *Reconciler) Status(id string) (*MessageStatus, error) {
+	return r.Store.Get(id)
+}
+
+// advance moves the status to state unless it's already in a more final one, reporting whether it moved
+func (s *MessageStatus) advance(state string) bool {
+	if stateRanks[state] < stateRanks[s.State] {
+		return false
+	}
+	s.State = state
+	return true
+}
+
+// MemoryStatusStore is an in-process StatusStore that keeps every status for the
+// life of the process. The zero value is usable.
+type MemoryStatusStore struct {
+	mu       sync.Mutex
+	statuses map[string]*MessageStatus
+}
+
+// Get returns a copy of the message's status, or nil
+func (s *MemoryStatusStore) Get(id string) (*MessageStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status, ok := s.statuses[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *status
+	return &copied, nil
+}
+
+// Update applies fn to the message's status
+func (s *MemoryStatusStore) Update(id string, fn func(status *MessageStatus)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.statuses == nil {
+		s.statuses = map[string]*MessageStatus{}
+	}
+	status, ok := s.statuses[id]
+	if !ok {
+		status = &MessageStatus{ID: id}
+		s.statuses[id] = status
+	}
+	fn(status)
+	return nil
+}
@@ -0,0 +1,77 @@
+package webhooks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keighl/mandrill"
+)
+
+// Reconciler //////////
+
+func Test_Reconciler(t *testing.T) {
+	r := &Reconciler{Store: &MemoryStatusStore{}}
+	id := "exampleaaaaaaaaaaaaaaaaaaaaaaaaa"
+
+	expect(t, r.RecordSend([]*mandrill.Response{
+		{Id: id, Email: "example.webhook@mandrillapp.com", Status: "sent"},
+		{Id: "rejected1", Email: "bob@example.com", Status: "rejected", RejectionReason: "hard-bounce"},
+	}), nil)
+
+	status, err := r.Status(id)
+	expect(t, err, nil)
+	expect(t, status.State, StateAccepted)
+	expect(t, status.Email, "example.webhook@mandrillapp.com")
+	refute(t, status.SentAt.IsZero(), true)
+
+	rejected, _ := r.Status("rejected1")
+	expect(t, rejected.State, StateRejected)
+	expect(t, rejected.Reason, "hard-bounce")
+
+	expect(t, r.RecordEvent(decodeEvent(t, openEventJSON)), nil)
+	expect(t, r.RecordEvent(decodeEvent(t, clickEventJSON)), nil)
+	status, _ = r.Status(id)
+	expect(t, status.State, StateClicked)
+	expect(t, status.Opens, 1)
+	expect(t, status.Clicks, 1)
+
+	// a late open doesn't move a clicked message back
+	expect(t, r.RecordEvent(decodeEvent(t, openEventJSON)), nil)
+	status, _ = r.Status(id)
+	expect(t, status.State, StateClicked)
+	expect(t, status.Opens, 2)
+
+	unknown, err := r.Status("unknown")
+	expect(t, err, nil)
+	expect(t, unknown, (*MessageStatus)(nil))
+}
+
+func Test_Reconciler_EventBeforeSend(t *testing.T) {
+	r := &Reconciler{Store: &MemoryStatusStore{}}
+
+	expect(t, r.RecordEvent(decodeEvent(t, bounceEventJSON)), nil)
+	status, _ := r.Status("exampleaaaaaaaaaaaaaaaaaaaaaaaaa")
+	expect(t, status.State, StateBounced)
+	expect(t, status.Reason, "bad_mailbox")
+	expect(t, status.Email, "example.webhook@mandrillapp.com")
+	expect(t, status.SentAt.Unix(), int64(1365109999))
+	expect(t, status.UpdatedAt.Unix(), int64(1365109999))
+
+	// the send result recorded late doesn't undo the bounce
+	expect(t, r.RecordSend([]*mandrill.Response{{Id: "exampleaaaaaaaaaaaaaaaaaaaaaaaaa", Email: "example.webhook@mandrillapp.com", Status: "sent"}}), nil)
+	status, _ = r.Status("exampleaaaaaaaaaaaaaaaaaaaaaaaaa")
+	expect(t, status.State, StateBounced)
+	expect(t, status.SentAt.Unix(), int64(1365109999))
+}
+
+func Test_Reconciler_Handler(t *testing.T) {
+	r := &Reconciler{Store: &MemoryStatusStore{}}
+	h := &Handler{InsecureSkipVerify: true, OnEvent: r.RecordEvent}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, webhookRequest("["+clickEventJSON+"]"))
+	expect(t, w.Code, http.StatusOK)
+	status, _ := r.Status("exampleaaaaaaaaaaaaaaaaaaaaaaaaa")
+	expect(t, status.State, StateClicked)
+}
@@ -0,0 +1,78 @@
+package webhooks
+
+import (
+	"sync"
+	"time"
+)
+
+// EventStore persists webhook events so they can be acknowledged before they're
+// processed. Implementations backed by a database should upsert on Event.Key,
+// e.g. with INSERT ... ON CONFLICT DO NOTHING, since Mandrill redelivers batches.
+type EventStore interface {
+	// Save stores the events, ignoring any whose Key is already stored, done or not
+	Save(events []*Event) error
+	// Pending returns the stored events that haven't been marked done, in the order they were saved
+	Pending() ([]*Event, error)
+	// Done marks the event with the key as processed
+	Done(key string) error
+}
+
+// Key identifies an event for de-duplication: its type, message id and timestamp.
+// A message's events share its id, so the id alone isn't unique.
+func (e *Event) Key() string {
+	return e.Type + ":" + e.ID + ":" + e.Ts.UTC().Format(time.RFC3339Nano)
+}
+
+// MemoryEventStore is an in-process EventStore, for tests and single-instance
+// services that can afford to lose pending events on restart. It remembers the
+// keys of done events for the life of the process. The zero value is usable.
+type MemoryEventStore struct {
+	mu      sync.Mutex
+	done    map[string]bool
+	pending []*Event
+}
+
+// Save stores the events not already stored
+func (s *MemoryEventStore) Save(events []*Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.done == nil {
+		s.done = map[string]bool{}
+	}
+	for _, event := range events {
+		key := event.Key()
+		if _, ok := s.done[key]; ok {
+			continue
+		}
+		s.done[key] = false
+		s.pending = append(s.pending, event)
+	}
+	return nil
+}
+
+// Pending returns the events not yet marked done
+func (s *MemoryEventStore) Pending() ([]*Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*Event(nil), s.pending...), nil
+}
+
+// Done marks the event with the key as processed
+func (s *MemoryEventStore) Done(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.done[key]; !ok {
+		return nil
+	}
+	s.done[key] = true
+	for i, event := range s.pending {
+		if event.Key() == key {
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package webhooks
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// EventStore //////////
+
+func Test_Event_Key(t *testing.T) {
+	event := decodeEvent(t, bounceEventJSON)
+	expect(t, event.Key(), "hard_bounce:exampleaaaaaaaaaaaaaaaaaaaaaaaaa:2013-04-04T21:13:19Z")
+}
+
+func Test_MemoryEventStore(t *testing.T) {
+	s := &MemoryEventStore{}
+	bounce := decodeEvent(t, bounceEventJSON)
+	click := decodeEvent(t, clickEventJSON)
+
+	expect(t, s.Save([]*Event{bounce, click}), nil)
+	expect(t, s.Save([]*Event{decodeEvent(t, bounceEventJSON)}), nil)
+	pending, _ := s.Pending()
+	expect(t, len(pending), 2)
+
+	expect(t, s.Done(bounce.Key()), nil)
+	pending, _ = s.Pending()
+	expect(t, len(pending), 1)
+	expect(t, pending[0], click)
+
+	// redelivered after being processed
+	expect(t, s.Save([]*Event{decodeEvent(t, bounceEventJSON)}), nil)
+	pending, _ = s.Pending()
+	expect(t, len(pending), 1)
+}
+
+func Test_Handler_Store(t *testing.T) {
+	store := &MemoryEventStore{}
+	fail := true
+	h := &Handler{InsecureSkipVerify: true, Store: store, OnBounce: func(*BounceEvent) error {
+		if fail {
+			return errors.New("database down")
+		}
+		return nil
+	}}
+
+	// acknowledged once saved, even though the callback failed
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, webhookRequest("["+bounceEventJSON+","+clickEventJSON+"]"))
+	expect(t, w.Code, http.StatusOK)
+	pending, _ := store.Pending()
+	expect(t, len(pending), 1)
+	expect(t, pending[0].Type, EventHardBounce)
+
+	expect(t, h.ProcessPending().Error(), "database down")
+
+	fail = false
+	expect(t, h.ProcessPending(), nil)
+	pending, _ = store.Pending()
+	expect(t, len(pending), 0)
+}
+
+type failingStore struct{ MemoryEventStore }
+
+func (s *failingStore) Save([]*Event) error { return errors.New("disk full") }
+
+func Test_Handler_StoreError(t *testing.T) {
+	called := false
+	h := &Handler{InsecureSkipVerify: true, Store: &failingStore{}, OnEvent: func(*Event) error {
+		called = true
+		return nil
+	}}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, webhookRequest("["+bounceEventJSON+"]"))
+	expect(t, w.Code, http.StatusInternalServerError)
+	expect(t, called, false)
+}
@@ -0,0 +1,131 @@
+// Package webhooks decodes the events Mandrill POSTs to webhook URLs.
+//
+// https://mandrill.zendesk.com/hc/en-us/articles/205583307-Message-Event-Webhook-format
+//
+//	func handle(w http.ResponseWriter, r *http.Request) {
+//	    events, err := webhooks.ParseEvents(r)
+//	    if err != nil {
+//	        http.Error(w, err.Error(), http.StatusBadRequest)
+//	        return
+//	    }
+//	    for _, event := range events {
+//	        if event.Type == webhooks.EventHardBounce {
+//	            suppress(event.Msg.Email)
+//	        }
+//	    }
+//	}
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/keighl/mandrill"
+)
+
+// Message event types
+const (
+	EventSend       = "send"
+	EventDeferral   = "deferral"
+	EventHardBounce = "hard_bounce"
+	EventSoftBounce = "soft_bounce"
+	EventOpen       = "open"
+	EventClick      = "click"
+	EventSpam       = "spam"
+	EventUnsub      = "unsub"
+	EventReject     = "reject"
+)
+
+// ErrNoEvents is returned when a request has no mandrill_events field
+var ErrNoEvents = errors.New("webhooks: request has no mandrill_events")
+
+// Event is a single webhook event
+type Event struct {
+	// the event type, e.g. EventHardBounce
+	Type string `json:"event"`
+	// the Unix timestamp when the event occurred
+	Ts mandrill.Time `json:"ts"`
+	// the message's unique id
+	ID string `json:"_id"`
+	// the message the event is about
+	Msg *Message `json:"msg"`
+	// the IP address that generated an open or click event
+	IP string `json:"ip"`
+	// the user agent that generated an open or click event
+	UserAgent string `json:"user_agent"`
+	// the parsed user agent of an open or click event
+	UserAgentParsed *UserAgent `json:"user_agent_parsed"`
+	// the approximate location of the IP address of an open or click event
+	Location *Location `json:"location"`
+	// the URL clicked in a click event
+	URL string `json:"url"`
+}
+
+// Message is the state of a message at the time of an event
+type Message struct {
+	// the Unix timestamp from when this message was sent
+	Ts mandrill.Time `json:"ts"`
+	// the message's unique id
+	ID string `json:"_id"`
+	// the message's sending status, e.g. "sent", "bounced", "soft-bounced", "rejected"
+	State string `json:"state"`
+	// the message's subject line
+	Subject string `json:"subject"`
+	// the recipient email address
+	Email string `json:"email"`
+	// the email address of the sender
+	Sender string `json:"sender"`
+	// list of tags on this message
+	Tags []string `json:"tags"`
+	// any custom metadata provided when the message was sent
+	Metadata map[string]string `json:"metadata"`
+	// the unique name of the template used, if any
+	Template string `json:"template"`
+	// the subaccount the message was sent from, if any
+	Subaccount string `json:"subaccount"`
+	// the opens recorded for the message
+	Opens []*Open `json:"opens"`
+	// the clicks recorded for the message
+	Clicks []*Click `json:"clicks"`
+	// a short description of a bounce, e.g. "bad_mailbox"
+	BounceDescription string `json:"bounce_description"`
+	// the SMTP diagnostic message of a bounce
+	Diag string `json:"diag"`
+	// a log of up to 3 smtp events for the message
+	SMTPEvents []*mandrill.SMTPEvent `json:"smtp_events"`
+}
+
+// Open is an open recorded for a message
+type Open struct {
+	// the Unix timestamp of the open
+	Ts mandrill.Time `json:"ts"`
+}
+
+// Click is a click recorded for a message
+type Click struct {
+	// the Unix timestamp of the click
+	Ts mandrill.Time `json:"ts"`
+	// the URL that was clicked
+	URL string `json:"url"`
+}
+
+// ParseEvents decodes the mandrill_events field of a webhook request
+func ParseEvents(r *http.Request) ([]*Event, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	if _, ok := r.PostForm["mandrill_events"]; !ok {
+		return nil, ErrNoEvents
+	}
+	return DecodeEvents([]byte(r.PostForm.Get("mandrill_events")))
+}
+
+// DecodeEvents decodes a JSON array of events, e.g. a mandrill_events value read from a queue
+func DecodeEvents(data []byte) ([]*Event, error) {
+	events := []*Event{}
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,116 @@
+package webhooks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func expect(t *testing.T, a interface{}, b interface{}) {
+	if a != b {
+		t.Errorf("Expected %v (type %[1]T) - Got %v (type %[2]T)", b, a)
+	}
+}
+
+func refute(t *testing.T, a interface{}, b interface{}) {
+	if a == b {
+		t.Errorf("Did not expect %v (type %[1]T) - Got %v (type %[2]T)", b, a)
+	}
+}
+
+const bounceEventJSON = `{
+	"event": "hard_bounce",
+	"ts": 1365109999,
+	"_id": "exampleaaaaaaaaaaaaaaaaaaaaaaaaa",
+	"msg": {
+		"ts": 1365109999,
+		"_id": "exampleaaaaaaaaaaaaaaaaaaaaaaaaa",
+		"state": "bounced",
+		"subject": "This an example webhook message",
+		"email": "example.webhook@mandrillapp.com",
+		"sender": "example.sender@mandrillapp.com",
+		"tags": ["webhook-example"],
+		"metadata": {"user_id": "111"},
+		"opens": [],
+		"clicks": [],
+		"bounce_description": "bad_mailbox",
+		"diag": "smtp;550 5.1.1 The email account that you tried to reach does not exist.",
+		"smtp_events": [{"ts": 1365109999, "type": "sent", "diag": "250 OK"}]
+	}
+}`
+
+const clickEventJSON = `{
+	"event": "click",
+	"ts": 1365111111,
+	"_id": "exampleaaaaaaaaaaaaaaaaaaaaaaaaa",
+	"ip": "127.0.0.1",
+	"user_agent": "Mozilla/5.0 (Macintosh; U; Intel Mac OS X 10.6; en-US; rv:1.9.1.8) Gecko/20100317 Postbox/1.1.3",
+	"url": "http://mandrill.com",
+	"location": {"country": "United States", "city": "Oklahoma City", "latitude": 35.4675598145},
+	"msg": {
+		"ts": 1365109999,
+		"_id": "exampleaaaaaaaaaaaaaaaaaaaaaaaaa",
+		"state": "sent",
+		"email": "example.webhook@mandrillapp.com",
+		"opens": [{"ts": 1365111111}],
+		"clicks": [{"ts": 1365111111, "url": "http://mandrill.com"}]
+	}
+}`
+
+func webhookRequest(events string) *http.Request {
+	form := url.Values{"mandrill_events": {events}}
+	r := httptest.NewRequest("POST", "/webhook", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+// ParseEvents //////////
+
+func Test_ParseEvents(t *testing.T) {
+	events, err := ParseEvents(webhookRequest("[" + bounceEventJSON + "," + clickEventJSON + "]"))
+	expect(t, err, nil)
+	expect(t, len(events), 2)
+
+	bounce := events[0]
+	expect(t, bounce.Type, EventHardBounce)
+	expect(t, bounce.Ts.Unix(), int64(1365109999))
+	expect(t, bounce.Msg.Email, "example.webhook@mandrillapp.com")
+	expect(t, bounce.Msg.BounceDescription, "bad_mailbox")
+	expect(t, bounce.Msg.Metadata["user_id"], "111")
+	expect(t, bounce.Msg.SMTPEvents[0].Diag, "250 OK")
+
+	click := events[1]
+	expect(t, click.Type, EventClick)
+	expect(t, click.URL, "http://mandrill.com")
+	expect(t, click.IP, "127.0.0.1")
+	expect(t, click.Msg.Clicks[0].URL, "http://mandrill.com")
+	expect(t, click.Msg.Opens[0].Ts.Unix(), int64(1365111111))
+}
+
+func Test_ParseEvents_Empty(t *testing.T) {
+	events, err := ParseEvents(webhookRequest("[]"))
+	expect(t, err, nil)
+	expect(t, len(events), 0)
+}
+
+func Test_ParseEvents_Missing(t *testing.T) {
+	r := httptest.NewRequest("POST", "/webhook", strings.NewReader(""))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	events, err := ParseEvents(r)
+	expect(t, err, ErrNoEvents)
+	expect(t, len(events), 0)
+}
+
+func Test_ParseEvents_Invalid(t *testing.T) {
+	_, err := ParseEvents(webhookRequest("{nope"))
+	refute(t, err, nil)
+}
+
+func Test_DecodeEvents(t *testing.T) {
+	events, err := DecodeEvents([]byte("[" + clickEventJSON + "]"))
+	expect(t, err, nil)
+	expect(t, events[0].UserAgent, "Mozilla/5.0 (Macintosh; U; Intel Mac OS X 10.6; en-US; rv:1.9.1.8) Gecko/20100317 Postbox/1.1.3")
+}
@@ -0,0 +1,294 @@
+// Package webhookstest generates realistic Mandrill webhook payloads, for testing
+// webhook handlers against the requests Mandrill actually makes.
+//
+//	req := webhookstest.Request("webhook-key", "https://example.com/mandrill",
+//		webhookstest.HardBounce("bob@example.com", webhookstest.DiagNoSuchUser),
+//		webhookstest.Click("amy@example.com", "https://example.com/pricing").Set("msg.tags", []string{"welcome"}),
+//	)
+//	w := httptest.NewRecorder()
+//	handler.ServeHTTP(w, req)
+//
+// Events about the same recipient share a message id, so a bounce and a click
+// generated for one address look like events about one message.
+package webhookstest
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/keighl/mandrill/webhooks"
+)
+
+// Now is when generated events occur. Replace it for events with fixed timestamps.
+var Now = time.Now
+
+// Realistic SMTP diagnostics for bounce events
+const (
+	DiagNoSuchUser   = "smtp;550 5.1.1 The email account that you tried to reach does not exist. Please try double-checking the recipient's email address for typos or unnecessary spaces."
+	DiagMailboxFull  = "smtp;552 5.2.2 The email account that you tried to reach is over quota. Please direct the recipient to https://support.google.com/mail/?p=OverQuotaPerm"
+	DiagBadDomain    = "smtp;550 5.1.2 Host or domain name not found. Name service error for name=example.invalid type=A: Host not found"
+	DiagPolicyBlock  = "smtp;550 5.7.1 Message rejected due to local policy. Please visit https://postmaster.example.com for more information."
+	DiagTooLarge     = "smtp;552 5.3.4 Message size exceeds fixed maximum message size"
+	DiagGreylisted   = "smtp;451 4.7.1 Greylisted, please try again later"
+	DiagTimedOut     = "smtp;421 4.4.2 Connection timed out"
+	DiagDeferralRate = "smtp;421 4.7.0 Our system has detected an unusual rate of unsolicited mail originating from your IP address. To protect our users from spam, mail sent from your IP address has been temporarily rate limited."
+)
+
+// bounceDescriptions maps bounce categories to Mandrill's bounce_description values
+var bounceDescriptions = map[webhooks.BounceCategory]string{
+	webhooks.BounceNoSuchUser:      "bad_mailbox",
+	webhooks.BounceMailboxFull:     "mailbox_full",
+	webhooks.BounceDNSFailure:      "invalid_domain",
+	webhooks.BouncePolicyBlock:     "policy_related",
+	webhooks.BounceMessageTooLarge: "general",
+	webhooks.BounceTemporary:       "general",
+	webhooks.BounceUnknown:         "general",
+}
+
+// Event is a webhook event as Mandrill encodes it
+type Event map[string]interface{}
+
+// Set sets a field of the event, reaching into nested objects with dotted paths,
+// e.g. "msg.tags" or "location.city". It returns the event for chaining.
+func (e Event) Set(path string, value interface{}) Event {
+	fields := strings.Split(path, ".")
+	object := map[string]interface{}(e)
+	for _, field := range fields[:len(fields)-1] {
+		next, ok := object[field].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			object[field] = next
+		}
+		object = next
+	}
+	object[fields[len(fields)-1]] = value
+	return e
+}
+
+// NewEvent returns an event of any message event type, e.g. webhooks.EventSpam,
+// about a message sent to email. Prefer the type's own function where there is
+// one; it fills in the type's fields.
+func NewEvent(eventType, email string) Event {
+	ts := Now().Unix()
+	id := MessageID(email)
+	state := "sent"
+	switch eventType {
+	case webhooks.EventHardBounce:
+		state = "bounced"
+	case webhooks.EventSoftBounce:
+		state = "soft-bounced"
+	case webhooks.EventDeferral:
+		state = "deferred"
+	case webhooks.EventReject:
+		state = "rejected"
+	}
+
+	return Event{
+		"event": eventType,
+		"ts":    ts,
+		"_id":   id,
+		"msg": map[string]interface{}{
+			"ts":          ts - 60,
+			"_id":         id,
+			"_version":    "exampleaaaaaaaaaaaaaaa",
+			"state":       state,
+			"subject":     "This is an example webhook message",
+			"email":       email,
+			"sender":      "example.sender@mandrillapp.com",
+			"tags":        []string{"webhook-example"},
+			"metadata":    map[string]string{"user_id": "111"},
+			"template":    nil,
+			"subaccount":  nil,
+			"opens":       []interface{}{},
+			"clicks":      []interface{}{},
+			"smtp_events": []interface{}{},
+			"resends":     []interface{}{},
+		},
+	}
+}
+
+// MessageID returns the id of generated events' messages to email
+func MessageID(email string) string {
+	sum := md5.Sum([]byte(strings.ToLower(email)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Send returns a send event: the receiving server accepted the message
+func Send(email string) Event {
+	e := NewEvent(webhooks.EventSend, email)
+	return e.Set("msg.smtp_events", []interface{}{smtpEvent(e, "sent", "250 2.0.0 OK 1365109999 a1si12345678qcd.88 - gsmtp")})
+}
+
+// Deferral returns a deferral event with an SMTP diagnostic, e.g. DiagGreylisted
+func Deferral(email, diag string) Event {
+	e := NewEvent(webhooks.EventDeferral, email)
+	return e.Set("msg.smtp_events", []interface{}{smtpEvent(e, "deferred", strings.TrimPrefix(diag, "smtp;"))})
+}
+
+// HardBounce returns a hard_bounce event with an SMTP diagnostic, e.g. DiagNoSuchUser,
+// and the bounce_description Mandrill would give it
+func HardBounce(email, diag string) Event {
+	return bounce(NewEvent(webhooks.EventHardBounce, email), diag)
+}
+
+// SoftBounce returns a soft_bounce event with an SMTP diagnostic, e.g. DiagMailboxFull,
+// and the bounce_description Mandrill would give it
+func SoftBounce(email, diag string) Event {
+	return bounce(NewEvent(webhooks.EventSoftBounce, email), diag)
+}
+
+func bounce(e Event, diag string) Event {
+	category := webhooks.ClassifyBounce(diag, "").Category
+	return e.
+		Set("msg.diag", diag).
+		Set("msg.bounce_description", bounceDescriptions[category]).
+		Set("msg.smtp_events", []interface{}{smtpEvent(e, "bounced", strings.TrimPrefix(diag, "smtp;"))})
+}
+
+// Open returns an open event from a desktop email client, with its location
+func Open(email string) Event {
+	e := NewEvent(webhooks.EventOpen, email)
+	ts := e["ts"]
+	return engagement(e).Set("msg.opens", []interface{}{map[string]interface{}{"ts": ts, "ip": "198.51.100.7", "location": "Oklahoma City, US", "ua": "OS X/Mac Mail"}})
+}
+
+// Click returns a click event on link, from a desktop email client, with its location
+func Click(email, link string) Event {
+	e := NewEvent(webhooks.EventClick, email)
+	ts := e["ts"]
+	return engagement(e).
+		Set("url", link).
+		Set("msg.opens", []interface{}{map[string]interface{}{"ts": ts, "ip": "198.51.100.7", "location": "Oklahoma City, US", "ua": "OS X/Mac Mail"}}).
+		Set("msg.clicks", []interface{}{map[string]interface{}{"ts": ts, "url": link, "ip": "198.51.100.7", "location": "Oklahoma City, US", "ua": "OS X/Mac Mail"}})
+}
+
+func engagement(e Event) Event {
+	return e.
+		Set("ip", "198.51.100.7").
+		Set("user_agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko)").
+		Set("user_agent_parsed", map[string]interface{}{
+			"type":       "Email Client",
+			"ua_family":  "Apple Mail",
+			"ua_name":    "Apple Mail",
+			"ua_version": nil,
+			"ua_url":     "http://www.apple.com/",
+			"os_family":  "OS X",
+			"os_name":    "OS X 10.15 Catalina",
+			"os_url":     "http://www.apple.com/osx/",
+			"mobile":     false,
+		}).
+		Set("location", map[string]interface{}{
+			"country_short": "US",
+			"country":       "United States",
+			"region":        "Oklahoma",
+			"city":          "Oklahoma City",
+			"postal_code":   "73101",
+			"timezone":      "-05:00",
+			"latitude":      35.4675598145,
+			"longitude":     -97.5164337158,
+		})
+}
+
+func smtpEvent(e Event, eventType, diag string) map[string]interface{} {
+	return map[string]interface{}{
+		"ts":             e["ts"],
+		"type":           eventType,
+		"diag":           diag,
+		"source_ip":      "198.2.128.1",
+		"destination_ip": "203.0.113.25",
+		"size":           4281,
+	}
+}
+
+// Attachment is a file attached to a generated inbound email
+type Attachment struct {
+	// the file name
+	Name string
+	// the MIME type, e.g. "application/pdf"
+	Type string
+	// the file's content
+	Content []byte
+}
+
+// Inbound returns an inbound event for an email from one address to another,
+// with its attachments. Text attachments are sent as plain text and others
+// base64 encoded, as Mandrill does.
+func Inbound(from, to, subject, text string, attachments ...Attachment) Event {
+	ts := Now().Unix()
+	files := map[string]interface{}{}
+	for _, a := range attachments {
+		file := map[string]interface{}{"name": a.Name, "type": a.Type, "base64": false, "content": string(a.Content)}
+		if !strings.HasPrefix(a.Type, "text/") {
+			file["base64"] = true
+			file["content"] = base64.StdEncoding.EncodeToString(a.Content)
+		}
+		files[a.Name] = file
+	}
+	messageID := fmt.Sprintf("<%d.%s@mail.example.com>", ts, MessageID(from)[:12])
+	date := time.Unix(ts, 0).UTC().Format(time.RFC1123Z)
+
+	return Event{
+		"event": webhooks.EventInbound,
+		"ts":    ts,
+		"msg": map[string]interface{}{
+			"raw_msg": "Received: from mail.example.com\nMessage-Id: " + messageID + "\nDate: " + date +
+				"\nFrom: " + from + "\nTo: " + to + "\nSubject: " + subject + "\nContent-Type: text/plain; charset=utf-8\n\n" + text,
+			"headers": map[string]interface{}{
+				"Received":     []string{"from mail.example.com (mail.example.com [203.0.113.25]) by mail115.us4.mandrillapp.com", "from localhost"},
+				"Message-Id":   messageID,
+				"Date":         date,
+				"From":         from,
+				"To":           to,
+				"Subject":      subject,
+				"Content-Type": "multipart/mixed",
+			},
+			"text":        text,
+			"html":        nil,
+			"from_email":  from,
+			"from_name":   nil,
+			"to":          [][]interface{}{{to, nil}},
+			"email":       to,
+			"subject":     subject,
+			"tags":        []string{},
+			"sender":      nil,
+			"spam_report": map[string]interface{}{"score": 0.3, "matched_rules": []interface{}{map[string]interface{}{"name": "DKIM_SIGNED", "score": 0.1, "description": "Message has a DKIM or DK signature, not necessarily valid"}}},
+			"dkim":        map[string]interface{}{"signed": true, "valid": true},
+			"spf":         map[string]interface{}{"result": "pass", "detail": "sender SPF authorized"},
+			"attachments": files,
+			"images":      map[string]interface{}{},
+		},
+	}
+}
+
+// Form returns the POST params Mandrill sends for the events
+func Form(events ...Event) url.Values {
+	if events == nil {
+		events = []Event{}
+	}
+	data, err := json.Marshal(events)
+	if err != nil {
+		panic("webhookstest: " + err.Error())
+	}
+	return url.Values{"mandrill_events": {string(data)}}
+}
+
+// Request returns a webhook request carrying the events, signed with key for
+// webhookURL as Mandrill signs it. An empty key leaves the request unsigned.
+func Request(key, webhookURL string, events ...Event) *http.Request {
+	form := Form(events...)
+	r := httptest.NewRequest("POST", webhookURL, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("User-Agent", "Mandrill-Webhook/1.0")
+	if key != "" {
+		r.Header.Set("X-Mandrill-Signature", webhooks.Signature(key, webhookURL, form))
+	}
+	return r
+}
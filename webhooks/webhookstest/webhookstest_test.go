@@ -0,0 +1,106 @@
+package webhookstest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/keighl/mandrill/webhooks"
+)
+
+func expect(t *testing.T, a interface{}, b interface{}) {
+	if a != b {
+		t.Errorf("Expected %v (type %[1]T) - Got %v (type %[2]T)", b, a)
+	}
+}
+
+func fixedNow(t *testing.T) {
+	Now = func() time.Time { return time.Unix(1365109999, 0) }
+	t.Cleanup(func() { Now = time.Now })
+}
+
+func Test_Request_Signed(t *testing.T) {
+	fixedNow(t)
+	var bounces []*webhooks.BounceEvent
+	var clicks []*webhooks.ClickEvent
+	h := &webhooks.Handler{
+		Keys:     []string{"key"},
+		OnBounce: func(e *webhooks.BounceEvent) error { bounces = append(bounces, e); return nil },
+		OnClick:  func(e *webhooks.ClickEvent) error { clicks = append(clicks, e); return nil },
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, Request("key", "https://example.com/mandrill",
+		HardBounce("bob@example.com", DiagNoSuchUser),
+		Click("bob@example.com", "https://example.com/pricing").Set("msg.tags", []string{"welcome"}),
+	))
+	expect(t, w.Code, http.StatusOK)
+
+	expect(t, len(bounces), 1)
+	bounce := bounces[0]
+	expect(t, bounce.Hard, true)
+	expect(t, bounce.Ts.Unix(), int64(1365109999))
+	expect(t, bounce.Msg.Email, "bob@example.com")
+	expect(t, bounce.Description, "bad_mailbox")
+	expect(t, bounce.Classify().Code, 550)
+	expect(t, bounce.Classify().Action, webhooks.ActionSuppress)
+	expect(t, bounce.ID, MessageID("bob@example.com"))
+
+	expect(t, len(clicks), 1)
+	click := clicks[0]
+	expect(t, click.ID, bounce.ID)
+	expect(t, click.URL, "https://example.com/pricing")
+	expect(t, click.Location.City, "Oklahoma City")
+	expect(t, click.UserAgent.Family, "Apple Mail")
+	expect(t, click.IsMobile(), false)
+	expect(t, click.Msg.Tags[0], "welcome")
+	expect(t, len(click.Msg.Clicks), 1)
+}
+
+func Test_Request_WrongKey(t *testing.T) {
+	h := &webhooks.Handler{Keys: []string{"key"}}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, Request("other", "https://example.com/mandrill", Send("bob@example.com")))
+	expect(t, w.Code, http.StatusForbidden)
+}
+
+func Test_SoftBounce(t *testing.T) {
+	events, err := webhooks.ParseEvents(Request("", "https://example.com/mandrill",
+		SoftBounce("amy@example.com", DiagMailboxFull),
+		Deferral("amy@example.com", DiagGreylisted),
+		NewEvent(webhooks.EventSpam, "amy@example.com"),
+	))
+	expect(t, err, nil)
+	expect(t, len(events), 3)
+
+	bounce, _ := events[0].Bounce()
+	expect(t, bounce.Hard, false)
+	expect(t, bounce.Description, "mailbox_full")
+	expect(t, events[0].Msg.State, "soft-bounced")
+	expect(t, events[1].Msg.SMTPEvents[0].Diag, "451 4.7.1 Greylisted, please try again later")
+	expect(t, events[2].Type, webhooks.EventSpam)
+}
+
+func Test_Inbound(t *testing.T) {
+	fixedNow(t)
+	events, err := webhooks.ParseInboundEvents(Request("", "https://example.com/inbound",
+		Inbound("amy@example.com", "support@example.com", "Invoice", "See attached.",
+			Attachment{Name: "notes.txt", Type: "text/plain", Content: []byte("plain notes")},
+			Attachment{Name: "invoice.pdf", Type: "application/pdf", Content: []byte("%PDF-1.4\x00\x01")},
+		),
+	))
+	expect(t, err, nil)
+	expect(t, len(events), 1)
+
+	msg := events[0].Msg
+	expect(t, events[0].Ts.Unix(), int64(1365109999))
+	expect(t, msg.FromEmail, "amy@example.com")
+	expect(t, msg.To[0].Email, "support@example.com")
+	expect(t, msg.Subject, "Invoice")
+	expect(t, msg.Headers.Get("subject"), "Invoice")
+	expect(t, msg.DKIM.Valid, true)
+	expect(t, string(msg.Attachments["notes.txt"].Content), "plain notes")
+	expect(t, string(msg.Attachments["invoice.pdf"].Content), "%PDF-1.4\x00\x01")
+}
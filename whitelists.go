@@ -0,0 +1,84 @@
+package mandrill
+
+import (
+	"context"
+)
+
+// WhitelistEntry is an address on the allowlist
+type WhitelistEntry struct {
+	// the email that is allowlisted
+	Email string `json:"email"`
+	// a description of why the email was allowlisted
+	Detail string `json:"detail"`
+	// when the email was added to the allowlist
+	CreatedAt Time `json:"created_at"`
+}
+
+// WhitelistResult is the outcome of adding an address to, or removing one from, the allowlist
+type WhitelistResult struct {
+	// the email address that was added or removed
+	Email string `json:"email"`
+	// whether the operation succeeded
+	Added bool `json:"added"`
+	// whether the address was deleted successfully
+	Deleted bool `json:"deleted"`
+}
+
+// WhitelistsAdd adds an email to the allowlist, removing it from the rejection denylist
+func (c *Client) WhitelistsAdd(email string, comment string) (*WhitelistResult, error) {
+	return c.WhitelistsAddContext(context.Background(), email, comment)
+}
+
+// WhitelistsAddContext is like WhitelistsAdd but with a context
+func (c *Client) WhitelistsAddContext(ctx context.Context, email string, comment string) (result *WhitelistResult, err error) {
+	var data struct {
+		Key     string `json:"key"`
+		Email   string `json:"email"`
+		Comment string `json:"comment,omitempty"`
+	}
+
+	data.Key = c.Key
+	data.Email = email
+	data.Comment = comment
+
+	err = c.call(ctx, "whitelists/add.json", data, &result)
+	return result, err
+}
+
+// WhitelistsList returns up to 1000 allowlist entries, optionally filtered by email prefix
+func (c *Client) WhitelistsList(email string) ([]*WhitelistEntry, error) {
+	return c.WhitelistsListContext(context.Background(), email)
+}
+
+// WhitelistsListContext is like WhitelistsList but with a context
+func (c *Client) WhitelistsListContext(ctx context.Context, email string) (entries []*WhitelistEntry, err error) {
+	var data struct {
+		Key   string `json:"key"`
+		Email string `json:"email,omitempty"`
+	}
+
+	data.Key = c.Key
+	data.Email = email
+
+	err = c.call(ctx, "whitelists/list.json", data, &entries)
+	return entries, err
+}
+
+// WhitelistsDelete removes an email from the allowlist
+func (c *Client) WhitelistsDelete(email string) (*WhitelistResult, error) {
+	return c.WhitelistsDeleteContext(context.Background(), email)
+}
+
+// WhitelistsDeleteContext is like WhitelistsDelete but with a context
+func (c *Client) WhitelistsDeleteContext(ctx context.Context, email string) (result *WhitelistResult, err error) {
+	var data struct {
+		Key   string `json:"key"`
+		Email string `json:"email"`
+	}
+
+	data.Key = c.Key
+	data.Email = email
+
+	err = c.call(ctx, "whitelists/delete.json", data, &result)
+	return result, err
+}
@@ -0,0 +1,42 @@
+package mandrill
+
+import (
+	"testing"
+)
+
+// Whitelists //////////
+
+func Test_WhitelistsAdd(t *testing.T) {
+	server, c, req := testRecorder(200, `{"email":"example@example.com","added":true}`)
+	defer server.Close()
+
+	result, err := c.WhitelistsAdd("example@example.com", "VIP")
+	expect(t, err, nil)
+	expect(t, req.Path, "/whitelists/add.json")
+	expect(t, req.Payload["email"], "example@example.com")
+	expect(t, req.Payload["comment"], "VIP")
+	expect(t, result.Added, true)
+}
+
+func Test_WhitelistsList(t *testing.T) {
+	server, c, req := testRecorder(200, `[{"email":"example@example.com","detail":"Example Detail","created_at":"2013-01-01 15:30:27"}]`)
+	defer server.Close()
+
+	entries, err := c.WhitelistsList("example")
+	expect(t, err, nil)
+	expect(t, req.Path, "/whitelists/list.json")
+	expect(t, req.Payload["email"], "example")
+	expect(t, len(entries), 1)
+	expect(t, entries[0].Detail, "Example Detail")
+	expect(t, entries[0].CreatedAt.IsZero(), false)
+}
+
+func Test_WhitelistsDelete(t *testing.T) {
+	server, c, req := testRecorder(200, `{"email":"example@example.com","deleted":true}`)
+	defer server.Close()
+
+	result, err := c.WhitelistsDelete("example@example.com")
+	expect(t, err, nil)
+	expect(t, req.Path, "/whitelists/delete.json")
+	expect(t, result.Deleted, true)
+}